@@ -0,0 +1,295 @@
+// Package accountdata builds personal data exports and carries out account
+// deletions: a background Worker turns export requests into zip archives and
+// hard deletes accounts whose grace period has passed.
+package accountdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"personal-assistant-backend/internal/models"
+)
+
+// Export is everything an archive's export.json holds about a user.
+type Export struct {
+	ExportedAt     time.Time  `json:"exported_at"`
+	Profile        Profile    `json:"profile"`
+	LinkedAccounts []Identity `json:"linked_accounts"`
+	Sessions       []Session  `json:"sessions"`
+	Personas       []Persona  `json:"personas"`
+	Chats          []Chat     `json:"chats"`
+}
+
+// Profile is the account itself. Credentials are left out on purpose.
+type Profile struct {
+	ID               string     `json:"id"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	Email            string     `json:"email"`
+	PhoneNumber      string     `json:"phone_number"`
+	CreatedAt        time.Time  `json:"created_at"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+}
+
+// Identity is a linked social login account.
+type Identity struct {
+	Issuer      string    `json:"issuer"`
+	Email       string    `json:"email"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+// Session is a signed-in device.
+type Session struct {
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Persona is a saved system prompt.
+type Persona struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	SystemPrompt string    `json:"system_prompt"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Chat is a conversation with its messages.
+type Chat struct {
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Pinned          bool      `json:"pinned"`
+	Archived        bool      `json:"archived"`
+	Model           string    `json:"model,omitempty"`
+	Temperature     *float32  `json:"temperature,omitempty"`
+	MaxOutputTokens *int      `json:"max_output_tokens,omitempty"`
+	TopP            *float32  `json:"top_p,omitempty"`
+	PersonaID       *string   `json:"persona_id,omitempty"`
+	Summary         *string   `json:"summary,omitempty"`
+	Messages        []Message `json:"messages"`
+}
+
+// Message is one turn of a chat.
+type Message struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Collect reads everything stored about userID.
+func Collect(ctx context.Context, db *sql.DB, userID string) (*Export, error) {
+	e := &Export{
+		ExportedAt:     time.Now().UTC(),
+		LinkedAccounts: []Identity{},
+		Sessions:       []Session{},
+		Personas:       []Persona{},
+		Chats:          []Chat{},
+	}
+
+	p := &e.Profile
+	err := db.QueryRowContext(ctx, `
+		SELECT id, first_name, last_name, email, phone_number, created_at, email_verified_at, totp_enabled_at IS NOT NULL
+		FROM users WHERE id = $1
+	`, userID).Scan(&p.ID, &p.FirstName, &p.LastName, &p.Email, &p.PhoneNumber, &p.CreatedAt, &p.EmailVerifiedAt, &p.TwoFactorEnabled)
+	if err != nil {
+		return nil, fmt.Errorf("read profile: %w", err)
+	}
+
+	err = each(ctx, db, `
+		SELECT issuer, email, created_at, last_login_at FROM identities
+		WHERE user_id = $1 ORDER BY created_at
+	`, userID, func(rows *sql.Rows) error {
+		var i Identity
+		err := rows.Scan(&i.Issuer, &i.Email, &i.CreatedAt, &i.LastLoginAt)
+		e.LinkedAccounts = append(e.LinkedAccounts, i)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read identities: %w", err)
+	}
+
+	err = each(ctx, db, `
+		SELECT user_agent, ip_address, created_at, last_used_at, revoked_at FROM sessions
+		WHERE user_id = $1 ORDER BY created_at
+	`, userID, func(rows *sql.Rows) error {
+		var s Session
+		err := rows.Scan(&s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastUsedAt, &s.RevokedAt)
+		e.Sessions = append(e.Sessions, s)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read sessions: %w", err)
+	}
+
+	err = each(ctx, db, `
+		SELECT id, name, system_prompt, created_at, updated_at FROM personas
+		WHERE user_id = $1 ORDER BY created_at
+	`, userID, func(rows *sql.Rows) error {
+		var p Persona
+		err := rows.Scan(&p.ID, &p.Name, &p.SystemPrompt, &p.CreatedAt, &p.UpdatedAt)
+		e.Personas = append(e.Personas, p)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read personas: %w", err)
+	}
+
+	err = each(ctx, db, `
+		SELECT c.id, c.title, c.created_at, c.updated_at, c.pinned, c.archived,
+			c.model, c.temperature, c.max_output_tokens, c.top_p, c.persona_id, s.content
+		FROM chats c LEFT JOIN chat_summaries s ON s.chat_id = c.id
+		WHERE c.user_id = $1 ORDER BY c.created_at, c.id
+	`, userID, func(rows *sql.Rows) error {
+		c := Chat{Messages: []Message{}}
+		err := rows.Scan(&c.ID, &c.Title, &c.CreatedAt, &c.UpdatedAt, &c.Pinned, &c.Archived,
+			&c.Model, &c.Temperature, &c.MaxOutputTokens, &c.TopP, &c.PersonaID, &c.Summary)
+		e.Chats = append(e.Chats, c)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read chats: %w", err)
+	}
+
+	byID := make(map[string]*Chat, len(e.Chats))
+	for i := range e.Chats {
+		byID[e.Chats[i].ID] = &e.Chats[i]
+	}
+	err = each(ctx, db, `
+		SELECT m.chat_id, m.role, m.content, m.status, m.created_at
+		FROM messages m JOIN chats c ON c.id = m.chat_id
+		WHERE c.user_id = $1 ORDER BY m.created_at, m.id
+	`, userID, func(rows *sql.Rows) error {
+		var chatID string
+		var m Message
+		if err := rows.Scan(&chatID, &m.Role, &m.Content, &m.Status, &m.CreatedAt); err != nil {
+			return err
+		}
+		// A chat created after the chat list was read is left for the next export
+		if c, ok := byID[chatID]; ok {
+			c.Messages = append(c.Messages, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read messages: %w", err)
+	}
+
+	return e, nil
+}
+
+// each runs query with userID and calls scan for every row
+func each(ctx context.Context, db *sql.DB, query, userID string, scan func(*sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Archive zips the export as export.json plus one Markdown file per chat
+// under chats/.
+func (e *Export) Archive() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	w, err := zw.Create("export.json")
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(e); err != nil {
+		return nil, err
+	}
+
+	personas := make(map[string]string, len(e.Personas))
+	for _, p := range e.Personas {
+		personas[p.ID] = p.Name
+	}
+	for _, c := range e.Chats {
+		w, err := zw.Create("chats/" + chatFileName(c))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(chatMarkdown(c, personas)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// chatFileName is date-title-id.md; the id prefix keeps same-titled chats apart
+func chatFileName(c Chat) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(c.Title), "-"), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimRight(slug[:50], "-")
+	}
+	if slug == "" {
+		slug = "chat"
+	}
+	id := c.ID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return fmt.Sprintf("%s-%s-%s.md", c.CreatedAt.UTC().Format("2006-01-02"), slug, id)
+}
+
+var roleNames = map[string]string{"user": "You", "assistant": "Assistant", "system": "System"}
+
+// chatMarkdown renders a chat as a readable transcript
+func chatMarkdown(c Chat, personas map[string]string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", c.Title)
+	fmt.Fprintf(&b, "- Created: %s\n", c.CreatedAt.UTC().Format(time.RFC3339))
+	if c.Model != "" {
+		fmt.Fprintf(&b, "- Model: %s\n", c.Model)
+	}
+	if c.PersonaID != nil {
+		if name, ok := personas[*c.PersonaID]; ok {
+			fmt.Fprintf(&b, "- Persona: %s\n", name)
+		}
+	}
+	if c.Summary != nil {
+		fmt.Fprintf(&b, "\n> Summary: %s\n", strings.ReplaceAll(*c.Summary, "\n", "\n> "))
+	}
+
+	for _, m := range c.Messages {
+		role, ok := roleNames[m.Role]
+		if !ok {
+			role = m.Role
+		}
+		when := m.CreatedAt.UTC().Format(time.RFC3339)
+		// Replies that stopped early say so
+		if m.Status != "" && m.Status != models.MessageComplete {
+			when += " · " + m.Status
+		}
+		fmt.Fprintf(&b, "\n---\n\n**%s** · %s\n\n%s\n", role, when, m.Content)
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,121 @@
+package accountdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	profileQuery    = `SELECT id, first_name, last_name, email, phone_number, created_at, email_verified_at, totp_enabled_at IS NOT NULL FROM users WHERE id = \$1`
+	identitiesQuery = `SELECT issuer, email, created_at, last_login_at FROM identities WHERE user_id = \$1 ORDER BY created_at`
+	sessionsQuery   = `SELECT user_agent, ip_address, created_at, last_used_at, revoked_at FROM sessions WHERE user_id = \$1 ORDER BY created_at`
+	personasQuery   = `SELECT id, name, system_prompt, created_at, updated_at FROM personas WHERE user_id = \$1 ORDER BY created_at`
+	chatsQuery      = `SELECT c.id, c.title, c.created_at, c.updated_at, c.pinned, c.archived, c.model, c.temperature, c.max_output_tokens, c.top_p, c.persona_id, s.content FROM chats c LEFT JOIN chat_summaries s ON s.chat_id = c.id WHERE c.user_id = \$1 ORDER BY c.created_at, c.id`
+	messagesQuery   = `SELECT m.chat_id, m.role, m.content, m.status, m.created_at FROM messages m JOIN chats c ON c.id = m.chat_id WHERE c.user_id = \$1 ORDER BY m.created_at, m.id`
+)
+
+var created = time.Date(2026, 3, 14, 9, 26, 0, 0, time.UTC)
+
+// expectCollect mocks every read Collect makes for user-1, who has one
+// persona and two chats, the first with three messages, one of them cut off
+func expectCollect(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(profileQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "phone_number", "created_at", "email_verified_at", "mfa"}).
+			AddRow("user-1", "Jane", "Doe", "jane@example.com", "+15551234567", created, created, true))
+	mock.ExpectQuery(identitiesQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"issuer", "email", "created_at", "last_login_at"}).
+			AddRow("https://accounts.google.com", "jane@gmail.com", created, created))
+	mock.ExpectQuery(sessionsQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_agent", "ip_address", "created_at", "last_used_at", "revoked_at"}).
+			AddRow("curl/8", "192.0.2.1", created, created, nil))
+	mock.ExpectQuery(personasQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "system_prompt", "created_at", "updated_at"}).
+			AddRow("persona-1", "Chef", "You are a chef.", created, created))
+	mock.ExpectQuery(chatsQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "created_at", "updated_at", "pinned", "archived", "model", "temperature", "max_output_tokens", "top_p", "persona_id", "summary"}).
+			AddRow("0f1e2d3c-aaaa-bbbb-cccc-000000000001", "Dinner: ideas?", created, created, true, false, "gpt-4o-mini", 0.5, nil, nil, "persona-1", "Talked about pasta.").
+			AddRow("0f1e2d3c-aaaa-bbbb-cccc-000000000002", "", created, created, false, true, "", nil, nil, nil, nil, nil))
+	mock.ExpectQuery(messagesQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"chat_id", "role", "content", "status", "created_at"}).
+			AddRow("0f1e2d3c-aaaa-bbbb-cccc-000000000001", "user", "What should I cook?", "complete", created).
+			AddRow("0f1e2d3c-aaaa-bbbb-cccc-000000000001", "assistant", "Carbonara.", "complete", created.Add(time.Second)).
+			AddRow("0f1e2d3c-aaaa-bbbb-cccc-000000000001", "assistant", "Or maybe", "cancelled", created.Add(2*time.Second)))
+}
+
+func unzip(t *testing.T, archive []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		body, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(body)
+	}
+	return files
+}
+
+// --- TESTS ---
+
+func TestCollectAndArchive(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	expectCollect(mock)
+
+	export, err := Collect(context.Background(), db, "user-1")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, "jane@example.com", export.Profile.Email)
+	assert.True(t, export.Profile.TwoFactorEnabled)
+	require.Len(t, export.Chats, 2)
+	assert.Len(t, export.Chats[0].Messages, 3)
+	assert.Empty(t, export.Chats[1].Messages)
+
+	archive, err := export.Archive()
+	require.NoError(t, err)
+	files := unzip(t, archive)
+
+	assert.Len(t, files, 3)
+	var decoded Export
+	require.NoError(t, json.Unmarshal([]byte(files["export.json"]), &decoded))
+	assert.Equal(t, "Carbonara.", decoded.Chats[0].Messages[1].Content)
+	assert.Equal(t, "192.0.2.1", decoded.Sessions[0].IPAddress)
+	assert.NotContains(t, files["export.json"], "password")
+
+	md := files["chats/2026-03-14-dinner-ideas-0f1e2d3c.md"]
+	assert.Contains(t, md, "# Dinner: ideas?\n")
+	assert.Contains(t, md, "- Persona: Chef\n")
+	assert.Contains(t, md, "> Summary: Talked about pasta.\n")
+	assert.Contains(t, md, "**You** · 2026-03-14T09:26:00Z\n\nWhat should I cook?\n")
+	assert.Contains(t, md, "**Assistant** · 2026-03-14T09:26:01Z\n\nCarbonara.\n")
+	assert.Contains(t, md, "**Assistant** · 2026-03-14T09:26:02Z · cancelled\n\nOr maybe\n")
+	assert.Contains(t, files, "chats/2026-03-14-chat-0f1e2d3c.md")
+}
+
+func TestChatFileName(t *testing.T) {
+	for title, want := range map[string]string{
+		"Hello, World!":    "2026-03-14-hello-world-abcdef12.md",
+		"../../etc/passwd": "2026-03-14-etc-passwd-abcdef12.md",
+		"Ünïcode only ✨":   "2026-03-14-n-code-only-abcdef12.md",
+		"  ":               "2026-03-14-chat-abcdef12.md",
+		"a very long title indeed, longer than fifty characters in total": "2026-03-14-a-very-long-title-indeed-longer-than-fifty-charact-abcdef12.md",
+	} {
+		assert.Equal(t, want, chatFileName(Chat{ID: "abcdef12-3456", Title: title, CreatedAt: created}), title)
+	}
+}
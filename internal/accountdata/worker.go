@@ -0,0 +1,143 @@
+package accountdata
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// Export statuses, as stored in data_exports.status.
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusReady   = "ready"
+	StatusFailed  = "failed"
+)
+
+// staleAfter is how long a running export may go unfinished before another
+// worker assumes its builder died and takes it over
+const staleAfter = 15 * time.Minute
+
+// Worker builds pending exports and purges deleted accounts and expired
+// archives. Any number of instances can run; rows are claimed with SKIP
+// LOCKED so each export is built once.
+type Worker struct {
+	db *sql.DB
+	// Interval between rounds
+	Interval time.Duration
+	// ExportTTL is how long a finished archive stays downloadable
+	ExportTTL time.Duration
+}
+
+// NewWorker creates a Worker polling every minute and keeping archives for
+// seven days.
+func NewWorker(db *sql.DB) *Worker {
+	return &Worker{db: db, Interval: time.Minute, ExportTTL: 7 * 24 * time.Hour}
+}
+
+// Run does a round every Interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		w.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce purges what is due and builds every pending export.
+func (w *Worker) RunOnce(ctx context.Context) {
+	if n, err := w.PurgeAccounts(ctx); err != nil {
+		log.Printf("❌ Failed to purge deleted accounts: %v\n", err)
+	} else if n > 0 {
+		log.Printf("🗑️ Purged %d deleted account(s)\n", n)
+	}
+	if err := w.PurgeExports(ctx); err != nil {
+		log.Printf("❌ Failed to purge expired exports: %v\n", err)
+	}
+
+	for ctx.Err() == nil {
+		built, err := w.BuildNext(ctx)
+		if err != nil {
+			log.Printf("❌ Failed to build data export: %v\n", err)
+			return
+		}
+		if !built {
+			return
+		}
+	}
+}
+
+// PurgeAccounts hard deletes accounts whose grace period is over. Chats,
+// messages and everything else a user owns go with them by cascade.
+func (w *Worker) PurgeAccounts(ctx context.Context) (int64, error) {
+	result, err := w.db.ExecContext(ctx, `DELETE FROM users WHERE deletion_scheduled_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeExports drops archives past their expiry and failed exports older
+// than a day.
+func (w *Worker) PurgeExports(ctx context.Context) error {
+	_, err := w.db.ExecContext(ctx, `
+		DELETE FROM data_exports
+		WHERE expires_at < now() OR (status = 'failed' AND created_at < now() - interval '1 day')
+	`)
+	return err
+}
+
+// BuildNext claims the oldest pending export, builds its archive and stores
+// it. It reports false when there was nothing to do. An export that can't
+// be built is marked failed rather than returned as an error, so one bad
+// export doesn't hold up the rest.
+func (w *Worker) BuildNext(ctx context.Context) (bool, error) {
+	var exportID, userID string
+	err := w.db.QueryRowContext(ctx, `
+		UPDATE data_exports SET status = 'running', started_at = now()
+		WHERE id = (
+			SELECT id FROM data_exports
+			WHERE status = 'pending' OR (status = 'running' AND started_at < now() - make_interval(secs => $1))
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id
+	`, staleAfter.Seconds()).Scan(&exportID, &userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	archive, err := w.build(ctx, userID)
+	if err != nil {
+		log.Printf("❌ Data export %s failed: %v\n", exportID, err)
+		_, err = w.db.ExecContext(ctx, `
+			UPDATE data_exports SET status = 'failed', completed_at = now() WHERE id = $1
+		`, exportID)
+		return true, err
+	}
+
+	_, err = w.db.ExecContext(ctx, `
+		UPDATE data_exports
+		SET status = 'ready', archive = $2, completed_at = now(), expires_at = now() + make_interval(secs => $3)
+		WHERE id = $1
+	`, exportID, archive, w.ExportTTL.Seconds())
+	return true, err
+}
+
+func (w *Worker) build(ctx context.Context, userID string) ([]byte, error) {
+	export, err := Collect(ctx, w.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	return export.Archive()
+}
@@ -0,0 +1,97 @@
+package accountdata
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	claimExportQuery  = `UPDATE data_exports SET status = 'running', started_at = now\(\) WHERE id = \( SELECT id FROM data_exports WHERE status = 'pending' OR \(status = 'running' AND started_at < now\(\) - make_interval\(secs => \$1\)\) ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED \) RETURNING id, user_id`
+	readyExportQuery  = `UPDATE data_exports SET status = 'ready', archive = \$2, completed_at = now\(\), expires_at = now\(\) \+ make_interval\(secs => \$3\) WHERE id = \$1`
+	failExportQuery   = `UPDATE data_exports SET status = 'failed', completed_at = now\(\) WHERE id = \$1`
+	purgeUsersQuery   = `DELETE FROM users WHERE deletion_scheduled_at <= now\(\)`
+	purgeExportsQuery = `DELETE FROM data_exports WHERE expires_at < now\(\) OR \(status = 'failed' AND created_at < now\(\) - interval '1 day'\)`
+)
+
+func setupWorker(t *testing.T) (*Worker, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	return NewWorker(db), mock
+}
+
+// zipArg matches a non-empty zip archive
+type zipArg struct{}
+
+func (zipArg) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	return ok && len(b) > 4 && string(b[:4]) == "PK\x03\x04"
+}
+
+// --- TESTS ---
+
+func TestBuildNext_Nothing(t *testing.T) {
+	w, mock := setupWorker(t)
+	mock.ExpectQuery(claimExportQuery).WithArgs(staleAfter.Seconds()).WillReturnError(sql.ErrNoRows)
+
+	built, err := w.BuildNext(context.Background())
+
+	assert.NoError(t, err)
+	assert.False(t, built)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildNext_StoresArchive(t *testing.T) {
+	w, mock := setupWorker(t)
+	mock.ExpectQuery(claimExportQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow("export-1", "user-1"))
+	expectCollect(mock)
+	mock.ExpectExec(readyExportQuery).
+		WithArgs("export-1", zipArg{}, w.ExportTTL.Seconds()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	built, err := w.BuildNext(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, built)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildNext_MarksFailure(t *testing.T) {
+	w, mock := setupWorker(t)
+	mock.ExpectQuery(claimExportQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow("export-1", "user-1"))
+	mock.ExpectQuery(profileQuery).WillReturnError(errors.New("connection reset"))
+	mock.ExpectExec(failExportQuery).
+		WithArgs("export-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	built, err := w.BuildNext(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, built)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunOnce(t *testing.T) {
+	w, mock := setupWorker(t)
+	mock.ExpectExec(purgeUsersQuery).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(purgeExportsQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(claimExportQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow("export-1", "user-1"))
+	expectCollect(mock)
+	mock.ExpectExec(readyExportQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(claimExportQuery).WillReturnError(sql.ErrNoRows)
+
+	w.RunOnce(context.Background())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
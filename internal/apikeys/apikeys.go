@@ -0,0 +1,203 @@
+// Package apikeys manages the per-client keys sent in X-API-Key. Each client
+// app gets its own key with its own scopes, so one can be revoked without
+// touching the others.
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Scopes a key can be granted. Each route group requires one of them.
+const (
+	ScopeAuth    = "auth"    // signup, login, token refresh, password reset, email verification
+	ScopeAccount = "account" // /me, sessions, two-factor settings
+	ScopeChat    = "chat"    // chats, search and personas
+	ScopeAll     = "*"       // every scope
+)
+
+// Scopes lists every grantable scope.
+var Scopes = []string{ScopeAuth, ScopeAccount, ScopeChat, ScopeAll}
+
+// keyPrefix marks our keys so they are recognisable in configs and leaks
+const keyPrefix = "pak_"
+
+// displayPrefixLen is how much of a key is kept in clear for listings
+const displayPrefixLen = len(keyPrefix) + 8
+
+// touchInterval limits last_used_at writes to one per key per interval
+const touchInterval = time.Minute
+
+// LegacyKeyName is the client name reported for the deprecated API_KEY.
+const LegacyKeyName = "legacy"
+
+// ErrNotFound is returned for unknown, revoked or malformed keys.
+var ErrNotFound = errors.New("api key not found")
+
+// Key is a client's API key, without the secret.
+type Key struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Allows reports whether the key grants scope.
+func (k *Key) Allows(scope string) bool {
+	return slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, ScopeAll)
+}
+
+// Store reads and writes keys in the api_keys table.
+type Store struct {
+	db *sql.DB
+	// legacy is the deprecated single API_KEY, accepted with every scope
+	// while clients move to their own keys; empty disables it.
+	legacy string
+}
+
+// NewStore creates a Store. legacyKey may be empty.
+func NewStore(db *sql.DB, legacyKey string) *Store {
+	return &Store{db: db, legacy: legacyKey}
+}
+
+// ValidateScopes rejects unknown scopes and empty lists.
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, s := range scopes {
+		if !slices.Contains(Scopes, s) {
+			return fmt.Errorf("unknown scope %q (want one of %s)", s, strings.Join(Scopes, ", "))
+		}
+	}
+	return nil
+}
+
+// Create mints a key for a client and returns it with its plaintext, which
+// is shown once and never stored.
+func (s *Store) Create(ctx context.Context, name string, scopes []string) (string, *Key, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil, errors.New("name is required")
+	}
+	if err := ValidateScopes(scopes); err != nil {
+		return "", nil, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	plaintext := keyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	key := &Key{Name: name, Prefix: plaintext[:displayPrefixLen], Scopes: scopes}
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes)
+		VALUES ($1, $2, $3, string_to_array($4, ','))
+		RETURNING id, created_at
+	`, name, key.Prefix, hashKey(plaintext), strings.Join(scopes, ",")).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return "", nil, err
+	}
+	return plaintext, key, nil
+}
+
+// List returns every key, newest first, revoked ones included.
+func (s *Store) List(ctx context.Context) ([]Key, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, prefix, array_to_string(scopes, ','), created_at, last_used_at, revoked_at
+		FROM api_keys
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []Key{}
+	for rows.Next() {
+		var k Key
+		var scopes string
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &scopes, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+			return nil, err
+		}
+		k.Scopes = splitScopes(scopes)
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke disables a key. Revoking an already revoked key is ErrNotFound.
+func (s *Store) Revoke(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = now()
+		WHERE id::text = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Authenticate resolves a presented key to its client, stamping last_used_at
+// at most once per touchInterval.
+func (s *Store) Authenticate(ctx context.Context, plaintext string) (*Key, error) {
+	if s.legacy != "" && subtle.ConstantTimeCompare([]byte(plaintext), []byte(s.legacy)) == 1 {
+		return &Key{Name: LegacyKeyName, Prefix: "API_KEY", Scopes: []string{ScopeAll}}, nil
+	}
+	if !strings.HasPrefix(plaintext, keyPrefix) {
+		return nil, ErrNotFound
+	}
+
+	var k Key
+	var scopes string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, name, prefix, array_to_string(scopes, ','), created_at, last_used_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`, hashKey(plaintext)).Scan(&k.ID, &k.Name, &k.Prefix, &scopes, &k.CreatedAt, &k.LastUsedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	k.Scopes = splitScopes(scopes)
+
+	if k.LastUsedAt == nil || time.Since(*k.LastUsedAt) > touchInterval {
+		if _, err := s.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = now() WHERE id = $1`, k.ID); err != nil {
+			log.Printf("❌ Failed to record use of API key %s: %v\n", k.Prefix, err)
+		}
+	}
+	return &k, nil
+}
+
+// hashKey returns the hex SHA-256 of a key. Keys are 256 random bits, so a
+// fast hash is enough.
+func hashKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+func splitScopes(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,138 @@
+package apikeys
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStore(t *testing.T, legacy string) (*Store, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	return NewStore(db, legacy), mock
+}
+
+const (
+	insertKeyQuery = `INSERT INTO api_keys \(name, prefix, key_hash, scopes\) VALUES \(\$1, \$2, \$3, string_to_array\(\$4, ','\)\) RETURNING id, created_at`
+	lookupKeyQuery = `SELECT id, name, prefix, array_to_string\(scopes, ','\), created_at, last_used_at FROM api_keys WHERE key_hash = \$1 AND revoked_at IS NULL`
+	touchKeyQuery  = `UPDATE api_keys SET last_used_at = now\(\) WHERE id = \$1`
+	revokeKeyQuery = `UPDATE api_keys SET revoked_at = now\(\) WHERE id::text = \$1 AND revoked_at IS NULL`
+)
+
+func expectLookup(mock sqlmock.Sqlmock, plaintext string, lastUsed *time.Time) {
+	mock.ExpectQuery(lookupKeyQuery).
+		WithArgs(hashKey(plaintext)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "prefix", "scopes", "created_at", "last_used_at"}).
+			AddRow("key-1", "ios", plaintext[:displayPrefixLen], "auth,chat", time.Now(), lastUsed))
+}
+
+// captureArg matches any string argument and keeps it
+type captureArg struct{ v *string }
+
+func (a captureArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	*a.v = s
+	return ok
+}
+
+// --- TESTS ---
+
+func TestCreate_StoresOnlyTheHash(t *testing.T) {
+	store, mock := setupStore(t, "")
+
+	var stored string
+	mock.ExpectQuery(insertKeyQuery).
+		WithArgs("ios", sqlmock.AnyArg(), captureArg{&stored}, "auth,chat").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("key-1", time.Now()))
+
+	plaintext, key, err := store.Create(context.Background(), "ios", []string{ScopeAuth, ScopeChat})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(plaintext, keyPrefix))
+	assert.Equal(t, plaintext[:displayPrefixLen], key.Prefix)
+	assert.Equal(t, "key-1", key.ID)
+	assert.Equal(t, hashKey(plaintext), stored)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreate_RejectsBadInput(t *testing.T) {
+	store, _ := setupStore(t, "")
+
+	_, _, err := store.Create(context.Background(), "ios", nil)
+	assert.Error(t, err)
+	_, _, err = store.Create(context.Background(), "ios", []string{"admin"})
+	assert.ErrorContains(t, err, "unknown scope")
+	_, _, err = store.Create(context.Background(), " ", []string{ScopeAll})
+	assert.Error(t, err)
+}
+
+func TestAuthenticate_TouchesStaleKey(t *testing.T) {
+	store, mock := setupStore(t, "")
+	plaintext := keyPrefix + "abcdefghijklmnop"
+
+	expectLookup(mock, plaintext, nil)
+	mock.ExpectExec(touchKeyQuery).WithArgs("key-1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	key, err := store.Authenticate(context.Background(), plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, "ios", key.Name)
+	assert.True(t, key.Allows(ScopeChat))
+	assert.False(t, key.Allows(ScopeAccount))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthenticate_SkipsTouchWhenRecent(t *testing.T) {
+	store, mock := setupStore(t, "")
+	plaintext := keyPrefix + "abcdefghijklmnop"
+
+	recent := time.Now().Add(-10 * time.Second)
+	expectLookup(mock, plaintext, &recent)
+
+	_, err := store.Authenticate(context.Background(), plaintext)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthenticate_UnknownOrRevoked(t *testing.T) {
+	store, mock := setupStore(t, "")
+	plaintext := keyPrefix + "abcdefghijklmnop"
+
+	mock.ExpectQuery(lookupKeyQuery).WithArgs(hashKey(plaintext)).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := store.Authenticate(context.Background(), plaintext)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Strings that can't be our keys never reach the database
+	_, err = store.Authenticate(context.Background(), "some-old-global-key")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthenticate_LegacyKey(t *testing.T) {
+	store, mock := setupStore(t, "global-secret")
+
+	key, err := store.Authenticate(context.Background(), "global-secret")
+	require.NoError(t, err)
+	assert.Equal(t, LegacyKeyName, key.Name)
+	assert.True(t, key.Allows(ScopeAccount))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevoke(t *testing.T) {
+	store, mock := setupStore(t, "")
+
+	mock.ExpectExec(revokeKeyQuery).WithArgs("key-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(revokeKeyQuery).WithArgs("key-1").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, store.Revoke(context.Background(), "key-1"))
+	assert.ErrorIs(t, store.Revoke(context.Background(), "key-1"), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
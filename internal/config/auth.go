@@ -0,0 +1,20 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// RequireVerifiedEmail reports whether REQUIRE_VERIFIED_EMAIL is set, which
+// keeps accounts with an unverified email out of the chat routes.
+func RequireVerifiedEmail() bool {
+	on, _ := strconv.ParseBool(os.Getenv("REQUIRE_VERIFIED_EMAIL"))
+	return on
+}
+
+// AdminBreakGlass reports whether ADMIN_BREAK_GLASS is set, which lets admins
+// read a user's messages. Every such read is written to the audit log.
+func AdminBreakGlass() bool {
+	on, _ := strconv.ParseBool(os.Getenv("ADMIN_BREAK_GLASS"))
+	return on
+}
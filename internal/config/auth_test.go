@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	t.Setenv("REQUIRE_VERIFIED_EMAIL", "")
+	assert.False(t, RequireVerifiedEmail())
+
+	t.Setenv("REQUIRE_VERIFIED_EMAIL", "true")
+	assert.True(t, RequireVerifiedEmail())
+
+	t.Setenv("REQUIRE_VERIFIED_EMAIL", "nope")
+	assert.False(t, RequireVerifiedEmail())
+}
+
+func TestAdminBreakGlass(t *testing.T) {
+	t.Setenv("ADMIN_BREAK_GLASS", "")
+	assert.False(t, AdminBreakGlass())
+
+	t.Setenv("ADMIN_BREAK_GLASS", "1")
+	assert.True(t, AdminBreakGlass())
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultModel is the model chats use when none is configured.
+const DefaultModel = "gpt-5-chat-latest"
+
+// AllowedModels returns the comma-separated ALLOWED_MODELS list.
+// The first entry is the default model; DefaultModel is used when unset.
+func AllowedModels() []string {
+	var models []string
+	for _, m := range strings.Split(os.Getenv("ALLOWED_MODELS"), ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			models = append(models, m)
+		}
+	}
+	if len(models) == 0 {
+		return []string{DefaultModel}
+	}
+	return models
+}
+
+// DefaultContextTokens is the context window assumed for models not listed in MODEL_CONTEXT_TOKENS.
+const DefaultContextTokens = 128000
+
+// DefaultReservedOutputTokens is held back from the context window for the reply.
+const DefaultReservedOutputTokens = 4096
+
+// ContextTokens parses MODEL_CONTEXT_TOKENS ("model=tokens,model=tokens")
+// into per-model context window sizes. Malformed entries are skipped.
+func ContextTokens() map[string]int {
+	windows := map[string]int{}
+	for _, entry := range strings.Split(os.Getenv("MODEL_CONTEXT_TOKENS"), ",") {
+		model, size, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil || n <= 0 {
+			continue
+		}
+		windows[strings.TrimSpace(model)] = n
+	}
+	return windows
+}
+
+// ReservedOutputTokens returns RESERVED_OUTPUT_TOKENS or DefaultReservedOutputTokens.
+func ReservedOutputTokens() int {
+	n, err := strconv.Atoi(os.Getenv("RESERVED_OUTPUT_TOKENS"))
+	if err != nil || n <= 0 {
+		return DefaultReservedOutputTokens
+	}
+	return n
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedModels_Default(t *testing.T) {
+	t.Setenv("ALLOWED_MODELS", "")
+	assert.Equal(t, []string{DefaultModel}, AllowedModels())
+}
+
+func TestAllowedModels_Custom(t *testing.T) {
+	t.Setenv("ALLOWED_MODELS", " gpt-4o , gpt-4o-mini,,")
+	assert.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, AllowedModels())
+}
+
+func TestContextTokens(t *testing.T) {
+	t.Setenv("MODEL_CONTEXT_TOKENS", "gpt-a=8000, gpt-b = 32000,broken,gpt-c=-1")
+	assert.Equal(t, map[string]int{"gpt-a": 8000, "gpt-b": 32000}, ContextTokens())
+}
+
+func TestReservedOutputTokens(t *testing.T) {
+	t.Setenv("RESERVED_OUTPUT_TOKENS", "")
+	assert.Equal(t, DefaultReservedOutputTokens, ReservedOutputTokens())
+
+	t.Setenv("RESERVED_OUTPUT_TOKENS", "1000")
+	assert.Equal(t, 1000, ReservedOutputTokens())
+}
@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	auditInsertQuery  = `INSERT INTO admin_audit_log \(admin_id, action, target_user_id, target_chat_id, reason, ip_address\) VALUES \(NULLIF\(\$1, ''\)::uuid, \$2, NULLIF\(\$3, ''\)::uuid, NULLIF\(\$4, ''\)::uuid, \$5, \$6\)`
+	revokeAllQuery    = `UPDATE sessions SET revoked_at = now\(\) WHERE user_id = \$1 AND revoked_at IS NULL`
+	setRoleQuery      = `UPDATE users SET role = \$2 WHERE email = \$1 RETURNING id`
+	adminActionReason = `{"reason":"abuse report #42"}`
+)
+
+// setupAdminRouter sets up Gin + sqlmock for AdminHandler, acting as admin-1
+func setupAdminRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	h := NewAdminHandler(db)
+	r := gin.Default()
+
+	// Fake admin userID in context
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "admin-1")
+		c.Next()
+	})
+
+	r.GET("/admin/users", h.ListUsers)
+	r.GET("/admin/users/:user_id", h.GetUser)
+	r.POST("/admin/users/:user_id/disable", h.DisableUser)
+	r.POST("/admin/users/:user_id/enable", h.EnableUser)
+	r.POST("/admin/users/:user_id/logout", h.LogoutUser)
+	r.GET("/admin/users/:user_id/chats", h.ListUserChats)
+	r.GET("/admin/users/:user_id/chats/:chat_id/messages", h.ReadMessages)
+	r.GET("/admin/audit", h.AuditLog)
+	return r, mock
+}
+
+// request serves a request with an optional JSON body
+func request(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// --- TESTS ---
+
+func TestSetRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(setRoleQuery).
+		WithArgs("jane@example.com", "admin").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("user-1"))
+	mock.ExpectExec(auditInsertQuery).
+		WithArgs("", ActionSetRole, "user-1", "", "admin", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, SetRole(context.Background(), db, "jane@example.com", "admin", ""))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetRole_UnknownEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(setRoleQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err = SetRole(context.Background(), db, "nobody@example.com", "admin", "")
+	assert.ErrorContains(t, err, "no user with email")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/pagination"
+)
+
+// AuditLog godoc
+// @Summary Read the admin audit log
+// @Description Lists admin actions, newest first, optionally only those concerning one user. Pass next_cursor back as `cursor` for the following page. Admins only.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param user_id query string false "Only entries targeting this user"
+// @Param limit query int false "Page size (1-100, default 50)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} models.AuditLogResponse
+// @Failure 400 {object} map[string]string "Invalid cursor"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /admin/audit [get]
+func (h *AdminHandler) AuditLog(c *gin.Context) {
+	limit, err := pagination.ParseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination", "details": err.Error()})
+		return
+	}
+	after, err := pagination.Decode(c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination", "details": err.Error()})
+		return
+	}
+
+	args := append([]any{c.Query("user_id")}, after.Args()...)
+	rows, err := h.DB.QueryContext(c.Request.Context(), `
+		SELECT id, admin_id, action, target_user_id, target_chat_id, reason, ip_address, created_at
+		FROM admin_audit_log
+		WHERE ($1 = '' OR target_user_id::text = $1)
+			AND ($2::timestamptz IS NULL OR (created_at, id) < ($2::timestamptz, $3::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`, append(args, limit+1)...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	var next string
+	var lastCreatedAt time.Time
+	for rows.Next() {
+		var e models.AuditEntry
+		var createdAt time.Time
+		err := rows.Scan(&e.ID, &e.AdminID, &e.Action, &e.TargetUserID, &e.TargetChatID, &e.Reason, &e.IPAddress, &createdAt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan error"})
+			return
+		}
+		// ✅ One row past the limit means there is another page
+		if len(entries) == limit {
+			next = pagination.EncodeTime(lastCreatedAt, entries[len(entries)-1].ID)
+			break
+		}
+		e.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		entries = append(entries, e)
+		lastCreatedAt = createdAt
+	}
+
+	c.JSON(http.StatusOK, models.AuditLogResponse{Entries: entries, NextCursor: next})
+}
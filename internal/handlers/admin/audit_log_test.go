@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/pagination"
+)
+
+const auditLogQuery = `SELECT id, admin_id, action, target_user_id, target_chat_id, reason, ip_address, created_at FROM admin_audit_log WHERE \(\$1 = '' OR target_user_id::text = \$1\)`
+
+var auditRowColumns = []string{"id", "admin_id", "action", "target_user_id", "target_chat_id", "reason", "ip_address", "created_at"}
+
+// --- TESTS ---
+
+func TestAuditLog(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(auditLogQuery).
+		WithArgs("user-1", nil, nil, 2).
+		WillReturnRows(sqlmock.NewRows(auditRowColumns).
+			AddRow("a-2", "admin-1", ActionReadMessages, "user-1", "chat-1", "legal hold", "10.0.0.1", now).
+			AddRow("a-1", nil, ActionSetRole, "user-1", nil, "admin", "", now.Add(-time.Hour)))
+
+	w := request(router, "GET", "/admin/audit?user_id=user-1&limit=1", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp models.AuditLogResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Entries, 1)
+	assert.Equal(t, ActionReadMessages, resp.Entries[0].Action)
+	require.NotNil(t, resp.Entries[0].TargetChatID)
+	assert.Equal(t, "chat-1", *resp.Entries[0].TargetChatID)
+	assert.Equal(t, pagination.EncodeTime(now, "a-2"), resp.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,142 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// DisableUser godoc
+// @Summary Disable an account
+// @Description Stops the account from signing in or refreshing tokens and signs it out everywhere. Its data is kept. The reason is written to the audit log. Admins can't disable themselves. Admins only.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param payload body models.AdminActionReq true "Reason for the audit log"
+// @Success 200 {object} map[string]interface{} "Account disabled"
+// @Failure 400 {object} map[string]string "Invalid payload or own account"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /admin/users/{user_id}/disable [post]
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	var req models.AdminActionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+	if c.Param("user_id") == c.GetString("userID") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot disable your own account"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer tx.Rollback()
+
+	// ✅ Disabling twice keeps the original time
+	var userID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users SET disabled_at = COALESCE(disabled_at, now())
+		WHERE id::text = $1
+		RETURNING id
+	`, c.Param("user_id")).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	revoked, err := revokeAllSessions(ctx, tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if err := record(ctx, tx, entryFor(c, ActionDisable, userID, req.Reason)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	log.Printf("⚠️ Admin %s disabled user %s\n", c.GetString("userID"), userID)
+	c.JSON(http.StatusOK, gin.H{"message": "Account disabled", "sessions_revoked": revoked})
+}
+
+// EnableUser godoc
+// @Summary Re-enable an account
+// @Description Lets a disabled account sign in again. The reason is written to the audit log. Admins only.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param payload body models.AdminActionReq true "Reason for the audit log"
+// @Success 200 {object} map[string]string "Account enabled"
+// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 404 {object} map[string]string "User not found or not disabled"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /admin/users/{user_id}/enable [post]
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+	var req models.AdminActionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer tx.Rollback()
+
+	var userID string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users SET disabled_at = NULL
+		WHERE id::text = $1 AND disabled_at IS NOT NULL
+		RETURNING id
+	`, c.Param("user_id")).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no disabled user with that id"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	if err := record(ctx, tx, entryFor(c, ActionEnable, userID, req.Reason)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account enabled"})
+}
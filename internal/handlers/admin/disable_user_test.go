@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	disableUserQuery = `UPDATE users SET disabled_at = COALESCE\(disabled_at, now\(\)\) WHERE id::text = \$1 RETURNING id`
+	enableUserQuery  = `UPDATE users SET disabled_at = NULL WHERE id::text = \$1 AND disabled_at IS NOT NULL RETURNING id`
+)
+
+// --- TESTS ---
+
+func TestDisableUser(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(disableUserQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("user-1"))
+	mock.ExpectExec(revokeAllQuery).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(auditInsertQuery).
+		WithArgs("admin-1", ActionDisable, "user-1", "", "abuse report #42", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	w := request(router, "POST", "/admin/users/user-1/disable", adminActionReason)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"message":"Account disabled","sessions_revoked":2}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDisableUser_AuditFailureRollsBack(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(disableUserQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("user-1"))
+	mock.ExpectExec(revokeAllQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(auditInsertQuery).WillReturnError(errors.New("db exploded"))
+	mock.ExpectRollback()
+
+	w := request(router, "POST", "/admin/users/user-1/disable", adminActionReason)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDisableUser_Refused(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(disableUserQuery).WithArgs("missing").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	assert.Equal(t, http.StatusBadRequest, request(router, "POST", "/admin/users/admin-1/disable", adminActionReason).Code)
+	assert.Equal(t, http.StatusBadRequest, request(router, "POST", "/admin/users/user-1/disable", `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, request(router, "POST", "/admin/users/missing/disable", adminActionReason).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnableUser(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(enableUserQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("user-1"))
+	mock.ExpectExec(auditInsertQuery).
+		WithArgs("admin-1", ActionEnable, "user-1", "", "abuse report #42", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(enableUserQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	assert.Equal(t, http.StatusOK, request(router, "POST", "/admin/users/user-1/enable", adminActionReason).Code)
+	assert.Equal(t, http.StatusNotFound, request(router, "POST", "/admin/users/user-1/enable", adminActionReason).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
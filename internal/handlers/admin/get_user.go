@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// GetUser godoc
+// @Summary Get a user with usage stats
+// @Description Returns an account with counts of its chats, messages, tokens and sessions. Message contents are never included. Admins only.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} models.AdminUserResponse
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /admin/users/{user_id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var user models.AdminUser
+	_, err := scanUser(h.DB.QueryRowContext(ctx, `
+		SELECT `+userColumns+` FROM users u WHERE u.id::text = $1
+	`, c.Param("user_id")), &user)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	// ✅ A session family starts at login; later rows in it are refreshes
+	usage := models.UserUsage{ChatCount: user.ChatCount}
+	var lastMessageAt, lastLoginAt *time.Time
+	err = h.DB.QueryRowContext(ctx, `
+		WITH m AS (
+			SELECT m.role, m.token_count, m.created_at
+			FROM messages m JOIN chats ch ON ch.id = m.chat_id
+			WHERE ch.user_id = $1
+		)
+		SELECT
+			(SELECT count(*) FROM m WHERE role = 'user'),
+			(SELECT count(*) FROM m WHERE role = 'assistant'),
+			(SELECT COALESCE(sum(token_count), 0) FROM m),
+			(SELECT max(created_at) FROM m),
+			(SELECT count(DISTINCT family_id) FROM sessions
+				WHERE user_id = $1 AND revoked_at IS NULL AND rotated_at IS NULL AND expires_at > now()),
+			(SELECT max(started_at) FROM (
+				SELECT min(created_at) AS started_at FROM sessions WHERE user_id = $1 GROUP BY family_id
+			) logins)
+	`, user.ID).Scan(
+		&usage.UserMessages, &usage.AssistantMessages, &usage.TokensUsed,
+		&lastMessageAt, &usage.ActiveSessions, &lastLoginAt,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	usage.LastMessageAt = formatTime(lastMessageAt)
+	usage.LastLoginAt = formatTime(lastLoginAt)
+
+	c.JSON(http.StatusOK, models.AdminUserResponse{User: user, Usage: usage})
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"personal-assistant-backend/internal/models"
+)
+
+const (
+	getUserQuery   = `SELECT u.id, .* FROM users u WHERE u.id::text = \$1`
+	userUsageQuery = `WITH m AS \( SELECT m.role, m.token_count, m.created_at FROM messages m JOIN chats ch ON ch.id = m.chat_id WHERE ch.user_id = \$1 \)`
+)
+
+// --- TESTS ---
+
+func TestGetUser_WithUsage(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(getUserQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows(userRowColumns).
+			AddRow("user-1", "Jane", "Doe", "jane@example.com", "user", now, nil, nil, nil, 4))
+	mock.ExpectQuery(userUsageQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"user", "assistant", "tokens", "last_message", "sessions", "last_login"}).
+			AddRow(10, 9, 12345, now, 2, now.Add(-time.Hour)))
+
+	w := request(router, "GET", "/admin/users/user-1", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp models.AdminUserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, models.UserUsage{
+		ChatCount:         4,
+		UserMessages:      10,
+		AssistantMessages: 9,
+		TokensUsed:        12345,
+		LastMessageAt:     resp.Usage.LastMessageAt,
+		ActiveSessions:    2,
+		LastLoginAt:       resp.Usage.LastLoginAt,
+	}, resp.Usage)
+	require.NotNil(t, resp.Usage.LastLoginAt)
+	assert.Equal(t, "2026-10-16T07:00:00Z", *resp.Usage.LastLoginAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUser_NotFound(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectQuery(getUserQuery).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+
+	assert.Equal(t, http.StatusNotFound, request(router, "GET", "/admin/users/missing", "").Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
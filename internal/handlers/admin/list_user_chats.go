@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/pagination"
+)
+
+// ListUserChats godoc
+// @Summary List a user's chats
+// @Description Lists an account's chats, newest first, with message counts. Titles are included; message contents are not. Pass next_cursor back as `cursor` for the following page. Admins only.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param limit query int false "Page size (1-100, default 50)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} models.AdminChatListResponse
+// @Failure 400 {object} map[string]string "Invalid cursor"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /admin/users/{user_id}/chats [get]
+func (h *AdminHandler) ListUserChats(c *gin.Context) {
+	limit, err := pagination.ParseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination", "details": err.Error()})
+		return
+	}
+	after, err := pagination.Decode(c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination", "details": err.Error()})
+		return
+	}
+
+	args := append([]any{c.Param("user_id")}, after.Args()...)
+	rows, err := h.DB.QueryContext(c.Request.Context(), `
+		SELECT ch.id, ch.title, ch.created_at, ch.updated_at, ch.archived,
+			(SELECT count(*) FROM messages WHERE chat_id = ch.id)
+		FROM chats ch
+		WHERE ch.user_id::text = $1
+			AND ($2::timestamptz IS NULL OR (ch.created_at, ch.id) < ($2::timestamptz, $3::uuid))
+		ORDER BY ch.created_at DESC, ch.id DESC
+		LIMIT $4
+	`, append(args, limit+1)...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer rows.Close()
+
+	chats := []models.AdminChat{}
+	var next string
+	var lastCreatedAt time.Time
+	for rows.Next() {
+		var chat models.AdminChat
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(&chat.ID, &chat.Title, &createdAt, &updatedAt, &chat.Archived, &chat.MessageCount); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan error"})
+			return
+		}
+		// ✅ One row past the limit means there is another page
+		if len(chats) == limit {
+			next = pagination.EncodeTime(lastCreatedAt, chats[len(chats)-1].ID)
+			break
+		}
+		chat.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		chat.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+		chats = append(chats, chat)
+		lastCreatedAt = createdAt
+	}
+
+	c.JSON(http.StatusOK, models.AdminChatListResponse{Chats: chats, NextCursor: next})
+}
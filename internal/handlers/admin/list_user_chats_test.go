@@ -0,0 +1,92 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/pagination"
+)
+
+const listUserChatsQuery = `SELECT ch.id, ch.title, ch.created_at, ch.updated_at, ch.archived, \(SELECT count\(\*\) FROM messages WHERE chat_id = ch.id\) FROM chats ch WHERE ch.user_id::text = \$1`
+
+// --- TESTS ---
+
+func TestListUserChats(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+	now := time.Now()
+
+	mock.ExpectQuery(listUserChatsQuery).
+		WithArgs("user-1", nil, nil, pagination.DefaultPageSize+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "created_at", "updated_at", "archived", "count"}).
+			AddRow("chat-2", "Trip plans", now, now, false, 12).
+			AddRow("chat-1", "New Chat", now.Add(-time.Hour), now, true, 0))
+
+	w := request(router, "GET", "/admin/users/user-1/chats", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp models.AdminChatListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Chats, 2)
+	assert.Equal(t, 12, resp.Chats[0].MessageCount)
+	assert.True(t, resp.Chats[1].Archived)
+	assert.NotContains(t, w.Body.String(), "content")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListUserChats_Empty(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectQuery(listUserChatsQuery).
+		WithArgs("user-2", nil, nil, pagination.DefaultPageSize+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "created_at", "updated_at", "archived", "count"}))
+
+	w := request(router, "GET", "/admin/users/user-2/chats", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"chats":[]}`, w.Body.String())
+}
+
+func TestListUserChats_Paginates(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	columns := []string{"id", "title", "created_at", "updated_at", "archived", "count"}
+
+	mock.ExpectQuery(listUserChatsQuery).
+		WithArgs("user-1", nil, nil, 3).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("chat-3", "C", now, now, false, 1).
+			AddRow("chat-2", "B", now.Add(-time.Hour), now, false, 2).
+			AddRow("chat-1", "A", now.Add(-2*time.Hour), now, false, 3))
+
+	w := request(router, "GET", "/admin/users/user-1/chats?limit=2", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp models.AdminChatListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Chats, 2)
+	require.Equal(t, pagination.EncodeTime(now.Add(-time.Hour), "chat-2"), resp.NextCursor)
+
+	mock.ExpectQuery(listUserChatsQuery).
+		WithArgs("user-1", now.Add(-time.Hour), "chat-2", 3).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("chat-1", "A", now.Add(-2*time.Hour), now, false, 3))
+
+	w = request(router, "GET", "/admin/users/user-1/chats?limit=2&cursor="+resp.NextCursor, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "next_cursor")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListUserChats_BadPagination(t *testing.T) {
+	router, _ := setupAdminRouter(t)
+
+	assert.Equal(t, http.StatusBadRequest, request(router, "GET", "/admin/users/user-1/chats?limit=101", "").Code)
+	assert.Equal(t, http.StatusBadRequest, request(router, "GET", "/admin/users/user-1/chats?cursor=bad!", "").Code)
+}
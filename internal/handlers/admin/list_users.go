@@ -0,0 +1,117 @@
+package admin
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/handlers"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/pagination"
+)
+
+// likeEscaper stops a search for "50%" or "a_b" from matching everything.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// userColumns matches the columns scanUser reads
+const userColumns = `u.id, u.first_name, u.last_name, u.email, u.role, u.created_at,
+	u.email_verified_at, u.disabled_at, u.deletion_scheduled_at,
+	(SELECT count(*) FROM chats WHERE user_id = u.id)`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser reads a row selected with userColumns and returns its created_at
+// for the cursor.
+func scanUser(row rowScanner, user *models.AdminUser) (time.Time, error) {
+	var createdAt time.Time
+	var verifiedAt, disabledAt, deleteAt *time.Time
+	err := row.Scan(
+		&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Role, &createdAt,
+		&verifiedAt, &disabledAt, &deleteAt, &user.ChatCount,
+	)
+	user.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	user.EmailVerifiedAt = formatTime(verifiedAt)
+	user.DisabledAt = formatTime(disabledAt)
+	user.DeletionScheduledAt = formatTime(deleteAt)
+	return createdAt, err
+}
+
+// ListUsers godoc
+// @Summary List users
+// @Description Lists accounts, newest first, with their chat counts. `q` matches part of the email or name. Pass next_cursor back as `cursor` for the following page. Admins only.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Search email and name"
+// @Param role query string false "Only users with this role (user, admin)"
+// @Param limit query int false "Page size (1-100, default 50)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} models.AdminUserListResponse
+// @Failure 400 {object} map[string]string "Invalid filter or cursor"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	limit, err := pagination.ParseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination", "details": err.Error()})
+		return
+	}
+	after, err := pagination.Decode(c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination", "details": err.Error()})
+		return
+	}
+	role := c.Query("role")
+	if role != "" && !slices.Contains(handlers.Roles, role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown role"})
+		return
+	}
+
+	pattern := ""
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern = "%" + likeEscaper.Replace(q) + "%"
+	}
+
+	args := append([]any{pattern, role}, after.Args()...)
+	rows, err := h.DB.QueryContext(c.Request.Context(), `
+		SELECT `+userColumns+`
+		FROM users u
+		WHERE ($1 = '' OR u.email ILIKE $1 OR (u.first_name || ' ' || u.last_name) ILIKE $1)
+			AND ($2 = '' OR u.role = $2)
+			AND ($3::timestamptz IS NULL OR (u.created_at, u.id) < ($3::timestamptz, $4::uuid))
+		ORDER BY u.created_at DESC, u.id DESC
+		LIMIT $5
+	`, append(args, limit+1)...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer rows.Close()
+
+	users := []models.AdminUser{}
+	var next string
+	var lastCreatedAt time.Time
+	for rows.Next() {
+		var user models.AdminUser
+		createdAt, err := scanUser(rows, &user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan error"})
+			return
+		}
+		// ✅ One row past the limit means there is another page
+		if len(users) == limit {
+			next = pagination.EncodeTime(lastCreatedAt, users[len(users)-1].ID)
+			break
+		}
+		users = append(users, user)
+		lastCreatedAt = createdAt
+	}
+
+	c.JSON(http.StatusOK, models.AdminUserListResponse{Users: users, NextCursor: next})
+}
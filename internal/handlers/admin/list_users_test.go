@@ -0,0 +1,87 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/pagination"
+)
+
+const listUsersQuery = `SELECT u.id, .* FROM users u WHERE \(\$1 = '' OR u.email ILIKE \$1 .*\) AND \(\$2 = '' OR u.role = \$2\) .* ORDER BY u.created_at DESC, u.id DESC LIMIT \$5`
+
+// userRowColumns matches userColumns
+var userRowColumns = []string{
+	"id", "first_name", "last_name", "email", "role", "created_at",
+	"email_verified_at", "disabled_at", "deletion_scheduled_at", "count",
+}
+
+// --- TESTS ---
+
+func TestListUsers_Search(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(listUsersQuery).
+		WithArgs(`%50\%\_off%`, "admin", nil, nil, 51).
+		WillReturnRows(sqlmock.NewRows(userRowColumns).
+			AddRow("user-1", "Jane", "Doe", "jane@example.com", "admin", now, now, nil, nil, 3))
+
+	w := request(router, "GET", "/admin/users?q=50%25_off&role=admin", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp models.AdminUserListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Users, 1)
+	assert.Equal(t, 3, resp.Users[0].ChatCount)
+	assert.Equal(t, "2026-10-16T08:00:00Z", resp.Users[0].CreatedAt)
+	assert.Nil(t, resp.Users[0].DisabledAt)
+	assert.Empty(t, resp.NextCursor)
+	assert.NotContains(t, w.Body.String(), "password")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListUsers_Pagination(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(listUsersQuery).
+		WithArgs("", "", nil, nil, 3).
+		WillReturnRows(sqlmock.NewRows(userRowColumns).
+			AddRow("user-3", "C", "C", "c@example.com", "user", now, nil, nil, nil, 0).
+			AddRow("user-2", "B", "B", "b@example.com", "user", now.Add(-time.Hour), nil, now, nil, 0).
+			AddRow("user-1", "A", "A", "a@example.com", "user", now.Add(-2*time.Hour), nil, nil, nil, 0))
+
+	w := request(router, "GET", "/admin/users?limit=2", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp models.AdminUserListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Users, 2)
+	assert.NotNil(t, resp.Users[1].DisabledAt)
+	require.Equal(t, pagination.EncodeTime(now.Add(-time.Hour), "user-2"), resp.NextCursor)
+
+	mock.ExpectQuery(listUsersQuery).
+		WithArgs("", "", now.Add(-time.Hour), "user-2", 3).
+		WillReturnRows(sqlmock.NewRows(userRowColumns).
+			AddRow("user-1", "A", "A", "a@example.com", "user", now.Add(-2*time.Hour), nil, nil, nil, 0))
+
+	w = request(router, "GET", "/admin/users?limit=2&cursor="+resp.NextCursor, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "next_cursor")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListUsers_BadFilters(t *testing.T) {
+	router, _ := setupAdminRouter(t)
+
+	assert.Equal(t, http.StatusBadRequest, request(router, "GET", "/admin/users?role=owner", "").Code)
+	assert.Equal(t, http.StatusBadRequest, request(router, "GET", "/admin/users?limit=0", "").Code)
+	assert.Equal(t, http.StatusBadRequest, request(router, "GET", "/admin/users?cursor=bad!", "").Code)
+}
@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// LogoutUser godoc
+// @Summary Sign a user out everywhere
+// @Description Revokes every session of the account; its access tokens stop working on their next request. The account can sign in again. The reason is written to the audit log. Admins only.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param payload body models.AdminActionReq true "Reason for the audit log"
+// @Success 200 {object} map[string]interface{} "Sessions revoked"
+// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 403 {object} map[string]string "Not an admin"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /admin/users/{user_id}/logout [post]
+func (h *AdminHandler) LogoutUser(c *gin.Context) {
+	var req models.AdminActionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer tx.Rollback()
+
+	var userID string
+	err = tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id::text = $1`, c.Param("user_id")).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	revoked, err := revokeAllSessions(ctx, tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if err := record(ctx, tx, entryFor(c, ActionLogout, userID, req.Reason)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User signed out everywhere", "sessions_revoked": revoked})
+}
@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const findUserQuery = `SELECT id FROM users WHERE id::text = \$1`
+
+// --- TESTS ---
+
+func TestLogoutUser(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(findUserQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("user-1"))
+	mock.ExpectExec(revokeAllQuery).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(auditInsertQuery).
+		WithArgs("admin-1", ActionLogout, "user-1", "", "abuse report #42", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	w := request(router, "POST", "/admin/users/user-1/logout", adminActionReason)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"sessions_revoked":3`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogoutUser_NotFound(t *testing.T) {
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(findUserQuery).WithArgs("missing").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	assert.Equal(t, http.StatusNotFound, request(router, "POST", "/admin/users/missing/logout", adminActionReason).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
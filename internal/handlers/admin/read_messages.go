@@ -0,0 +1,92 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/config"
+	"personal-assistant-backend/internal/models"
+)
+
+// maxReasonLength matches the limit on AdminActionReq.Reason
+const maxReasonLength = 500
+
+// ReadMessages godoc
+// @Summary Read a user's messages (break-glass)
+// @Description Returns a chat's messages, oldest first. Only available while ADMIN_BREAK_GLASS is set, and only with a reason. The read is written to the audit log before any message is loaded; if it can't be recorded, nothing is returned. Admins only.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param chat_id path string true "Chat ID"
+// @Param reason query string true "Why the messages are needed, for the audit log"
+// @Success 200 {object} models.MessageListResponse
+// @Failure 400 {object} map[string]string "Missing reason"
+// @Failure 403 {object} map[string]string "Not an admin or break-glass access is off"
+// @Failure 404 {object} map[string]string "Chat not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /admin/users/{user_id}/chats/{chat_id}/messages [get]
+func (h *AdminHandler) ReadMessages(c *gin.Context) {
+	if !config.AdminBreakGlass() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "break-glass access is off"})
+		return
+	}
+	reason := strings.TrimSpace(c.Query("reason"))
+	if reason == "" || len(reason) > maxReasonLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a reason of at most 500 characters is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var userID, chatID string
+	err := h.DB.QueryRowContext(ctx, `
+		SELECT user_id, id FROM chats WHERE id::text = $1 AND user_id::text = $2
+	`, c.Param("chat_id"), c.Param("user_id")).Scan(&userID, &chatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	// ✅ No audit entry, no messages
+	entry := entryFor(c, ActionReadMessages, userID, reason)
+	entry.TargetChatID = chatID
+	if err := record(ctx, h.DB, entry); err != nil {
+		log.Printf("❌ Failed to audit break-glass read of chat %s: %v\n", chatID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	log.Printf("⚠️ Admin %s read messages of chat %s (user %s)\n", entry.AdminID, chatID, userID)
+
+	rows, err := h.DB.QueryContext(ctx, `
+		SELECT id, chat_id, role, content, status, created_at
+		FROM messages
+		WHERE chat_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, chatID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer rows.Close()
+
+	messages := []models.Message{}
+	for rows.Next() {
+		var m models.Message
+		if err := rows.Scan(&m.ID, &m.ChatID, &m.Role, &m.Content, &m.Status, &m.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan error"})
+			return
+		}
+		messages = append(messages, m)
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, models.MessageListResponse{Messages: messages})
+}
@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	ownedChatQuery    = `SELECT user_id, id FROM chats WHERE id::text = \$1 AND user_id::text = \$2`
+	readMessagesQuery = `SELECT id, chat_id, role, content, status, created_at FROM messages WHERE chat_id = \$1`
+	readMessagesPath  = "/admin/users/user-1/chats/chat-1/messages?reason=legal+hold+%2317"
+)
+
+// --- TESTS ---
+
+func TestReadMessages_BreakGlassOff(t *testing.T) {
+	t.Setenv("ADMIN_BREAK_GLASS", "")
+	router, mock := setupAdminRouter(t)
+
+	w := request(router, "GET", readMessagesPath, "")
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadMessages_AuditedRead(t *testing.T) {
+	t.Setenv("ADMIN_BREAK_GLASS", "true")
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectQuery(ownedChatQuery).
+		WithArgs("chat-1", "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "id"}).AddRow("user-1", "chat-1"))
+	mock.ExpectExec(auditInsertQuery).
+		WithArgs("admin-1", ActionReadMessages, "user-1", "chat-1", "legal hold #17", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(readMessagesQuery).
+		WithArgs("chat-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chat_id", "role", "content", "status", "created_at"}).
+			AddRow("m-1", "chat-1", "user", "hello", "complete", "2026-10-16T08:00:00Z"))
+
+	w := request(router, "GET", readMessagesPath, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"content":"hello"`)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadMessages_NoAuditNoMessages(t *testing.T) {
+	t.Setenv("ADMIN_BREAK_GLASS", "true")
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectQuery(ownedChatQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "id"}).AddRow("user-1", "chat-1"))
+	mock.ExpectExec(auditInsertQuery).WillReturnError(errors.New("db exploded"))
+
+	w := request(router, "GET", readMessagesPath, "")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "content")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadMessages_Refused(t *testing.T) {
+	t.Setenv("ADMIN_BREAK_GLASS", "true")
+	router, mock := setupAdminRouter(t)
+
+	mock.ExpectQuery(ownedChatQuery).WithArgs("chat-1", "someone-else").WillReturnError(sql.ErrNoRows)
+
+	assert.Equal(t, http.StatusBadRequest, request(router, "GET", "/admin/users/user-1/chats/chat-1/messages", "").Code)
+	assert.Equal(t, http.StatusBadRequest, request(router, "GET", "/admin/users/user-1/chats/chat-1/messages?reason="+strings.Repeat("x", 501), "").Code)
+	assert.Equal(t, http.StatusNotFound, request(router, "GET", "/admin/users/someone-else/chats/chat-1/messages?reason=x", "").Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
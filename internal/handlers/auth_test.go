@@ -21,7 +21,7 @@ func setupAuthCheckRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
 		t.Fatalf("failed to open sqlmock: %v", err)
 	}
 
-	authHandler := NewAuthHandler(db)
+	authHandler := NewAuthHandler(db, nil, nil)
 	r := gin.Default()
 
 	// Simulate JWT middleware setting userID in context
@@ -57,7 +57,7 @@ func TestAuthCheck_Unauthorized(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	db, _, _ := sqlmock.New()
-	authHandler := NewAuthHandler(db)
+	authHandler := NewAuthHandler(db, nil, nil)
 	r := gin.Default()
 
 	// Missing userID in context
@@ -1,11 +1,37 @@
 package chat
 
-import "database/sql"
+import (
+	"database/sql"
+	"os"
+	"sync"
+
+	"personal-assistant-backend/internal/config"
+	"personal-assistant-backend/internal/llm"
+)
 
 type ChatHandler struct {
-	DB *sql.DB
+	DB                   *sql.DB
+	LLM                  llm.Provider
+	Tokenizer            llm.Tokenizer
+	AllowedModels        []string
+	SystemPrompt         string
+	ContextTokens        map[string]int
+	ReservedOutputTokens int
+
+	// Background runs async work such as summary refreshes; nil means a goroutine.
+	Background func(task func())
+
+	summarizing sync.Map // chat IDs with a summary refresh in flight
 }
 
-func NewChatHandler(db *sql.DB) *ChatHandler {
-	return &ChatHandler{DB: db}
+func NewChatHandler(db *sql.DB, provider llm.Provider) *ChatHandler {
+	return &ChatHandler{
+		DB:                   db,
+		LLM:                  provider,
+		Tokenizer:            llm.ApproxTokenizer{},
+		AllowedModels:        config.AllowedModels(),
+		SystemPrompt:         os.Getenv("DEFAULT_SYSTEM_PROMPT"),
+		ContextTokens:        config.ContextTokens(),
+		ReservedOutputTokens: config.ReservedOutputTokens(),
+	}
 }
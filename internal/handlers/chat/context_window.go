@@ -0,0 +1,131 @@
+package chat
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"personal-assistant-backend/internal/config"
+	"personal-assistant-backend/internal/llm"
+	"personal-assistant-backend/internal/models"
+)
+
+// chatContext is everything SendMessage needs about a chat besides its messages.
+type chatContext struct {
+	Title         string
+	Settings      models.ChatSettings
+	PersonaPrompt string
+	Summary       string
+	SummaryUntil  sql.NullTime // messages at or before this are covered by Summary
+}
+
+// loadChatContext verifies ownership and loads title, settings, persona prompt
+// and rolling summary in one query. It returns sql.ErrNoRows for unknown chats.
+func (h *ChatHandler) loadChatContext(chatID, userID string) (chatContext, error) {
+	var cc chatContext
+	err := h.DB.QueryRow(`
+		SELECT c.model, c.temperature, c.max_output_tokens, c.top_p,
+			COALESCE(p.system_prompt, ''), COALESCE(s.content, ''), s.covered_until, c.title
+		FROM chats c
+		LEFT JOIN personas p ON p.id = c.persona_id
+		LEFT JOIN chat_summaries s ON s.chat_id = c.id
+		WHERE c.id = $1 AND c.user_id = $2
+	`, chatID, userID).Scan(
+		&cc.Settings.Model, &cc.Settings.Temperature, &cc.Settings.MaxOutputTokens, &cc.Settings.TopP,
+		&cc.PersonaPrompt, &cc.Summary, &cc.SummaryUntil, &cc.Title,
+	)
+	return cc, err
+}
+
+// tokenizer returns the configured tokenizer, defaulting to the approximate one.
+func (h *ChatHandler) tokenizer() llm.Tokenizer {
+	if h.Tokenizer == nil {
+		return llm.ApproxTokenizer{}
+	}
+	return h.Tokenizer
+}
+
+// promptBudget is the model's context window minus the tokens reserved for the reply.
+func (h *ChatHandler) promptBudget(s models.ChatSettings) int {
+	window, ok := h.ContextTokens[h.model(s)]
+	if !ok {
+		window = config.DefaultContextTokens
+	}
+
+	reserved := h.ReservedOutputTokens
+	if s.MaxOutputTokens != nil {
+		reserved = *s.MaxOutputTokens
+	} else if reserved <= 0 {
+		reserved = config.DefaultReservedOutputTokens
+	}
+
+	return window - reserved
+}
+
+// buildContext fills the prompt with the system prompt, the rolling summary,
+// as much unsummarized history as fits the chat's budget, and the new user
+// message. Token counts missing from message rows are computed and cached.
+//
+// When history overflows, it also returns the created_at of the newest
+// message left out; everything up to it should be folded into the summary.
+func (h *ChatHandler) buildContext(chatID string, cc chatContext, content string) (*llm.ContextBuilder, *time.Time, error) {
+	builder := llm.NewContextBuilder(h.promptBudget(cc.Settings), h.tokenizer())
+	if prompt := h.systemPrompt(cc.PersonaPrompt); prompt != "" {
+		builder.AddSystem(prompt)
+	}
+	if cc.Summary != "" {
+		builder.AddSystem(summaryPrefix + cc.Summary)
+	}
+	builder.SetCurrent(llm.Message{Role: llm.RoleUser, Content: content}, builder.Count(content))
+
+	rows, err := h.DB.Query(`
+		SELECT id, role, content, token_count, created_at
+		FROM messages
+		WHERE chat_id = $1 AND ($2::timestamptz IS NULL OR created_at > $2)
+		ORDER BY created_at DESC
+	`, chatID, cc.SummaryUntil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var overflowAt *time.Time
+	uncounted := map[string]int{}
+	for rows.Next() {
+		var id, role, text string
+		var tokens sql.NullInt64
+		var createdAt time.Time
+		if err := rows.Scan(&id, &role, &text, &tokens, &createdAt); err != nil {
+			return nil, nil, err
+		}
+		// Replies cut off before their first token add nothing to the prompt
+		if text == "" {
+			continue
+		}
+
+		n := int(tokens.Int64)
+		if !tokens.Valid {
+			n = builder.Count(text)
+			uncounted[id] = n
+		}
+		if !builder.AddHistory(llm.Message{Role: role, Content: text}, n) {
+			overflowAt = &createdAt
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	h.cacheTokenCounts(uncounted)
+	return builder, overflowAt, nil
+}
+
+// cacheTokenCounts stores computed token counts so later requests skip tokenizing.
+func (h *ChatHandler) cacheTokenCounts(counts map[string]int) {
+	for id, n := range counts {
+		if _, err := h.DB.Exec(`UPDATE messages SET token_count = $2 WHERE id = $1`, id, n); err != nil {
+			log.Printf("⚠️ Failed to cache token count for message %s: %v\n", id, err)
+		}
+	}
+}
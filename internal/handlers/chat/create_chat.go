@@ -9,14 +9,14 @@ import (
 
 // CreateChat godoc
 // @Summary Create a new chat
-// @Description Creates a blank chat session for the logged-in user. Optionally accepts a title.
+// @Description Creates a blank chat session for the logged-in user. Optionally accepts a title, generation settings (model, temperature, max_output_tokens, top_p) and a persona_id.
 // @Tags Chats
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param payload body models.CreateChatReq false "Optional chat title"
+// @Param payload body models.CreateChatReq false "Optional chat title, settings and persona"
 // @Success 201 {object} models.ChatCreateResponse
-// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 400 {object} map[string]string "Invalid payload, settings or persona"
 // @Failure 500 {object} map[string]string "Database error"
 // @Router /chats [post]
 func (h *ChatHandler) CreateChat(c *gin.Context) {
@@ -24,21 +24,48 @@ func (h *ChatHandler) CreateChat(c *gin.Context) {
 
 	var req models.CreateChatReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		// If no body or invalid, just use default title
-		req.Title = "New Chat"
+		// If no body or invalid, just use default title and settings
+		req = models.CreateChatReq{Title: defaultChatTitle}
 	}
 
 	title := req.Title
 	if title == "" {
-		title = "New Chat"
+		title = defaultChatTitle
+	}
+
+	var settings models.ChatSettings
+	if req.Settings != nil {
+		settings = *req.Settings
+	}
+	if err := h.validateSettings(settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid settings", "details": err.Error()})
+		return
+	}
+
+	// Only attach personas the user owns
+	if req.PersonaID != nil {
+		if err := validatePersonaID(*req.PersonaID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid settings", "details": err.Error()})
+			return
+		}
+		owned, err := h.personaOwned(*req.PersonaID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error", "details": err.Error()})
+			return
+		}
+		if !owned {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "persona not found"})
+			return
+		}
 	}
 
 	var chat models.Chat
-	err := h.DB.QueryRow(`
-		INSERT INTO chats (user_id, title)
-		VALUES ($1, $2)
-		RETURNING id, title, created_at
-	`, userID, title).Scan(&chat.ID, &chat.Title, &chat.CreatedAt)
+	err := scanChat(h.DB.QueryRow(`
+		INSERT INTO chats (user_id, title, model, temperature, max_output_tokens, top_p, persona_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+chatColumns,
+		userID, title, settings.Model, settings.Temperature, settings.MaxOutputTokens, settings.TopP, req.PersonaID,
+	), &chat)
 	if err != nil {
 		// Show DB error details (for debugging)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -25,7 +25,7 @@ func setupChatRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
 		t.Fatalf("failed to open sqlmock: %v", err)
 	}
 
-	h := &ChatHandler{DB: db}
+	h := &ChatHandler{DB: db, AllowedModels: []string{"gpt-a", "gpt-b"}}
 	r := gin.Default()
 
 	// Add fake userID in context middleware
@@ -38,16 +38,19 @@ func setupChatRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
 	return r, mock
 }
 
+// chatRowColumns matches chatColumns for mocked chat rows
+var chatRowColumns = []string{"id", "title", "created_at", "model", "temperature", "max_output_tokens", "top_p", "persona_id", "pinned", "archived", "updated_at"}
+
 // --- TESTS ---
 
 func TestCreateChat_Success(t *testing.T) {
 	router, mock := setupChatRouter(t)
 
 	now := time.Now()
-	mock.ExpectQuery(`INSERT INTO chats \(user_id, title\) VALUES \(\$1, \$2\) RETURNING id, title, created_at`).
-		WithArgs("user123", "My First Chat").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "created_at"}).
-			AddRow("chat-123", "My First Chat", now))
+	mock.ExpectQuery(`INSERT INTO chats \(user_id, title, model, temperature, max_output_tokens, top_p, persona_id\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\) RETURNING id, title, created_at`).
+		WithArgs("user123", "My First Chat", "", nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat-123", "My First Chat", now, "", nil, nil, nil, nil, false, false, now))
 
 	body := `{"title":"My First Chat"}`
 	req, _ := http.NewRequest("POST", "/chats", strings.NewReader(body))
@@ -69,10 +72,10 @@ func TestCreateChat_InvalidJSON_DefaultTitle(t *testing.T) {
 	router, mock := setupChatRouter(t)
 
 	now := time.Now()
-	mock.ExpectQuery(`INSERT INTO chats \(user_id, title\) VALUES \(\$1, \$2\) RETURNING id, title, created_at`).
-		WithArgs("user123", "New Chat").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "created_at"}).
-			AddRow("chat-999", "New Chat", now))
+	mock.ExpectQuery(`INSERT INTO chats \(user_id, title, model, temperature, max_output_tokens, top_p, persona_id\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\) RETURNING id, title, created_at`).
+		WithArgs("user123", "New Chat", "", nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat-999", "New Chat", now, "", nil, nil, nil, nil, false, false, now))
 
 	req, _ := http.NewRequest("POST", "/chats", strings.NewReader(`{invalid}`))
 	req.Header.Set("Content-Type", "application/json")
@@ -92,10 +95,10 @@ func TestCreateChat_EmptyTitle_DefaultTitle(t *testing.T) {
 	router, mock := setupChatRouter(t)
 
 	now := time.Now()
-	mock.ExpectQuery(`INSERT INTO chats \(user_id, title\) VALUES \(\$1, \$2\) RETURNING id, title, created_at`).
-		WithArgs("user123", "New Chat").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "created_at"}).
-			AddRow("chat-111", "New Chat", now))
+	mock.ExpectQuery(`INSERT INTO chats \(user_id, title, model, temperature, max_output_tokens, top_p, persona_id\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\) RETURNING id, title, created_at`).
+		WithArgs("user123", "New Chat", "", nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat-111", "New Chat", now, "", nil, nil, nil, nil, false, false, now))
 
 	body := `{"title":""}`
 	req, _ := http.NewRequest("POST", "/chats", strings.NewReader(body))
@@ -128,3 +131,122 @@ func TestCreateChat_DBError(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "db error")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestCreateChat_WithSettings(t *testing.T) {
+	router, mock := setupChatRouter(t)
+
+	now := time.Now()
+	temp := float32(0.3)
+	maxTokens := 500
+	mock.ExpectQuery(`INSERT INTO chats`).
+		WithArgs("user123", "Tuned", "gpt-b", &temp, &maxTokens, nil, nil).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat-222", "Tuned", now, "gpt-b", 0.3, 500, nil, nil, false, false, now))
+
+	body := `{"title":"Tuned","settings":{"model":"gpt-b","temperature":0.3,"max_output_tokens":500}}`
+	req, _ := http.NewRequest("POST", "/chats", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var resp models.ChatCreateResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "gpt-b", resp.Chat.Settings.Model)
+	assert.InDelta(t, 0.3, *resp.Chat.Settings.Temperature, 0.0001)
+	assert.Equal(t, 500, *resp.Chat.Settings.MaxOutputTokens)
+	assert.Nil(t, resp.Chat.Settings.TopP)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateChat_ModelNotAllowed(t *testing.T) {
+	router, mock := setupChatRouter(t)
+
+	body := `{"title":"Nope","settings":{"model":"gpt-unknown"}}`
+	req, _ := http.NewRequest("POST", "/chats", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid settings")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateChat_TemperatureOutOfRange(t *testing.T) {
+	router, _ := setupChatRouter(t)
+
+	body := `{"settings":{"temperature":3.5}}`
+	req, _ := http.NewRequest("POST", "/chats", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "temperature")
+}
+
+func TestCreateChat_WithPersona(t *testing.T) {
+	router, mock := setupChatRouter(t)
+
+	now := time.Now()
+	personaID := "3f2b8c1e-6d4a-4e8b-9c7a-1b2c3d4e5f60"
+	mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM personas WHERE id = \$1 AND user_id = \$2 \)`).
+		WithArgs(personaID, "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`INSERT INTO chats`).
+		WithArgs("user123", "Cooking", "", nil, nil, nil, &personaID).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat-333", "Cooking", now, "", nil, nil, nil, personaID, false, false, now))
+
+	body := `{"title":"Cooking","persona_id":"` + personaID + `"}`
+	req, _ := http.NewRequest("POST", "/chats", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var resp models.ChatCreateResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, personaID, *resp.Chat.PersonaID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateChat_PersonaNotOwned(t *testing.T) {
+	router, mock := setupChatRouter(t)
+
+	mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM personas WHERE id = \$1 AND user_id = \$2 \)`).
+		WithArgs("9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	body := `{"persona_id":"9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"}`
+	req, _ := http.NewRequest("POST", "/chats", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "persona not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateChat_PersonaNotUUID(t *testing.T) {
+	router, mock := setupChatRouter(t)
+
+	req, _ := http.NewRequest("POST", "/chats", strings.NewReader(`{"persona_id":"abc"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid settings")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -8,13 +8,17 @@ import (
 )
 
 // ListMessages godoc
-// @Summary Get all messages in a chat
-// @Description Returns the full conversation history for a given chat ID.
+// @Summary Get messages in a chat
+// @Description Returns a page of a chat's messages in chronological order, starting with the most recent page. Pass next_cursor back as `before` for older messages, or as `after` to fetch messages sent since.
 // @Tags Chats
 // @Security BearerAuth
 // @Produce  json
 // @Param chat_id path string true "Chat ID"
-// @Success 200 {array} models.Message
+// @Param limit query int false "Page size (1-100, default 50)"
+// @Param before query string false "Cursor: return messages older than this one"
+// @Param after query string false "Cursor: return messages newer than this one"
+// @Success 200 {object} models.MessageListResponse
+// @Failure 400 {object} map[string]string "Invalid limit or cursor"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 404 {object} map[string]string "Chat not found"
 // @Failure 500 {object} map[string]string "Database error"
@@ -23,9 +27,15 @@ func (h *ChatHandler) ListMessages(c *gin.Context) {
 	userID := c.GetString("userID")
 	chatID := c.Param("chat_id")
 
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination", "details": err.Error()})
+		return
+	}
+
 	// Verify chat ownership
 	var exists bool
-	err := h.DB.QueryRow(`
+	err = h.DB.QueryRow(`
 		SELECT EXISTS (
 			SELECT 1 FROM chats WHERE id = $1 AND user_id = $2
 		)
@@ -39,26 +49,32 @@ func (h *ChatHandler) ListMessages(c *gin.Context) {
 		return
 	}
 
-	// Fetch messages
+	// Fetch one page of messages
+	where, orderBy := p.keyset(2)
+	args := append([]any{chatID}, p.cursorArgs()...)
 	rows, err := h.DB.Query(`
-		SELECT id, chat_id, role, content, created_at
+		SELECT id, chat_id, role, content, status, created_at
 		FROM messages
-		WHERE chat_id = $1
-		ORDER BY created_at ASC
-	`, chatID)
+		WHERE chat_id = $1 AND `+where+`
+		ORDER BY `+orderBy+`
+		LIMIT $4
+	`, append(args, p.fetchLimit())...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 		return
 	}
 	defer rows.Close()
 
-	var messages []models.Message
+	messages := []models.Message{}
 	for rows.Next() {
 		var msg models.Message
-		if err := rows.Scan(&msg.ID, &msg.ChatID, &msg.Role, &msg.Content, &msg.CreatedAt); err == nil {
+		if err := rows.Scan(&msg.ID, &msg.ChatID, &msg.Role, &msg.Content, &msg.Status, &msg.CreatedAt); err == nil {
 			messages = append(messages, msg)
 		}
 	}
 
-	c.JSON(http.StatusOK, messages)
+	messages, next := paginate(p, messages, false, func(msg models.Message) (string, string) {
+		return msg.CreatedAt, msg.ID
+	})
+	c.JSON(http.StatusOK, models.MessageListResponse{Messages: messages, NextCursor: next})
 }
@@ -0,0 +1,175 @@
+package chat
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/pagination"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupListMessagesRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+
+	h := &ChatHandler{DB: db}
+	r := gin.Default()
+
+	// Middleware adds fake userID
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+
+	r.GET("/chats/:chat_id/messages", h.ListMessages)
+	return r, mock
+}
+
+func TestListMessages_Success(t *testing.T) {
+	router, mock := setupListMessagesRouter(t)
+
+	now := time.Now()
+
+	// Ownership check returns true
+	mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM chats WHERE id = \$1 AND user_id = \$2 \)`).
+		WithArgs("chat123", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	// Return two messages
+	mock.ExpectQuery(`SELECT id, chat_id, role, content, status, created_at FROM messages WHERE chat_id = \$1 AND .* ORDER BY created_at DESC, id DESC LIMIT \$4`).
+		WithArgs("chat123", nil, nil, pagination.DefaultPageSize+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chat_id", "role", "content", "status", "created_at"}).
+			AddRow("msg2", "chat123", "assistant", "Hi th", "cancelled", now).
+			AddRow("msg1", "chat123", "user", "Hello", "complete", now.Add(-time.Second)))
+
+	req, _ := http.NewRequest("GET", "/chats/chat123/messages", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Newest page, oldest message first
+	var resp models.MessageListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	msgs := resp.Messages
+	assert.Len(t, msgs, 2)
+	assert.Empty(t, resp.NextCursor)
+	assert.Equal(t, "Hello", msgs[0].Content)
+	assert.Equal(t, "assistant", msgs[1].Role)
+	assert.Equal(t, models.MessageCancelled, msgs[1].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListMessages_ChatNotFound(t *testing.T) {
+	router, mock := setupListMessagesRouter(t)
+
+	mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM chats WHERE id = \$1 AND user_id = \$2 \)`).
+		WithArgs("chat404", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	req, _ := http.NewRequest("GET", "/chats/chat404/messages", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "chat not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListMessages_DBErrorOnOwnershipCheck(t *testing.T) {
+	router, mock := setupListMessagesRouter(t)
+
+	mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM chats WHERE id = \$1 AND user_id = \$2 \)`).
+		WithArgs("chat500", "user123").
+		WillReturnError(errors.New("db exploded"))
+
+	req, _ := http.NewRequest("GET", "/chats/chat500/messages", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "db error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListMessages_DBErrorOnMessagesQuery(t *testing.T) {
+	router, mock := setupListMessagesRouter(t)
+
+	mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM chats WHERE id = \$1 AND user_id = \$2 \)`).
+		WithArgs("chat999", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	mock.ExpectQuery(`SELECT id, chat_id, role, content, status, created_at FROM messages WHERE chat_id = \$1`).
+		WithArgs("chat999", nil, nil, pagination.DefaultPageSize+1).
+		WillReturnError(sql.ErrConnDone)
+
+	req, _ := http.NewRequest("GET", "/chats/chat999/messages", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "db error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListMessages_OlderPageOnTies(t *testing.T) {
+	router, mock := setupListMessagesRouter(t)
+
+	tie := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	before := pagination.Encode(tie.Format(time.RFC3339Nano), "msg3")
+
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs("chat123", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`\(created_at, id\) < \(\$2::timestamptz, \$3::uuid\)\) ORDER BY created_at DESC, id DESC LIMIT \$4`).
+		WithArgs("chat123", tie, "msg3", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chat_id", "role", "content", "status", "created_at"}).
+			AddRow("msg2", "chat123", "assistant", "Two", "complete", tie).
+			AddRow("msg1", "chat123", "user", "One", "complete", tie).
+			AddRow("msg0", "chat123", "user", "Zero", "complete", tie))
+
+	req, _ := http.NewRequest("GET", "/chats/chat123/messages?limit=2&before="+before, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.MessageListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Messages, 2) {
+		assert.Equal(t, "msg1", resp.Messages[0].ID)
+		assert.Equal(t, "msg2", resp.Messages[1].ID)
+	}
+
+	// Next older page resumes after msg1 within the same timestamp
+	cur, err := pagination.Decode(resp.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, "msg1", cur.ID)
+	assert.True(t, tie.Equal(cur.CreatedAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListMessages_InvalidCursor(t *testing.T) {
+	router, mock := setupListMessagesRouter(t)
+
+	req, _ := http.NewRequest("GET", "/chats/chat123/messages?after=not-a-cursor", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid pagination")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
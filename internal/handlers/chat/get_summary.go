@@ -0,0 +1,53 @@
+package chat
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// GetChatSummary godoc
+// @Summary Get a chat's rolling summary
+// @Description Returns the summary of older messages that is sent to the model in place of history that no longer fits its context window.
+// @Tags Chats
+// @Security BearerAuth
+// @Produce json
+// @Param chat_id path string true "Chat ID"
+// @Success 200 {object} models.ChatSummaryResponse
+// @Failure 404 {object} map[string]string "Chat or summary not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /chats/{chat_id}/summary [get]
+func (h *ChatHandler) GetChatSummary(c *gin.Context) {
+	userID := c.GetString("userID")
+	chatID := c.Param("chat_id")
+
+	var content, coveredUntil, updatedAt sql.NullString
+	err := h.DB.QueryRow(`
+		SELECT s.content, s.covered_until, s.updated_at
+		FROM chats c
+		LEFT JOIN chat_summaries s ON s.chat_id = c.id
+		WHERE c.id = $1 AND c.user_id = $2
+	`, chatID, userID).Scan(&content, &coveredUntil, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error", "details": err.Error()})
+		return
+	}
+	if !content.Valid {
+		c.JSON(http.StatusNotFound, gin.H{"error": "summary not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ChatSummaryResponse{Summary: models.ChatSummary{
+		ChatID:       chatID,
+		Content:      content.String,
+		CoveredUntil: coveredUntil.String,
+		UpdatedAt:    updatedAt.String,
+	}})
+}
@@ -0,0 +1,89 @@
+package chat
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupGetSummaryRouter sets up Gin + sqlmock for GetChatSummary tests
+func setupGetSummaryRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	h := &ChatHandler{DB: db}
+	r := gin.Default()
+
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+
+	r.GET("/chats/:chat_id/summary", h.GetChatSummary)
+	return r, mock
+}
+
+const getSummaryQuery = `SELECT s.content, s.covered_until, s.updated_at FROM chats c LEFT JOIN chat_summaries s ON s.chat_id = c.id WHERE c.id = \$1 AND c.user_id = \$2`
+
+func getSummary(router *gin.Engine) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/chats/chat123/summary", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// --- TESTS ---
+
+func TestGetChatSummary_Success(t *testing.T) {
+	router, mock := setupGetSummaryRouter(t)
+
+	mock.ExpectQuery(getSummaryQuery).
+		WithArgs("chat123", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"content", "covered_until", "updated_at"}).
+			AddRow("User is planning a trip.", "2025-01-01T12:00:00Z", "2025-01-02T08:00:00Z"))
+
+	w := getSummary(router)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"content":"User is planning a trip."`)
+	assert.Contains(t, w.Body.String(), `"covered_until":"2025-01-01T12:00:00Z"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChatSummary_NoSummaryYet(t *testing.T) {
+	router, mock := setupGetSummaryRouter(t)
+
+	mock.ExpectQuery(getSummaryQuery).
+		WithArgs("chat123", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"content", "covered_until", "updated_at"}).
+			AddRow(nil, nil, nil))
+
+	w := getSummary(router)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "summary not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChatSummary_ChatNotFound(t *testing.T) {
+	router, mock := setupGetSummaryRouter(t)
+
+	mock.ExpectQuery(getSummaryQuery).
+		WithArgs("chat123", "user123").
+		WillReturnError(sql.ErrNoRows)
+
+	w := getSummary(router)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "chat not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -8,23 +8,36 @@ import (
 )
 
 // ListChats godoc
-// @Summary List all chats for current user
-// @Description Returns all chat sessions created by the logged-in user.
+// @Summary List chats for current user
+// @Description Returns the logged-in user's chats, newest first, one page at a time. Pass next_cursor back as `before` for the following page, or as `after` to fetch chats created since.
 // @Tags Chats
 // @Security BearerAuth
 // @Produce json
+// @Param limit query int false "Page size (1-100, default 50)"
+// @Param before query string false "Cursor: return chats older than this one"
+// @Param after query string false "Cursor: return chats newer than this one"
 // @Success 200 {object} models.ChatListResponse
+// @Failure 400 {object} map[string]string "Invalid limit or cursor"
 // @Failure 500 {object} map[string]string "Database error"
 // @Router /chats [get]
 func (h *ChatHandler) ListChats(c *gin.Context) {
 	userID := c.GetString("userID")
 
+	p, err := parsePage(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination", "details": err.Error()})
+		return
+	}
+
+	where, orderBy := p.keyset(2)
+	args := append([]any{userID}, p.cursorArgs()...)
 	rows, err := h.DB.Query(`
-		SELECT id, title, created_at
+		SELECT `+chatColumns+`
 		FROM chats
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-	`, userID)
+		WHERE user_id = $1 AND `+where+`
+		ORDER BY `+orderBy+`
+		LIMIT $4
+	`, append(args, p.fetchLimit())...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 		return
@@ -34,12 +47,15 @@ func (h *ChatHandler) ListChats(c *gin.Context) {
 	var chats []models.Chat
 	for rows.Next() {
 		var chat models.Chat
-		if err := rows.Scan(&chat.ID, &chat.Title, &chat.CreatedAt); err != nil {
+		if err := scanChat(rows, &chat); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan error"})
 			return
 		}
 		chats = append(chats, chat)
 	}
 
-	c.JSON(http.StatusOK, models.ChatListResponse{Chats: chats})
+	chats, next := paginate(p, chats, true, func(chat models.Chat) (string, string) {
+		return chat.CreatedAt, chat.ID
+	})
+	c.JSON(http.StatusOK, models.ChatListResponse{Chats: chats, NextCursor: next})
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/pagination"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
@@ -44,11 +45,11 @@ func TestListChats_Success(t *testing.T) {
 
 	now := time.Now()
 
-	mock.ExpectQuery(`SELECT id, title, created_at FROM chats WHERE user_id = \$1 ORDER BY created_at DESC`).
-		WithArgs("user123").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "created_at"}).
-			AddRow("chat1", "First Chat", now).
-			AddRow("chat2", "Second Chat", now.Add(-time.Hour)))
+	mock.ExpectQuery(`SELECT id, title, created_at, model, temperature, max_output_tokens, top_p, persona_id, pinned, archived, updated_at FROM chats WHERE user_id = \$1 AND .* ORDER BY created_at DESC, id DESC LIMIT \$4`).
+		WithArgs("user123", nil, nil, pagination.DefaultPageSize+1).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat1", "First Chat", now, "", nil, nil, nil, nil, false, false, now).
+			AddRow("chat2", "Second Chat", now.Add(-time.Hour), "gpt-b", 0.7, nil, 0.9, "persona-1", true, false, now))
 
 	req, _ := http.NewRequest("GET", "/chats", nil)
 	w := httptest.NewRecorder()
@@ -60,14 +61,18 @@ func TestListChats_Success(t *testing.T) {
 	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
 	assert.Len(t, resp.Chats, 2)
 	assert.Equal(t, "First Chat", resp.Chats[0].Title)
+	assert.Equal(t, "gpt-b", resp.Chats[1].Settings.Model)
+	assert.Nil(t, resp.Chats[1].Settings.MaxOutputTokens)
+	assert.Nil(t, resp.Chats[0].PersonaID)
+	assert.Equal(t, "persona-1", *resp.Chats[1].PersonaID)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestListChats_DBError(t *testing.T) {
 	router, mock := setupListChatsRouter(t)
 
-	mock.ExpectQuery(`SELECT id, title, created_at FROM chats WHERE user_id = \$1 ORDER BY created_at DESC`).
-		WithArgs("user123").
+	mock.ExpectQuery(`SELECT id, title, created_at, model, temperature, max_output_tokens, top_p, persona_id, pinned, archived, updated_at FROM chats WHERE user_id = \$1 AND .* ORDER BY created_at DESC, id DESC LIMIT \$4`).
+		WithArgs("user123", nil, nil, pagination.DefaultPageSize+1).
 		WillReturnError(errors.New("db exploded"))
 
 	req, _ := http.NewRequest("GET", "/chats", nil)
@@ -84,8 +89,8 @@ func TestListChats_ScanError(t *testing.T) {
 
 	// Simulate a broken row (extra column value will trigger Scan error)
 	mockRows := sqlmock.NewRows([]string{"id", "title"}).AddRow("chat1", "Broken Chat")
-	mock.ExpectQuery(`SELECT id, title, created_at FROM chats`).
-		WithArgs("user123").
+	mock.ExpectQuery(`SELECT id, title, created_at, model, temperature, max_output_tokens, top_p, persona_id, pinned, archived, updated_at FROM chats`).
+		WithArgs("user123", nil, nil, pagination.DefaultPageSize+1).
 		WillReturnRows(mockRows)
 
 	req, _ := http.NewRequest("GET", "/chats", nil)
@@ -96,3 +101,93 @@ func TestListChats_ScanError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "scan error")
 }
+
+func TestListChats_NextCursorOnTies(t *testing.T) {
+	router, mock := setupListChatsRouter(t)
+
+	// Three chats share a timestamp; the id orders them
+	tie := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`ORDER BY created_at DESC, id DESC LIMIT \$4`).
+		WithArgs("user123", nil, nil, 3).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("c3", "Three", tie, "", nil, nil, nil, nil, false, false, tie).
+			AddRow("c2", "Two", tie, "", nil, nil, nil, nil, false, false, tie).
+			AddRow("c1", "One", tie, "", nil, nil, nil, nil, false, false, tie))
+
+	req, _ := http.NewRequest("GET", "/chats?limit=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.ChatListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Chats, 2) {
+		assert.Equal(t, "c3", resp.Chats[0].ID)
+		assert.Equal(t, "c2", resp.Chats[1].ID)
+	}
+
+	// The cursor carries the id so the next page resumes between tied rows
+	cur, err := pagination.Decode(resp.NextCursor)
+	assert.NoError(t, err)
+	assert.True(t, tie.Equal(cur.CreatedAt))
+	assert.Equal(t, "c2", cur.ID)
+
+	mock.ExpectQuery(`WHERE user_id = \$1 AND \(\$2::timestamptz IS NULL OR \(created_at, id\) < \(\$2::timestamptz, \$3::uuid\)\) ORDER BY created_at DESC, id DESC LIMIT \$4`).
+		WithArgs("user123", cur.CreatedAt, "c2", 3).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("c1", "One", tie, "", nil, nil, nil, nil, false, false, tie))
+
+	req, _ = http.NewRequest("GET", "/chats?limit=2&before="+resp.NextCursor, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	resp = models.ChatListResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Chats, 1)
+	assert.Empty(t, resp.NextCursor)
+	assert.NotContains(t, w.Body.String(), "next_cursor")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListChats_AfterKeepsNewestFirst(t *testing.T) {
+	router, mock := setupListChatsRouter(t)
+
+	now := time.Now().UTC()
+	after := pagination.Encode(now.Add(-time.Hour).Format(time.RFC3339Nano), "c0")
+
+	// Walking forward fetches oldest-first; the response is still newest-first
+	mock.ExpectQuery(`\(created_at, id\) > \(\$2::timestamptz, \$3::uuid\)\) ORDER BY created_at ASC, id ASC LIMIT \$4`).
+		WithArgs("user123", sqlmock.AnyArg(), "c0", 2).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("c1", "One", now.Add(-time.Minute), "", nil, nil, nil, nil, false, false, now).
+			AddRow("c2", "Two", now, "", nil, nil, nil, nil, false, false, now))
+
+	req, _ := http.NewRequest("GET", "/chats?limit=1&after="+after, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp models.ChatListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Chats, 1) {
+		assert.Equal(t, "c1", resp.Chats[0].ID)
+	}
+
+	cur, err := pagination.Decode(resp.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, "c1", cur.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListChats_InvalidPagination(t *testing.T) {
+	router, _ := setupListChatsRouter(t)
+
+	for _, q := range []string{"limit=0", "limit=101", "limit=abc", "before=!!!", "before=" + pagination.Encode("yesterday", "c1"), "before=a&after=b"} {
+		req, _ := http.NewRequest("GET", "/chats?"+q, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, q)
+		assert.Contains(t, w.Body.String(), "invalid pagination")
+	}
+}
@@ -0,0 +1,80 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/pagination"
+)
+
+// page is one keyset-paginated request. Without a cursor it starts at the
+// newest rows; After walks toward newer rows instead of older ones.
+type page struct {
+	Limit  int
+	Cursor *pagination.Cursor
+	After  bool
+}
+
+// parsePage reads ?limit=, ?before= and ?after= from the query string.
+func parsePage(c *gin.Context) (page, error) {
+	limit, err := pagination.ParseLimit(c)
+	if err != nil {
+		return page{}, err
+	}
+	p := page{Limit: limit}
+
+	before, after := c.Query("before"), c.Query("after")
+	if before != "" && after != "" {
+		return p, errors.New("use either before or after, not both")
+	}
+
+	switch {
+	case before != "":
+		p.Cursor, err = pagination.Decode(before)
+	case after != "":
+		p.Cursor, err = pagination.Decode(after)
+		p.After = true
+	}
+	return p, err
+}
+
+// keyset returns the WHERE condition and ORDER BY clause that walk past the
+// cursor, which is bound to placeholders $n (created_at) and $n+1 (id).
+func (p page) keyset(n int) (where, orderBy string) {
+	op, dir := "<", "DESC"
+	if p.After {
+		op, dir = ">", "ASC"
+	}
+	where = fmt.Sprintf("($%d::timestamptz IS NULL OR (created_at, id) %s ($%d::timestamptz, $%d::uuid))", n, op, n, n+1)
+	orderBy = fmt.Sprintf("created_at %s, id %s", dir, dir)
+	return where, orderBy
+}
+
+// cursorArgs returns the values for the keyset placeholders.
+func (p page) cursorArgs() []any {
+	return p.Cursor.Args()
+}
+
+// fetchLimit asks for one extra row to tell whether another page exists.
+func (p page) fetchLimit() int {
+	return p.Limit + 1
+}
+
+// paginate trims rows fetched in keyset order to the page size, returns the
+// cursor for the next page in the same direction ("" when there is none),
+// and puts the rows in the endpoint's display order.
+func paginate[T any](p page, rows []T, newestFirst bool, key func(T) (createdAt, id string)) ([]T, string) {
+	var next string
+	if len(rows) > p.Limit {
+		rows = rows[:p.Limit]
+		next = pagination.Encode(key(rows[len(rows)-1]))
+	}
+
+	// Rows come back newest-first unless walking forward
+	if p.After == newestFirst {
+		slices.Reverse(rows)
+	}
+	return rows, next
+}
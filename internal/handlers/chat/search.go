@@ -0,0 +1,123 @@
+package chat
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/pagination"
+)
+
+// maxSearchQueryLength keeps pathological queries away from the planner.
+const maxSearchQueryLength = 256
+
+// Search godoc
+// @Summary Search chats and messages
+// @Description Full-text searches the logged-in user's messages and chat titles. Supports web-search syntax ("quoted phrases", OR, -exclude). Hits are ranked by relevance; matched terms in snippets are wrapped in **double asterisks**. Pass next_cursor back as `cursor` for the next page.
+// @Tags Chats
+// @Security BearerAuth
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Page size (1-100, default 50)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} models.SearchResponse
+// @Failure 400 {object} map[string]string "Missing query or invalid pagination"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /search [get]
+func (h *ChatHandler) Search(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" || len(query) > maxSearchQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query"})
+		return
+	}
+
+	limit, err := pagination.ParseLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination", "details": err.Error()})
+		return
+	}
+	offset, err := decodeOffset(c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pagination", "details": err.Error()})
+		return
+	}
+
+	// Rank first, then build snippets for just the page being returned
+	rows, err := h.DB.Query(`
+		WITH q AS (SELECT websearch_to_tsquery('english', $2) AS query),
+		hits AS (
+			SELECT m.chat_id, m.id::text AS message_id, m.role, m.content AS body,
+				ts_rank(m.search_vector, q.query) AS rank, m.created_at
+			FROM messages m
+			JOIN chats c ON c.id = m.chat_id
+			CROSS JOIN q
+			WHERE c.user_id = $1 AND m.search_vector @@ q.query
+			UNION ALL
+			SELECT c.id, '', '', c.title,
+				ts_rank(c.search_vector, q.query), c.created_at
+			FROM chats c
+			CROSS JOIN q
+			WHERE c.user_id = $1 AND c.search_vector @@ q.query
+			ORDER BY rank DESC, created_at DESC, chat_id, message_id
+			LIMIT $3 OFFSET $4
+		)
+		SELECT h.chat_id, c.title, h.message_id, h.role,
+			ts_headline('english', h.body, q.query, 'StartSel=**, StopSel=**, MinWords=10, MaxWords=30, MaxFragments=2'),
+			h.rank, h.created_at
+		FROM hits h
+		JOIN chats c ON c.id = h.chat_id
+		CROSS JOIN q
+		ORDER BY h.rank DESC, h.created_at DESC, h.chat_id, h.message_id
+	`, userID, query, limit+1, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer rows.Close()
+
+	hits := []models.SearchHit{}
+	for rows.Next() {
+		var hit models.SearchHit
+		if err := rows.Scan(&hit.ChatID, &hit.ChatTitle, &hit.MessageID, &hit.Role, &hit.Snippet, &hit.Rank, &hit.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan error"})
+			return
+		}
+		hits = append(hits, hit)
+	}
+
+	var next string
+	if len(hits) > limit {
+		hits = hits[:limit]
+		next = encodeOffset(offset + limit)
+	}
+
+	c.JSON(http.StatusOK, models.SearchResponse{Hits: hits, NextCursor: next})
+}
+
+// encodeOffset builds the opaque cursor for ranked results, which have no
+// stable keyset to resume from.
+func encodeOffset(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+// decodeOffset parses a cursor produced by encodeOffset; "" means the first page.
+func decodeOffset(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, errors.New("malformed cursor")
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(string(raw), "o:"))
+	if err != nil || n < 0 || !strings.HasPrefix(string(raw), "o:") {
+		return 0, errors.New("malformed cursor")
+	}
+	return n, nil
+}
@@ -0,0 +1,137 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/pagination"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupSearchRouter sets up Gin + sqlmock for Search
+func setupSearchRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	h := &ChatHandler{DB: db}
+	r := gin.Default()
+
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+
+	r.GET("/search", h.Search)
+	return r, mock
+}
+
+var searchRowColumns = []string{"chat_id", "title", "message_id", "role", "snippet", "rank", "created_at"}
+
+func search(router *gin.Engine, query string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/search?"+query, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// --- TESTS ---
+
+func TestSearch_RankedHits(t *testing.T) {
+	router, mock := setupSearchRouter(t)
+
+	now := time.Now()
+	mock.ExpectQuery(`WITH q AS \(SELECT websearch_to_tsquery\('english', \$2\) AS query\)`).
+		WithArgs("user123", "lisbon trip", pagination.DefaultPageSize+1, 0).
+		WillReturnRows(sqlmock.NewRows(searchRowColumns).
+			AddRow("chat1", "Lisbon trip", "", "", "**Lisbon** **trip**", 0.9, now).
+			AddRow("chat1", "Lisbon trip", "msg1", "user", "Planning a **trip** to **Lisbon** in May", 0.6, now))
+
+	w := search(router, "q="+url.QueryEscape(" lisbon trip "))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.SearchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Hits, 2) {
+		assert.Empty(t, resp.Hits[0].MessageID)
+		assert.Equal(t, "msg1", resp.Hits[1].MessageID)
+		assert.Equal(t, "Planning a **trip** to **Lisbon** in May", resp.Hits[1].Snippet)
+		assert.InDelta(t, 0.6, resp.Hits[1].Rank, 0.0001)
+	}
+	assert.Empty(t, resp.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearch_Pagination(t *testing.T) {
+	router, mock := setupSearchRouter(t)
+
+	now := time.Now()
+	mock.ExpectQuery(`LIMIT \$3 OFFSET \$4`).
+		WithArgs("user123", "lisbon", 3, 0).
+		WillReturnRows(sqlmock.NewRows(searchRowColumns).
+			AddRow("c1", "A", "m1", "user", "a", 0.9, now).
+			AddRow("c1", "A", "m2", "user", "b", 0.8, now).
+			AddRow("c2", "B", "m3", "user", "c", 0.7, now))
+
+	w := search(router, "q=lisbon&limit=2")
+
+	var resp models.SearchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Hits, 2)
+	assert.NotEmpty(t, resp.NextCursor)
+
+	mock.ExpectQuery(`LIMIT \$3 OFFSET \$4`).
+		WithArgs("user123", "lisbon", 3, 2).
+		WillReturnRows(sqlmock.NewRows(searchRowColumns).
+			AddRow("c2", "B", "m3", "user", "c", 0.7, now))
+
+	w = search(router, "q=lisbon&limit=2&cursor="+resp.NextCursor)
+
+	resp = models.SearchResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Hits, 1)
+	assert.Empty(t, resp.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearch_InvalidRequests(t *testing.T) {
+	router, _ := setupSearchRouter(t)
+
+	cases := map[string]string{
+		"":                      "invalid query",
+		"q=%20%20":              "invalid query",
+		"q=lisbon&limit=0":      "invalid pagination",
+		"q=lisbon&cursor=bogus": "invalid pagination",
+		"q=lisbon&cursor=" + pagination.Encode("2025-01-01T00:00:00Z", "c1"): "invalid pagination",
+	}
+	for query, want := range cases {
+		w := search(router, query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.Contains(t, w.Body.String(), want, query)
+	}
+}
+
+func TestSearch_DBError(t *testing.T) {
+	router, mock := setupSearchRouter(t)
+
+	mock.ExpectQuery(`WITH q AS`).WillReturnError(errors.New("db exploded"))
+
+	w := search(router, "q=lisbon")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "db error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,41 +1,20 @@
 package chat
 
 import (
-	"context"
-	"crypto/tls"
+	"database/sql"
 	"errors"
 	"io"
+	"log"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	openai "github.com/sashabaranov/go-openai"
 	"personal-assistant-backend/internal/models"
 )
 
-// ✅ Interface for streaming client
-type openAIStreamClient interface {
-	CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error)
-}
-
-// ✅ Factory (mockable + TLS-safe)
-var openAIStreamFactory = func(apiKey string) openAIStreamClient {
-	config := openai.DefaultConfig(apiKey)
-	config.HTTPClient = &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: false, // ✅ verifies properly if ca-certificates are installed
-			},
-		},
-		Timeout: 0, // allow long-lived connections for streaming
-	}
-	return openai.NewClientWithConfig(config)
-}
-
 // SendMessage godoc
 // @Summary Send a message in a chat and stream AI response
-// @Description Sends a message to a chat. The last 20 messages are sent as context to the AI model, and tokens stream back in real time.
+// @Description Sends a message to a chat. The persona (or default) system prompt, the rolling summary of older messages, and as much recent history as fits the model's token budget are sent as context to the chat's configured model, and tokens stream back in real time. History that no longer fits is summarized in the background. Untitled chats are named after their first exchange, and the new title follows `done` as a `title` event if it is ready within a few seconds. If the model fails mid-answer an `error` event is sent instead of `done`; if the client disconnects, generation stops. Either way the partial reply is saved with status `errored` or `cancelled`.
 // @Tags Chats
 // @Security BearerAuth
 // @Accept json
@@ -58,67 +37,29 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
-	// Verify chat ownership
-	var exists bool
-	err := h.DB.QueryRow(`
-		SELECT EXISTS (
-			SELECT 1 FROM chats WHERE id = $1 AND user_id = $2
-		)
-	`, chatID, userID).Scan(&exists)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error", "details": err.Error()})
-		return
-	}
-	if !exists {
+	// Verify chat ownership and load settings, persona prompt and summary
+	cc, err := h.loadChatContext(chatID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
 		return
 	}
-
-	// Fetch last 20 messages
-	rows, err := h.DB.Query(`
-		SELECT role, content
-		FROM messages
-		WHERE chat_id = $1
-		ORDER BY created_at DESC
-		LIMIT 20
-	`, chatID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load chat history"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error", "details": err.Error()})
 		return
 	}
-	defer rows.Close()
-
-	var history []openai.ChatCompletionMessage
-	for rows.Next() {
-		var role, content string
-		if err := rows.Scan(&role, &content); err == nil {
-			history = append([]openai.ChatCompletionMessage{{
-				Role:    role,
-				Content: content,
-			}}, history...)
-		}
-	}
 
-	// Append new user message
-	history = append(history, openai.ChatCompletionMessage{
-		Role:    "user",
-		Content: req.Content,
-	})
-
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "missing OPENAI_API_KEY in env"})
+	// Fit system prompt + summary + recent history + new message into the model's budget
+	builder, overflowAt, err := h.buildContext(chatID, cc, req.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load chat history"})
 		return
 	}
 
-	client := openAIStreamFactory(apiKey)
-	ctx := context.Background()
+	// ✅ The request context is cancelled when the client disconnects, which
+	// aborts the upstream completion instead of paying for tokens nobody reads
+	ctx := c.Request.Context()
 
-	stream, err := client.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{
-		Model:    "gpt-5-chat-latest",
-		Messages: history,
-		Stream:   true,
-	})
+	stream, err := h.LLM.Stream(ctx, h.completionRequest(cc.Settings, builder.Messages()))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "model error", "details": err.Error()})
 		return
@@ -133,10 +74,10 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	// Save user message before streaming
 	var userMsg models.Message
 	err = h.DB.QueryRow(`
-		INSERT INTO messages (chat_id, role, content, created_at)
-		VALUES ($1, 'user', $2, $3)
+		INSERT INTO messages (chat_id, role, content, token_count, created_at)
+		VALUES ($1, 'user', $2, $3, $4)
 		RETURNING id, created_at
-	`, chatID, req.Content, time.Now()).
+	`, chatID, req.Content, builder.Count(req.Content), time.Now()).
 		Scan(&userMsg.ID, &userMsg.CreatedAt)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save user message"})
@@ -146,44 +87,70 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	userMsg.ChatID = chatID
 	userMsg.Role = "user"
 	userMsg.Content = req.Content
+	userMsg.Status = models.MessageComplete
 
 	// Stream assistant response tokens to client
 	var fullResponse string
+	status := models.MessageComplete
 	c.Stream(func(w io.Writer) bool {
 		for {
-			resp, err := stream.Recv()
+			chunk, err := stream.Recv()
 			if errors.Is(err, io.EOF) {
 				break
 			}
 			if err != nil {
-				c.SSEvent("error", err.Error())
-				return false
+				// Nobody is left to tell when the client went away
+				if ctx.Err() != nil {
+					status = models.MessageCancelled
+				} else {
+					status = models.MessageErrored
+					c.SSEvent("error", err.Error())
+				}
+				break
 			}
 
-			if len(resp.Choices) > 0 {
-				delta := resp.Choices[0].Delta.Content
-				if delta != "" {
-					fullResponse += delta
-					c.SSEvent("message", delta)
-				}
+			if chunk.Delta != "" {
+				fullResponse += chunk.Delta
+				c.SSEvent("message", chunk.Delta)
 			}
 		}
 
-		// Save assistant message once stream finishes
+		// Save assistant message once the stream ends, partial replies included.
+		// Not with ctx: it is already cancelled if the client left.
 		var assistantMsg models.Message
 		err = h.DB.QueryRow(`
-			INSERT INTO messages (chat_id, role, content, created_at)
-			VALUES ($1, 'assistant', $2, $3)
+			INSERT INTO messages (chat_id, role, content, token_count, status, created_at)
+			VALUES ($1, 'assistant', $2, $3, $4, $5)
 			RETURNING id, created_at
-		`, chatID, fullResponse, time.Now()).
+		`, chatID, fullResponse, builder.Count(fullResponse), status, time.Now()).
 			Scan(&assistantMsg.ID, &assistantMsg.CreatedAt)
-		if err == nil {
+		if err != nil {
+			log.Printf("❌ Failed to save %s reply in chat %s: %v\n", status, chatID, err)
+		} else {
 			assistantMsg.ChatID = chatID
 			assistantMsg.Role = "assistant"
 			assistantMsg.Content = fullResponse
+			assistantMsg.Status = status
+		}
+
+		// Fold history that no longer fits into the rolling summary
+		if overflowAt != nil {
+			h.scheduleSummary(chatID, cc, *overflowAt)
 		}
 
+		if status != models.MessageComplete {
+			return false
+		}
 		c.SSEvent("done", "[DONE]")
+
+		// Name untitled chats after their first finished exchange; the reply is
+		// already complete, so the title trails it while the client listens
+		if err == nil && needsTitle(cc, builder) {
+			c.Writer.Flush()
+			if title, ok := h.awaitTitle(ctx, chatID, cc, req.Content, fullResponse); ok {
+				c.SSEvent("title", title)
+			}
+		}
 		return false
 	})
 }
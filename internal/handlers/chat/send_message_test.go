@@ -0,0 +1,443 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"personal-assistant-backend/internal/llm"
+	"personal-assistant-backend/internal/models"
+)
+
+// setupSendMessageRouter sets up Gin + sqlmock + fake LLM for SendMessage
+func setupSendMessageRouter(t *testing.T, provider *llm.FakeProvider, opts ...func(*ChatHandler)) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	h := &ChatHandler{
+		DB:            db,
+		LLM:           provider,
+		Tokenizer:     llm.FakeTokenizer{},
+		AllowedModels: []string{"gpt-a", "gpt-b"},
+		Background:    func(func()) {}, // summary refreshes are tested separately
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	r := gin.Default()
+
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+
+	r.POST("/chats/:chat_id/messages", h.SendMessage)
+	return r, mock
+}
+
+// settingsRowColumns matches the settings + persona + summary columns SendMessage loads
+var settingsRowColumns = []string{"model", "temperature", "max_output_tokens", "top_p", "system_prompt", "summary", "covered_until", "title"}
+
+// historyRowColumns matches the history columns the context builder loads
+var historyRowColumns = []string{"id", "role", "content", "token_count", "created_at"}
+
+// historyAt is a fixed created_at for history rows
+var historyAt = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+const assistantInsertQuery = `INSERT INTO messages \(chat_id, role, content, token_count, status, created_at\) VALUES \(\$1, 'assistant', \$2, \$3, \$4, \$5\)`
+
+const chatContextQuery = `SELECT c.model, c.temperature, c.max_output_tokens, c.top_p, COALESCE\(p.system_prompt, ''\), COALESCE\(s.content, ''\), s.covered_until, c.title FROM chats c LEFT JOIN personas p ON p.id = c.persona_id LEFT JOIN chat_summaries s ON s.chat_id = c.id WHERE c.id = \$1 AND c.user_id = \$2`
+
+// expectChatSettings mocks the ownership + settings lookup for a named chat without a summary
+func expectChatSettings(mock sqlmock.Sqlmock, row ...driver.Value) {
+	mock.ExpectQuery(chatContextQuery).
+		WithArgs("chat123", "user123").
+		WillReturnRows(sqlmock.NewRows(settingsRowColumns).AddRow(append(row, "", nil, "Trip planning")...))
+}
+
+// expectHistory mocks the newest-first history query
+func expectHistory(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+	mock.ExpectQuery(`SELECT id, role, content, token_count, created_at FROM messages WHERE chat_id = \$1 AND \(\$2::timestamptz IS NULL OR created_at > \$2\) ORDER BY created_at DESC`).
+		WithArgs("chat123", nil).
+		WillReturnRows(rows)
+}
+
+// expectSaves mocks the user + assistant message inserts
+func expectSaves(mock sqlmock.Sqlmock) {
+	now := time.Now()
+	mock.ExpectQuery(`INSERT INTO messages \(chat_id, role, content, token_count, created_at\) VALUES \(\$1, 'user', \$2, \$3, \$4\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("msg1", now))
+	mock.ExpectQuery(assistantInsertQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("msg2", now))
+}
+
+// streamRecorder adds CloseNotify so gin's c.Stream works with httptest
+type streamRecorder struct {
+	*httptest.ResponseRecorder
+	closed chan bool
+}
+
+func newStreamRecorder() *streamRecorder {
+	return &streamRecorder{ResponseRecorder: httptest.NewRecorder(), closed: make(chan bool, 1)}
+}
+
+func (r *streamRecorder) CloseNotify() <-chan bool {
+	return r.closed
+}
+
+// hangUpProvider cancels the request after the first streamed chunk, like a
+// client closing the app mid-answer
+type hangUpProvider struct {
+	*llm.FakeProvider
+	hangUp context.CancelFunc
+}
+
+func (p hangUpProvider) Stream(ctx context.Context, req llm.Request) (llm.Stream, error) {
+	stream, err := p.FakeProvider.Stream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return hangUpStream{Stream: stream, hangUp: p.hangUp}, nil
+}
+
+type hangUpStream struct {
+	llm.Stream
+	hangUp context.CancelFunc
+}
+
+func (s hangUpStream) Recv() (llm.Chunk, error) {
+	chunk, err := s.Stream.Recv()
+	s.hangUp()
+	return chunk, err
+}
+
+func sendHello(router *gin.Engine) *streamRecorder {
+	return sendHelloWithContext(router, context.Background())
+}
+
+func sendHelloWithContext(router *gin.Engine, ctx context.Context) *streamRecorder {
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/chats/chat123/messages", strings.NewReader(`{"content":"Hello"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := newStreamRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// --- TESTS ---
+
+func TestSendMessage_StreamsAndSaves(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "Hi there"}
+	router, mock := setupSendMessageRouter(t, provider)
+
+	now := time.Now()
+	expectChatSettings(mock, "", nil, nil, nil, "")
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns).
+		AddRow("m2", "assistant", "Earlier reply", 2, historyAt).
+		AddRow("m1", "user", "Earlier question", 2, historyAt))
+	mock.ExpectQuery(`INSERT INTO messages \(chat_id, role, content, token_count, created_at\) VALUES \(\$1, 'user', \$2, \$3, \$4\)`).
+		WithArgs("chat123", "Hello", 1, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("msg1", now))
+	mock.ExpectQuery(assistantInsertQuery).
+		WithArgs("chat123", "Hi there", 2, models.MessageComplete, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("msg2", now))
+
+	w := sendHello(router)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "event:message")
+	assert.Contains(t, w.Body.String(), "event:done")
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// History is sent oldest-first with the new message last
+	sent := provider.LastRequest().Messages
+	if assert.Len(t, sent, 3) {
+		assert.Equal(t, "Earlier question", sent[0].Content)
+		assert.Equal(t, "Earlier reply", sent[1].Content)
+		assert.Equal(t, llm.Message{Role: llm.RoleUser, Content: "Hello"}, sent[2])
+	}
+
+	// Chats without a model use the first allowed model
+	assert.Equal(t, "gpt-a", provider.LastRequest().Model)
+	assert.Nil(t, provider.LastRequest().Temperature)
+}
+
+func TestSendMessage_AppliesChatSettings(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "ok"}
+	router, mock := setupSendMessageRouter(t, provider)
+
+	expectChatSettings(mock, "gpt-b", 0.2, 256, 0.5, "")
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns))
+	expectSaves(mock)
+
+	w := sendHello(router)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	sent := provider.LastRequest()
+	assert.Equal(t, "gpt-b", sent.Model)
+	assert.InDelta(t, 0.2, *sent.Temperature, 0.0001)
+	assert.InDelta(t, 0.5, *sent.TopP, 0.0001)
+	assert.Equal(t, 256, sent.MaxTokens)
+}
+
+func TestSendMessage_TrimsHistoryToBudget(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "ok"}
+	// Window 40 - 10 reserved = 30 prompt tokens.
+	// system "be brief" (2+4) + current "Hello" (1+4) = 11, leaving 19.
+	router, mock := setupSendMessageRouter(t, provider, func(h *ChatHandler) {
+		h.SystemPrompt = "be brief"
+		h.ContextTokens = map[string]int{"gpt-a": 40}
+		h.ReservedOutputTokens = 10
+	})
+
+	expectChatSettings(mock, "", nil, nil, nil, "")
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns).
+		AddRow("m4", "assistant", "four", 5, historyAt). // 9 → 20
+		AddRow("m3", "user", "three", 5, historyAt).     // 9 → 29
+		AddRow("m2", "assistant", "two", 5, historyAt).  // 9 → 38, doesn't fit
+		AddRow("m1", "user", "one", 1, historyAt))
+	expectSaves(mock)
+
+	w := sendHello(router)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	sent := provider.LastRequest().Messages
+	assert.Equal(t, []llm.Message{
+		{Role: llm.RoleSystem, Content: "be brief"},
+		{Role: llm.RoleUser, Content: "three"},
+		{Role: llm.RoleAssistant, Content: "four"},
+		{Role: llm.RoleUser, Content: "Hello"},
+	}, sent)
+}
+
+func TestSendMessage_MaxOutputTokensReducesBudget(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "ok"}
+	router, mock := setupSendMessageRouter(t, provider, func(h *ChatHandler) {
+		h.ContextTokens = map[string]int{"gpt-a": 40}
+		h.ReservedOutputTokens = 10
+	})
+
+	// max_output_tokens 30 leaves 10 prompt tokens: current (5) + one 1-token message (5)
+	expectChatSettings(mock, "", nil, 30, nil, "")
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns).
+		AddRow("m2", "assistant", "two", 1, historyAt).
+		AddRow("m1", "user", "one", 1, historyAt))
+	expectSaves(mock)
+
+	w := sendHello(router)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, provider.LastRequest().Messages, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendMessage_CachesMissingTokenCounts(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "ok"}
+	router, mock := setupSendMessageRouter(t, provider)
+
+	expectChatSettings(mock, "", nil, nil, nil, "")
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns).
+		AddRow("m1", "user", "three words here", nil, historyAt))
+	mock.ExpectExec(`UPDATE messages SET token_count = \$2 WHERE id = \$1`).
+		WithArgs("m1", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectSaves(mock)
+
+	w := sendHello(router)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendMessage_SystemPrompts(t *testing.T) {
+	cases := []struct {
+		name          string
+		defaultPrompt string
+		personaPrompt string
+		want          string
+	}{
+		{"persona overrides default", "Be helpful.", "You are a chef.", "You are a chef."},
+		{"default without persona", "Be helpful.", "", "Be helpful."},
+		{"no prompts", "", "", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := &llm.FakeProvider{Reply: "ok"}
+			router, mock := setupSendMessageRouter(t, provider, func(h *ChatHandler) {
+				h.SystemPrompt = tc.defaultPrompt
+			})
+
+			expectChatSettings(mock, "", nil, nil, nil, tc.personaPrompt)
+			expectHistory(mock, sqlmock.NewRows(historyRowColumns))
+			expectSaves(mock)
+
+			w := sendHello(router)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+
+			sent := provider.LastRequest().Messages
+			if tc.want == "" {
+				assert.Len(t, sent, 1)
+				assert.Equal(t, llm.RoleUser, sent[0].Role)
+				return
+			}
+			assert.Len(t, sent, 2)
+			assert.Equal(t, llm.Message{Role: llm.RoleSystem, Content: tc.want}, sent[0])
+		})
+	}
+}
+
+func TestSendMessage_InvalidPayload(t *testing.T) {
+	router, _ := setupSendMessageRouter(t, llm.NewFakeProvider())
+
+	req, _ := http.NewRequest("POST", "/chats/chat123/messages", strings.NewReader(`{"content":""}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid payload")
+}
+
+func TestSendMessage_ChatNotFound(t *testing.T) {
+	router, mock := setupSendMessageRouter(t, llm.NewFakeProvider())
+
+	mock.ExpectQuery(`SELECT c.model`).
+		WithArgs("chat404", "user123").
+		WillReturnError(sql.ErrNoRows)
+
+	req, _ := http.NewRequest("POST", "/chats/chat404/messages", strings.NewReader(`{"content":"Hello"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "chat not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendMessage_HistoryError(t *testing.T) {
+	router, mock := setupSendMessageRouter(t, llm.NewFakeProvider())
+
+	expectChatSettings(mock, "", nil, nil, nil, "")
+	mock.ExpectQuery(`SELECT id, role, content, token_count, created_at FROM messages`).
+		WillReturnError(sql.ErrConnDone)
+
+	w := sendHello(router)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "failed to load chat history")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendMessage_HistoryRowError(t *testing.T) {
+	router, mock := setupSendMessageRouter(t, llm.NewFakeProvider())
+
+	expectChatSettings(mock, "", nil, nil, nil, "")
+	// A failure mid-iteration must not send the model a truncated history
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns).
+		AddRow("m2", "assistant", "Hi there", 3, historyAt).
+		AddRow("m1", "user", "Hello", 1, historyAt).
+		RowError(1, sql.ErrConnDone))
+
+	w := sendHello(router)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "failed to load chat history")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendMessage_ModelError(t *testing.T) {
+	provider := &llm.FakeProvider{Err: errors.New("upstream down")}
+	router, mock := setupSendMessageRouter(t, provider)
+
+	expectChatSettings(mock, "", nil, nil, nil, "")
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns))
+
+	w := sendHello(router)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "model error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendMessage_SavesPartialReplyOnStreamError(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "Half an", StreamErr: errors.New("connection reset")}
+	router, mock := setupSendMessageRouter(t, provider)
+
+	expectChatSettings(mock, "", nil, nil, nil, "")
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns))
+	mock.ExpectQuery(`INSERT INTO messages \(chat_id, role, content, token_count, created_at\) VALUES \(\$1, 'user', \$2, \$3, \$4\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("msg1", time.Now()))
+	mock.ExpectQuery(assistantInsertQuery).
+		WithArgs("chat123", "Half an", 2, models.MessageErrored, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("msg2", time.Now()))
+
+	w := sendHello(router)
+
+	assert.Contains(t, w.Body.String(), "event:message")
+	assert.Contains(t, w.Body.String(), "event:error\ndata:connection reset")
+	assert.NotContains(t, w.Body.String(), "event:done")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendMessage_CancelsWhenClientDisconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	provider := hangUpProvider{FakeProvider: &llm.FakeProvider{Reply: "Hi there friend"}, hangUp: cancel}
+	router, mock := setupSendMessageRouter(t, provider.FakeProvider, func(h *ChatHandler) {
+		h.LLM = provider
+	})
+
+	expectChatSettings(mock, "", nil, nil, nil, "")
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns))
+	mock.ExpectQuery(`INSERT INTO messages \(chat_id, role, content, token_count, created_at\) VALUES \(\$1, 'user', \$2, \$3, \$4\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("msg1", time.Now()))
+	mock.ExpectQuery(assistantInsertQuery).
+		WithArgs("chat123", "Hi ", 1, models.MessageCancelled, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("msg2", time.Now()))
+
+	w := sendHelloWithContext(router, ctx)
+
+	assert.NotContains(t, w.Body.String(), "event:error")
+	assert.NotContains(t, w.Body.String(), "event:done")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendMessage_SkipsEmptyRepliesInHistory(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "ok"}
+	router, mock := setupSendMessageRouter(t, provider)
+
+	expectChatSettings(mock, "", nil, nil, nil, "")
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns).
+		AddRow("m2", "assistant", "", 0, historyAt).
+		AddRow("m1", "user", "Earlier question", 2, historyAt))
+	expectSaves(mock)
+
+	w := sendHello(router)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []llm.Message{
+		{Role: llm.RoleUser, Content: "Earlier question"},
+		{Role: llm.RoleUser, Content: "Hello"},
+	}, provider.LastRequest().Messages)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,97 @@
+package chat
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"personal-assistant-backend/internal/llm"
+	"personal-assistant-backend/internal/models"
+)
+
+// chatColumns is the column list every chat query selects, in scanChat order.
+const chatColumns = `id, title, created_at, model, temperature, max_output_tokens, top_p, persona_id, pinned, archived, updated_at`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanChat reads a row selected with chatColumns.
+func scanChat(row rowScanner, chat *models.Chat) error {
+	return row.Scan(
+		&chat.ID, &chat.Title, &chat.CreatedAt,
+		&chat.Settings.Model, &chat.Settings.Temperature,
+		&chat.Settings.MaxOutputTokens, &chat.Settings.TopP,
+		&chat.PersonaID, &chat.Pinned, &chat.Archived, &chat.UpdatedAt,
+	)
+}
+
+// validateSettings checks model against the allow-list and generation params against their ranges.
+func (h *ChatHandler) validateSettings(s models.ChatSettings) error {
+	if s.Model != "" && !slices.Contains(h.AllowedModels, s.Model) {
+		return fmt.Errorf("model %q is not allowed", s.Model)
+	}
+	if s.Temperature != nil && (*s.Temperature < 0 || *s.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+	if s.TopP != nil && (*s.TopP <= 0 || *s.TopP > 1) {
+		return fmt.Errorf("top_p must be greater than 0 and at most 1")
+	}
+	if s.MaxOutputTokens != nil && *s.MaxOutputTokens <= 0 {
+		return fmt.Errorf("max_output_tokens must be positive")
+	}
+	return nil
+}
+
+// uuidPattern matches the canonical UUID form persona IDs are stored in.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validatePersonaID rejects IDs Postgres couldn't cast to a uuid.
+func validatePersonaID(id string) error {
+	if !uuidPattern.MatchString(id) {
+		return fmt.Errorf("persona_id must be a UUID")
+	}
+	return nil
+}
+
+// personaOwned reports whether the persona exists and belongs to the user.
+func (h *ChatHandler) personaOwned(personaID, userID string) (bool, error) {
+	var exists bool
+	err := h.DB.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM personas WHERE id = $1 AND user_id = $2
+		)
+	`, personaID, userID).Scan(&exists)
+	return exists, err
+}
+
+// model returns the chat's model, falling back to the first allowed model.
+func (h *ChatHandler) model(s models.ChatSettings) string {
+	if s.Model == "" && len(h.AllowedModels) > 0 {
+		return h.AllowedModels[0]
+	}
+	return s.Model
+}
+
+// systemPrompt returns the persona's prompt, or the server default when the chat has none.
+func (h *ChatHandler) systemPrompt(personaPrompt string) string {
+	if personaPrompt != "" {
+		return personaPrompt
+	}
+	return h.SystemPrompt
+}
+
+// completionRequest builds an LLM request for messages using the chat's settings.
+func (h *ChatHandler) completionRequest(s models.ChatSettings, messages []llm.Message) llm.Request {
+	req := llm.Request{
+		Model:       h.model(s),
+		Messages:    messages,
+		Temperature: s.Temperature,
+		TopP:        s.TopP,
+	}
+	if s.MaxOutputTokens != nil {
+		req.MaxTokens = *s.MaxOutputTokens
+	}
+	return req
+}
@@ -0,0 +1,126 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"personal-assistant-backend/internal/llm"
+)
+
+// summaryPrefix introduces the rolling summary when it's injected as a system message.
+const summaryPrefix = "Summary of the earlier conversation:\n"
+
+// summaryTimeout bounds a single background summarization call.
+const summaryTimeout = 2 * time.Minute
+
+const summarizerPrompt = "You maintain a running summary of a conversation between a user and an assistant. " +
+	"Merge the new messages into the current summary. Keep facts, decisions, user preferences and open questions; " +
+	"drop small talk. Reply with the updated summary only."
+
+// background runs task asynchronously, or via the injected runner in tests.
+func (h *ChatHandler) background(task func()) {
+	if h.Background != nil {
+		h.Background(task)
+		return
+	}
+	go task()
+}
+
+// scheduleSummary refreshes the chat's summary in the background, skipping
+// chats that already have a refresh in flight on this machine.
+func (h *ChatHandler) scheduleSummary(chatID string, cc chatContext, until time.Time) {
+	if _, running := h.summarizing.LoadOrStore(chatID, true); running {
+		return
+	}
+	h.background(func() {
+		defer h.summarizing.Delete(chatID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), summaryTimeout)
+		defer cancel()
+
+		if err := h.refreshSummary(ctx, chatID, cc, until); err != nil {
+			log.Printf("⚠️ Failed to refresh summary for chat %s: %v\n", chatID, err)
+		}
+	})
+}
+
+// refreshSummary folds messages after the current summary and up to `until`
+// into a new summary, taking only as many as fit the model's budget.
+func (h *ChatHandler) refreshSummary(ctx context.Context, chatID string, cc chatContext, until time.Time) error {
+	rows, err := h.DB.QueryContext(ctx, `
+		SELECT role, content, token_count, created_at
+		FROM messages
+		WHERE chat_id = $1 AND ($2::timestamptz IS NULL OR created_at > $2) AND created_at <= $3
+		ORDER BY created_at ASC
+	`, chatID, cc.SummaryUntil, until)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	tok := h.tokenizer()
+	budget := h.promptBudget(cc.Settings) - tok.CountTokens(summarizerPrompt) - tok.CountTokens(cc.Summary)
+
+	var transcript strings.Builder
+	var coveredUntil time.Time
+	used := 0
+	for rows.Next() {
+		var role, content string
+		var tokens sql.NullInt64
+		var createdAt time.Time
+		if err := rows.Scan(&role, &content, &tokens, &createdAt); err != nil {
+			return err
+		}
+
+		n := int(tokens.Int64)
+		if !tokens.Valid {
+			n = tok.CountTokens(content)
+		}
+		n += llm.MessageOverheadTokens
+		if used+n > budget && used > 0 {
+			break
+		}
+		used += n
+
+		fmt.Fprintf(&transcript, "%s: %s\n", role, content)
+		coveredUntil = createdAt
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if coveredUntil.IsZero() {
+		return nil
+	}
+	rows.Close()
+
+	current := cc.Summary
+	if current == "" {
+		current = "(none yet)"
+	}
+	resp, err := h.LLM.Complete(ctx, llm.Request{
+		Model: h.model(cc.Settings),
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: summarizerPrompt},
+			{Role: llm.RoleUser, Content: "Current summary:\n" + current + "\n\nNew messages:\n" + transcript.String()},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Never move the summary backwards if another machine got there first
+	_, err = h.DB.ExecContext(ctx, `
+		INSERT INTO chat_summaries (chat_id, content, covered_until, updated_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (chat_id) DO UPDATE
+		SET content = EXCLUDED.content,
+			covered_until = EXCLUDED.covered_until,
+			updated_at = now()
+		WHERE chat_summaries.covered_until < EXCLUDED.covered_until
+	`, chatID, strings.TrimSpace(resp.Content), coveredUntil)
+	return err
+}
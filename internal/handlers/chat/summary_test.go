@@ -0,0 +1,124 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"personal-assistant-backend/internal/llm"
+)
+
+var summaryRowColumns = []string{"role", "content", "token_count", "created_at"}
+
+const summarySourceQuery = `SELECT role, content, token_count, created_at FROM messages WHERE chat_id = \$1 AND \(\$2::timestamptz IS NULL OR created_at > \$2\) AND created_at <= \$3 ORDER BY created_at ASC`
+
+const upsertSummaryQuery = `INSERT INTO chat_summaries \(chat_id, content, covered_until, updated_at\) VALUES \(\$1, \$2, \$3, now\(\)\) ON CONFLICT \(chat_id\) DO UPDATE`
+
+// runNow runs background tasks synchronously so their queries are part of the test
+func runNow(h *ChatHandler) {
+	h.Background = func(task func()) { task() }
+}
+
+// --- TESTS ---
+
+func TestSendMessage_InjectsSummary(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "ok"}
+	router, mock := setupSendMessageRouter(t, provider)
+
+	mock.ExpectQuery(chatContextQuery).
+		WithArgs("chat123", "user123").
+		WillReturnRows(sqlmock.NewRows(settingsRowColumns).
+			AddRow("", nil, nil, nil, "", "User is planning a trip.", historyAt, "Trip planning"))
+	// Only messages newer than the summary are loaded as history
+	mock.ExpectQuery(`SELECT id, role, content, token_count, created_at FROM messages`).
+		WithArgs("chat123", historyAt).
+		WillReturnRows(sqlmock.NewRows(historyRowColumns).
+			AddRow("m3", "assistant", "Lisbon is lovely", 3, historyAt.Add(time.Minute)))
+	expectSaves(mock)
+
+	w := sendHello(router)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Contains(t, w.Body.String(), "event:done")
+	assert.Equal(t, []llm.Message{
+		{Role: llm.RoleSystem, Content: summaryPrefix + "User is planning a trip."},
+		{Role: llm.RoleAssistant, Content: "Lisbon is lovely"},
+		{Role: llm.RoleUser, Content: "Hello"},
+	}, provider.LastRequest().Messages)
+}
+
+func TestSendMessage_OverflowRefreshesSummary(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "ok"}
+	// Window 20 - 10 reserved = 10 prompt tokens: current (5) + one 1-token message (5)
+	router, mock := setupSendMessageRouter(t, provider, runNow, func(h *ChatHandler) {
+		h.ContextTokens = map[string]int{"gpt-a": 20}
+		h.ReservedOutputTokens = 10
+	})
+
+	older := historyAt.Add(-time.Minute)
+	expectChatSettings(mock, "", nil, nil, nil, "")
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns).
+		AddRow("m2", "assistant", "two", 1, historyAt).
+		AddRow("m1", "user", "one", 1, older))
+	expectSaves(mock)
+
+	// m1 didn't fit, so everything up to it is summarized
+	mock.ExpectQuery(summarySourceQuery).
+		WithArgs("chat123", nil, older).
+		WillReturnRows(sqlmock.NewRows(summaryRowColumns).
+			AddRow("user", "one", 1, older))
+	mock.ExpectExec(upsertSummaryQuery).
+		WithArgs("chat123", "ok", older).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := sendHello(router)
+
+	assert.Contains(t, w.Body.String(), "event:done")
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	reqs := provider.Requests()
+	if assert.Len(t, reqs, 2) {
+		assert.Equal(t, summarizerPrompt, reqs[1].Messages[0].Content)
+		assert.Contains(t, reqs[1].Messages[1].Content, "user: one")
+	}
+}
+
+func TestRefreshSummary_NothingNew(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	provider := llm.NewFakeProvider()
+	h := &ChatHandler{DB: db, LLM: provider}
+
+	cc := chatContext{Summary: "old", SummaryUntil: sql.NullTime{Time: historyAt, Valid: true}}
+	mock.ExpectQuery(summarySourceQuery).
+		WithArgs("chat123", historyAt, historyAt).
+		WillReturnRows(sqlmock.NewRows(summaryRowColumns))
+
+	err = h.refreshSummary(context.Background(), "chat123", cc, historyAt)
+
+	assert.NoError(t, err)
+	assert.Empty(t, provider.Requests())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshSummary_ModelError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	h := &ChatHandler{DB: db, LLM: &llm.FakeProvider{Err: sql.ErrConnDone}}
+
+	mock.ExpectQuery(summarySourceQuery).
+		WillReturnRows(sqlmock.NewRows(summaryRowColumns).AddRow("user", "one", nil, historyAt))
+
+	err = h.refreshSummary(context.Background(), "chat123", chatContext{}, historyAt)
+
+	// Nothing is written when the model fails
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,106 @@
+package chat
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"personal-assistant-backend/internal/llm"
+)
+
+// defaultChatTitle marks chats the user hasn't named; only these get generated titles.
+const defaultChatTitle = "New Chat"
+
+// maxTitleLength matches the limit on user-provided titles.
+const maxTitleLength = 120
+
+// titleTimeout bounds title generation; titleWait is how long the SSE stream
+// stays open after done waiting for it before closing without a title event.
+const (
+	titleTimeout = 30 * time.Second
+	titleWait    = 5 * time.Second
+)
+
+const titlePrompt = "Write a short title (at most six words) for a conversation that starts with the exchange below. " +
+	"Reply with the title only, without quotes or trailing punctuation."
+
+// needsTitle reports whether this message is the first exchange in an untitled chat.
+func needsTitle(cc chatContext, builder *llm.ContextBuilder) bool {
+	return cc.Title == defaultChatTitle && cc.Summary == "" && builder.HistoryLen() == 0 && !builder.Overflowed()
+}
+
+// awaitTitle generates a title in the background and waits up to titleWait
+// for it, or until ctx ends. The chat is renamed even if the wait runs out.
+func (h *ChatHandler) awaitTitle(ctx context.Context, chatID string, cc chatContext, question, answer string) (string, bool) {
+	result := make(chan string, 1)
+	h.background(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), titleTimeout)
+		defer cancel()
+
+		title, err := h.generateTitle(ctx, chatID, cc, question, answer)
+		if err != nil {
+			log.Printf("⚠️ Failed to generate title for chat %s: %v\n", chatID, err)
+		}
+		result <- title
+	})
+
+	select {
+	case title := <-result:
+		return title, title != ""
+	case <-time.After(titleWait):
+		return "", false
+	case <-ctx.Done():
+		return "", false
+	}
+}
+
+// generateTitle asks the model for a title and stores it unless the user
+// renamed the chat in the meantime. It returns "" when nothing was stored.
+func (h *ChatHandler) generateTitle(ctx context.Context, chatID string, cc chatContext, question, answer string) (string, error) {
+	resp, err := h.LLM.Complete(ctx, llm.Request{
+		Model: h.model(cc.Settings),
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: titlePrompt},
+			{Role: llm.RoleUser, Content: "User: " + question + "\n\nAssistant: " + answer},
+		},
+		MaxTokens: 32,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	title := cleanTitle(resp.Content)
+	if title == "" {
+		return "", nil
+	}
+
+	result, err := h.DB.ExecContext(ctx, `
+		UPDATE chats SET title = $2
+		WHERE id = $1 AND title = $3
+	`, chatID, title, defaultChatTitle)
+	if err != nil {
+		return "", err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return "", err
+	}
+	return title, nil
+}
+
+// cleanTitle strips the quotes, punctuation and extra lines models like to add.
+func cleanTitle(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimPrefix(s, "Title:")
+	s = strings.Trim(s, " \t\"'“”‘’*.")
+	s = strings.Join(strings.Fields(s), " ")
+
+	if utf8.RuneCountInString(s) > maxTitleLength {
+		s = strings.TrimSpace(string([]rune(s)[:maxTitleLength]))
+	}
+	return s
+}
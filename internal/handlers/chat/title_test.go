@@ -0,0 +1,120 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"personal-assistant-backend/internal/llm"
+)
+
+const updateTitleQuery = `UPDATE chats SET title = \$2 WHERE id = \$1 AND title = \$3`
+
+// expectUntitledChat mocks the settings lookup for a chat still called "New Chat"
+func expectUntitledChat(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(chatContextQuery).
+		WithArgs("chat123", "user123").
+		WillReturnRows(sqlmock.NewRows(settingsRowColumns).
+			AddRow("", nil, nil, nil, "", "", nil, defaultChatTitle))
+}
+
+// --- TESTS ---
+
+func TestSendMessage_GeneratesTitle(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "Trip to Lisbon"}
+	router, mock := setupSendMessageRouter(t, provider, runNow)
+
+	expectUntitledChat(mock)
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns))
+	expectSaves(mock)
+	mock.ExpectExec(updateTitleQuery).
+		WithArgs("chat123", "Trip to Lisbon", defaultChatTitle).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := sendHello(router)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "event:title\ndata:Trip to Lisbon")
+	// The reply is complete before the title is ready
+	assert.Less(t, strings.Index(body, "event:done"), strings.Index(body, "event:title"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	reqs := provider.Requests()
+	if assert.Len(t, reqs, 2) {
+		assert.Equal(t, titlePrompt, reqs[1].Messages[0].Content)
+		assert.Equal(t, "User: Hello\n\nAssistant: Trip to Lisbon", reqs[1].Messages[1].Content)
+	}
+}
+
+func TestSendMessage_TitleRenamedMeanwhile(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "Trip to Lisbon"}
+	router, mock := setupSendMessageRouter(t, provider, runNow)
+
+	expectUntitledChat(mock)
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns))
+	expectSaves(mock)
+	mock.ExpectExec(updateTitleQuery).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := sendHello(router)
+
+	assert.NotContains(t, w.Body.String(), "event:title")
+	assert.Contains(t, w.Body.String(), "event:done")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendMessage_StopsWaitingForTitleWhenClientLeaves(t *testing.T) {
+	ctx, hangUp := context.WithCancel(context.Background())
+	defer hangUp()
+	// The client hangs up while the title is still being generated
+	router, mock := setupSendMessageRouter(t, &llm.FakeProvider{Reply: "Trip to Lisbon"}, func(h *ChatHandler) {
+		h.Background = func(func()) { hangUp() }
+	})
+
+	expectUntitledChat(mock)
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns))
+	expectSaves(mock)
+
+	start := time.Now()
+	w := sendHelloWithContext(router, ctx)
+
+	assert.Less(t, time.Since(start), titleWait)
+	assert.Contains(t, w.Body.String(), "event:done")
+	assert.NotContains(t, w.Body.String(), "event:title")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSendMessage_NoTitleAfterFirstExchange(t *testing.T) {
+	provider := &llm.FakeProvider{Reply: "ok"}
+	router, mock := setupSendMessageRouter(t, provider, runNow)
+
+	expectUntitledChat(mock)
+	expectHistory(mock, sqlmock.NewRows(historyRowColumns).
+		AddRow("m1", "user", "one", 1, historyAt))
+	expectSaves(mock)
+
+	w := sendHello(router)
+
+	assert.NotContains(t, w.Body.String(), "event:title")
+	assert.Len(t, provider.Requests(), 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCleanTitle(t *testing.T) {
+	cases := map[string]string{
+		"Trip to Lisbon":                "Trip to Lisbon",
+		`"Trip to Lisbon."`:             "Trip to Lisbon",
+		"Title: **Trip  to Lisbon**":    "Trip to Lisbon",
+		"Trip to Lisbon\nHope it helps": "Trip to Lisbon",
+		"  ":                            "",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, cleanTitle(in), in)
+	}
+
+	long := cleanTitle(strings.Repeat("word ", 40))
+	assert.LessOrEqual(t, len([]rune(long)), maxTitleLength)
+}
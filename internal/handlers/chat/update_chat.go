@@ -0,0 +1,121 @@
+package chat
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// UpdateChat godoc
+// @Summary Update a chat
+// @Description Renames, pins, archives or changes the settings of a chat owned by the logged-in user. Omitted fields keep their current value; an empty settings.persona_id detaches the persona and settings.reset lists generation settings (temperature, max_output_tokens, top_p) to return to the provider defaults.
+// @Tags Chats
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param chat_id path string true "Chat ID"
+// @Param payload body models.UpdateChatReq true "Fields to change"
+// @Success 200 {object} models.ChatUpdateResponse
+// @Failure 400 {object} map[string]string "Invalid payload, settings or persona"
+// @Failure 404 {object} map[string]string "Chat not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /chats/{chat_id} [patch]
+func (h *ChatHandler) UpdateChat(c *gin.Context) {
+	userID := c.GetString("userID")
+	chatID := c.Param("chat_id")
+
+	var req models.UpdateChatReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+			return
+		}
+		req.Title = &title
+	}
+
+	h.applyChatUpdate(c, chatID, userID, req)
+}
+
+// applyChatUpdate validates and stores the provided fields in one statement,
+// only touching chats the user owns, and responds with the updated chat.
+func (h *ChatHandler) applyChatUpdate(c *gin.Context, chatID, userID string, u models.UpdateChatReq) {
+	// Validate only the fields being changed
+	var req models.UpdateChatSettingsReq
+	if u.Settings != nil {
+		req = *u.Settings
+	}
+	check := models.ChatSettings{
+		Temperature:     req.Temperature,
+		MaxOutputTokens: req.MaxOutputTokens,
+		TopP:            req.TopP,
+	}
+	if req.Model != nil {
+		check.Model = *req.Model
+	}
+	if err := h.validateSettings(check); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid settings", "details": err.Error()})
+		return
+	}
+	resetTemperature := slices.Contains(req.Reset, "temperature")
+	resetMaxOutputTokens := slices.Contains(req.Reset, "max_output_tokens")
+	resetTopP := slices.Contains(req.Reset, "top_p")
+	if (resetTemperature && req.Temperature != nil) ||
+		(resetMaxOutputTokens && req.MaxOutputTokens != nil) ||
+		(resetTopP && req.TopP != nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid settings", "details": "a field can't be both set and reset"})
+		return
+	}
+
+	if req.PersonaID != nil && *req.PersonaID != "" {
+		if err := validatePersonaID(*req.PersonaID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid settings", "details": err.Error()})
+			return
+		}
+		owned, err := h.personaOwned(*req.PersonaID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error", "details": err.Error()})
+			return
+		}
+		if !owned {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "persona not found"})
+			return
+		}
+	}
+
+	var chat models.Chat
+	err := scanChat(h.DB.QueryRow(`
+		UPDATE chats
+		SET model = COALESCE($3, model),
+			temperature = CASE WHEN $11::boolean THEN NULL ELSE COALESCE($4, temperature) END,
+			max_output_tokens = CASE WHEN $12::boolean THEN NULL ELSE COALESCE($5, max_output_tokens) END,
+			top_p = CASE WHEN $13::boolean THEN NULL ELSE COALESCE($6, top_p) END,
+			persona_id = CASE WHEN $7::text IS NULL THEN persona_id ELSE NULLIF($7::text, '')::uuid END,
+			title = COALESCE($8, title),
+			pinned = COALESCE($9, pinned),
+			archived = COALESCE($10, archived)
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+chatColumns,
+		chatID, userID, req.Model, req.Temperature, req.MaxOutputTokens, req.TopP, req.PersonaID,
+		u.Title, u.Pinned, u.Archived, resetTemperature, resetMaxOutputTokens, resetTopP,
+	), &chat)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ChatUpdateResponse{Chat: chat})
+}
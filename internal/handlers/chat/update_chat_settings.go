@@ -0,0 +1,35 @@
+package chat
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// UpdateChatSettings godoc
+// @Summary Update a chat's generation settings
+// @Description Changes the model, temperature, max output tokens, top_p or persona for a chat owned by the logged-in user. Omitted fields keep their current value; an empty persona_id detaches the persona and reset lists generation settings (temperature, max_output_tokens, top_p) to return to the provider defaults.
+// @Tags Chats
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param chat_id path string true "Chat ID"
+// @Param payload body models.UpdateChatSettingsReq true "Settings to change"
+// @Success 200 {object} models.ChatUpdateResponse
+// @Failure 400 {object} map[string]string "Invalid payload, settings or persona"
+// @Failure 404 {object} map[string]string "Chat not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /chats/{chat_id}/settings [patch]
+func (h *ChatHandler) UpdateChatSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+	chatID := c.Param("chat_id")
+
+	var req models.UpdateChatSettingsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	h.applyChatUpdate(c, chatID, userID, models.UpdateChatReq{Settings: &req})
+}
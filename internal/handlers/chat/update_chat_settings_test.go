@@ -0,0 +1,173 @@
+package chat
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"personal-assistant-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupUpdateSettingsRouter sets up Gin + sqlmock for UpdateChatSettings
+func setupUpdateSettingsRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	h := &ChatHandler{DB: db, AllowedModels: []string{"gpt-a", "gpt-b"}}
+	r := gin.Default()
+
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+
+	r.PATCH("/chats/:chat_id/settings", h.UpdateChatSettings)
+	return r, mock
+}
+
+// --- TESTS ---
+
+func TestUpdateChatSettings_Success(t *testing.T) {
+	router, mock := setupUpdateSettingsRouter(t)
+
+	now := time.Now()
+	model := "gpt-b"
+	mock.ExpectQuery(`UPDATE chats SET model = COALESCE\(\$3, model\)`).
+		WithArgs("chat-123", "user123", &model, nil, nil, nil, nil, nil, nil, nil, false, false, false).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat-123", "My Chat", now, "gpt-b", 0.5, nil, nil, nil, false, false, now))
+
+	req, _ := http.NewRequest("PATCH", "/chats/chat-123/settings", strings.NewReader(`{"model":"gpt-b"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.ChatUpdateResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "gpt-b", resp.Chat.Settings.Model)
+	assert.InDelta(t, 0.5, *resp.Chat.Settings.Temperature, 0.0001)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateChatSettings_NotFound(t *testing.T) {
+	router, mock := setupUpdateSettingsRouter(t)
+
+	mock.ExpectQuery(`UPDATE chats`).WillReturnError(sql.ErrNoRows)
+
+	req, _ := http.NewRequest("PATCH", "/chats/missing/settings", strings.NewReader(`{"top_p":0.9}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "chat not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateChatSettings_InvalidSettings(t *testing.T) {
+	router, mock := setupUpdateSettingsRouter(t)
+
+	for _, body := range []string{
+		`{"model":"gpt-unknown"}`,
+		`{"top_p":0}`,
+		`{"max_output_tokens":-1}`,
+	} {
+		req, _ := http.NewRequest("PATCH", "/chats/chat-123/settings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), "invalid settings", body)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateChatSettings_InvalidPayload(t *testing.T) {
+	router, _ := setupUpdateSettingsRouter(t)
+
+	req, _ := http.NewRequest("PATCH", "/chats/chat-123/settings", strings.NewReader(`{invalid}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid payload")
+}
+
+func TestUpdateChatSettings_DetachPersona(t *testing.T) {
+	router, mock := setupUpdateSettingsRouter(t)
+
+	now := time.Now()
+	empty := ""
+	mock.ExpectQuery(`UPDATE chats SET .* persona_id = CASE WHEN \$7::text IS NULL`).
+		WithArgs("chat-123", "user123", nil, nil, nil, nil, &empty, nil, nil, nil, false, false, false).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat-123", "My Chat", now, "", nil, nil, nil, nil, false, false, now))
+
+	req, _ := http.NewRequest("PATCH", "/chats/chat-123/settings", strings.NewReader(`{"persona_id":""}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.ChatUpdateResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Nil(t, resp.Chat.PersonaID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateChatSettings_ResetToDefaults(t *testing.T) {
+	router, mock := setupUpdateSettingsRouter(t)
+
+	now := time.Now()
+	mock.ExpectQuery(`UPDATE chats SET .* temperature = CASE WHEN \$11::boolean THEN NULL ELSE COALESCE\(\$4, temperature\) END, max_output_tokens = CASE WHEN \$12::boolean THEN NULL ELSE COALESCE\(\$5, max_output_tokens\) END, top_p = CASE WHEN \$13::boolean THEN NULL ELSE COALESCE\(\$6, top_p\) END`).
+		WithArgs("chat-123", "user123", nil, nil, nil, nil, nil, nil, nil, nil, true, false, true).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat-123", "My Chat", now, "gpt-b", nil, 256, nil, nil, false, false, now))
+
+	req, _ := http.NewRequest("PATCH", "/chats/chat-123/settings", strings.NewReader(`{"reset":["temperature","top_p"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.ChatUpdateResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Nil(t, resp.Chat.Settings.Temperature)
+	assert.Nil(t, resp.Chat.Settings.TopP)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateChatSettings_InvalidReset(t *testing.T) {
+	router, mock := setupUpdateSettingsRouter(t)
+
+	for _, body := range []string{
+		`{"reset":["model"]}`,
+		`{"temperature":0.5,"reset":["temperature"]}`,
+	} {
+		req, _ := http.NewRequest("PATCH", "/chats/chat-123/settings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,125 @@
+package chat
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"personal-assistant-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupUpdateChatRouter sets up Gin + sqlmock for UpdateChat
+func setupUpdateChatRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	h := &ChatHandler{DB: db, AllowedModels: []string{"gpt-a", "gpt-b"}}
+	r := gin.Default()
+
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+
+	r.PATCH("/chats/:chat_id", h.UpdateChat)
+	return r, mock
+}
+
+func patchChat(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("PATCH", "/chats/chat-123", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// --- TESTS ---
+
+func TestUpdateChat_RenameAndPin(t *testing.T) {
+	router, mock := setupUpdateChatRouter(t)
+
+	now := time.Now()
+	title, pinned := "Trip to Lisbon", true
+	mock.ExpectQuery(`UPDATE chats SET .* title = COALESCE\(\$8, title\), pinned = COALESCE\(\$9, pinned\), archived = COALESCE\(\$10, archived\) WHERE id = \$1 AND user_id = \$2`).
+		WithArgs("chat-123", "user123", nil, nil, nil, nil, nil, &title, &pinned, nil, false, false, false).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat-123", "Trip to Lisbon", now.Add(-time.Hour), "", nil, nil, nil, nil, true, false, now))
+
+	w := patchChat(router, `{"title":"  Trip to Lisbon ","pinned":true}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.ChatUpdateResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Trip to Lisbon", resp.Chat.Title)
+	assert.True(t, resp.Chat.Pinned)
+	assert.False(t, resp.Chat.Archived)
+	assert.NotEmpty(t, resp.Chat.UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateChat_ArchiveWithSettings(t *testing.T) {
+	router, mock := setupUpdateChatRouter(t)
+
+	now := time.Now()
+	model, archived := "gpt-b", true
+	mock.ExpectQuery(`UPDATE chats`).
+		WithArgs("chat-123", "user123", &model, nil, nil, nil, nil, nil, nil, &archived, false, false, false).
+		WillReturnRows(sqlmock.NewRows(chatRowColumns).
+			AddRow("chat-123", "My Chat", now, "gpt-b", nil, nil, nil, nil, false, true, now))
+
+	w := patchChat(router, `{"archived":true,"settings":{"model":"gpt-b"}}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"archived":true`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateChat_InvalidSettings(t *testing.T) {
+	router, mock := setupUpdateChatRouter(t)
+
+	for _, body := range []string{
+		`{"settings":{"model":"gpt-unknown"}}`,
+		`{"settings":{"persona_id":"abc"}}`,
+	} {
+		w := patchChat(router, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), "invalid settings", body)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateChat_InvalidTitle(t *testing.T) {
+	router, _ := setupUpdateChatRouter(t)
+
+	for _, body := range []string{`{"title":""}`, `{"title":"   "}`, `{"title":"` + strings.Repeat("x", 121) + `"}`} {
+		w := patchChat(router, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), "invalid payload")
+	}
+}
+
+func TestUpdateChat_NotFound(t *testing.T) {
+	router, mock := setupUpdateChatRouter(t)
+
+	mock.ExpectQuery(`UPDATE chats`).WillReturnError(sql.ErrNoRows)
+
+	w := patchChat(router, `{"pinned":false}`)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "chat not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/accountdata"
+	"personal-assistant-backend/internal/models"
+)
+
+// RequestExport godoc
+// @Summary Export my data
+// @Description Starts building a zip archive of everything stored about the user: export.json with the profile, linked accounts, sessions, personas and chats, plus a Markdown transcript per chat. The archive is built in the background; poll GET /me/export/{export_id} until it is ready. While one export is in progress, asking again returns it instead of starting another. Archives can be downloaded for 7 days.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce  json
+// @Success 202 {object} models.DataExportResponse "Export queued or already in progress"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /me/export [post]
+func (h *AuthHandler) RequestExport(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// ✅ Reuse an export that hasn't finished yet. The partial unique index
+	// turns a concurrent insert into a no-op, so read back the winner's row.
+	var export models.DataExport
+	err := h.db.QueryRow(`
+		INSERT INTO data_exports (user_id) VALUES ($1)
+		ON CONFLICT (user_id) WHERE status IN ('pending', 'running') DO NOTHING
+		RETURNING id, status, created_at
+	`, userID).Scan(&export.ID, &export.Status, &export.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = h.db.QueryRow(`
+			SELECT id, status, created_at FROM data_exports
+			WHERE user_id = $1 AND status IN ('pending', 'running')
+		`, userID).Scan(&export.ID, &export.Status, &export.CreatedAt)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.DataExportResponse{Export: export})
+}
+
+// GetExport godoc
+// @Summary Get data export status
+// @Description Returns an export's status; download_url is set once it is ready.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce  json
+// @Param export_id path string true "Export ID"
+// @Success 200 {object} models.DataExportResponse "Export"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Export not found or expired"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /me/export/{export_id} [get]
+func (h *AuthHandler) GetExport(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var export models.DataExport
+	err := h.db.QueryRow(`
+		SELECT id, status, created_at, completed_at, expires_at FROM data_exports
+		WHERE id::text = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > now())
+	`, c.Param("export_id"), userID).Scan(
+		&export.ID, &export.Status, &export.CreatedAt, &export.CompletedAt, &export.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "export not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if export.Status == accountdata.StatusReady {
+		export.DownloadURL = "/me/export/" + export.ID + "/download"
+	}
+
+	c.JSON(http.StatusOK, models.DataExportResponse{Export: export})
+}
+
+// DownloadExport godoc
+// @Summary Download data export
+// @Description Downloads a finished export as a zip archive.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce  application/zip
+// @Param export_id path string true "Export ID"
+// @Success 200 {file} file "Zip archive"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Export not found or expired"
+// @Failure 409 {object} map[string]string "Export not ready"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /me/export/{export_id}/download [get]
+func (h *AuthHandler) DownloadExport(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var status string
+	var archive []byte
+	var completedAt *time.Time
+	err := h.db.QueryRow(`
+		SELECT status, archive, completed_at FROM data_exports
+		WHERE id::text = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > now())
+	`, c.Param("export_id"), userID).Scan(&status, &archive, &completedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "export not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if status != accountdata.StatusReady || completedAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "export not ready", "status": status})
+		return
+	}
+
+	filename := fmt.Sprintf("personal-assistant-export-%s.zip", completedAt.UTC().Format("2006-01-02"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", archive)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"personal-assistant-backend/internal/models"
+)
+
+const (
+	insertExportQuery   = `INSERT INTO data_exports \(user_id\) VALUES \(\$1\) ON CONFLICT \(user_id\) WHERE status IN \('pending', 'running'\) DO NOTHING RETURNING id, status, created_at`
+	activeExportQuery   = `SELECT id, status, created_at FROM data_exports WHERE user_id = \$1 AND status IN \('pending', 'running'\)`
+	getExportQuery      = `SELECT id, status, created_at, completed_at, expires_at FROM data_exports WHERE id::text = \$1 AND user_id = \$2 AND \(expires_at IS NULL OR expires_at > now\(\)\)`
+	downloadExportQuery = `SELECT status, archive, completed_at FROM data_exports WHERE id::text = \$1 AND user_id = \$2 AND \(expires_at IS NULL OR expires_at > now\(\)\)`
+)
+
+// --- TESTS ---
+
+func TestRequestExport_Queues(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	mock.ExpectQuery(insertExportQuery).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).AddRow("export-1", "pending", time.Now()))
+
+	w := serve(router, "POST", "/me/export")
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var resp models.DataExportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "export-1", resp.Export.ID)
+	assert.Equal(t, "pending", resp.Export.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequestExport_ReusesActive(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	// The insert conflicts with the export already in flight
+	mock.ExpectQuery(insertExportQuery).WithArgs("user123").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(activeExportQuery).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).AddRow("export-1", "running", time.Now()))
+
+	w := serve(router, "POST", "/me/export")
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"running"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetExport(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+	now := time.Now()
+
+	mock.ExpectQuery(getExportQuery).
+		WithArgs("export-1", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at", "completed_at", "expires_at"}).
+			AddRow("export-1", "pending", now, nil, nil))
+	mock.ExpectQuery(getExportQuery).
+		WithArgs("export-1", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at", "completed_at", "expires_at"}).
+			AddRow("export-1", "ready", now, now, now.Add(7*24*time.Hour)))
+	mock.ExpectQuery(getExportQuery).
+		WithArgs("someone-elses", "user123").
+		WillReturnError(sql.ErrNoRows)
+
+	w := serve(router, "GET", "/me/export/export-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "download_url")
+
+	w = serve(router, "GET", "/me/export/export-1")
+	var resp models.DataExportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "/me/export/export-1/download", resp.Export.DownloadURL)
+	assert.NotNil(t, resp.Export.ExpiresAt)
+
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/me/export/someone-elses").Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDownloadExport(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+	completed := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(downloadExportQuery).
+		WithArgs("export-1", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "archive", "completed_at"}).
+			AddRow("ready", []byte("PK\x03\x04zip"), completed))
+
+	w := serve(router, "GET", "/me/export/export-1/download")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="personal-assistant-export-2026-10-16.zip"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "PK\x03\x04zip", w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDownloadExport_NotReady(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	mock.ExpectQuery(downloadExportQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "archive", "completed_at"}).AddRow("running", nil, nil))
+	mock.ExpectQuery(downloadExportQuery).WillReturnError(sql.ErrNoRows)
+
+	assert.Equal(t, http.StatusConflict, serve(router, "GET", "/me/export/export-1/download").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/me/export/export-2/download").Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
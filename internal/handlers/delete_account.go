@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/mail"
+	"personal-assistant-backend/internal/models"
+)
+
+// DeleteMe godoc
+// @Summary Delete account
+// @Description Closes the account after checking the password and signs it out everywhere. The account, chats, messages and everything else it owns are permanently deleted after a grace period (ACCOUNT_DELETION_GRACE_DAYS, 30 by default); signing in again and calling DELETE /me/deletion before then keeps it. Accounts created through social login set a password via /password/forgot first.
+// @Tags Auth
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param payload body deleteAccountReq true "Account password"
+// @Success 202 {object} models.AccountDeletionResponse "Deletion scheduled"
+// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Password is incorrect"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /me [delete]
+func (h *AuthHandler) DeleteMe(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req deleteAccountReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer tx.Rollback()
+
+	var hash, email, firstName string
+	err = tx.QueryRow(`
+		SELECT password_hash, email, first_name FROM users WHERE id = $1 FOR UPDATE
+	`, userID).Scan(&hash, &email, &firstName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if ok, _ := h.hasher().Verify(hash, req.Password); !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "password is incorrect"})
+		return
+	}
+
+	// ✅ Asking twice keeps the original date
+	var deleteAt time.Time
+	err = tx.QueryRow(`
+		UPDATE users SET deletion_scheduled_at = COALESCE(deletion_scheduled_at, now() + make_interval(secs => $2))
+		WHERE id = $1
+		RETURNING deletion_scheduled_at
+	`, userID, getAccountDeletionGrace().Seconds()).Scan(&deleteAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	// ✅ Closing the account signs out every device, this one included
+	if _, err := revokeSessions(tx, userID, "", ""); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	// A heads-up in case someone else closed the account
+	if h.mailer != nil {
+		msg := mail.Message{
+			To:      email,
+			Subject: "Your account will be deleted",
+			Body: fmt.Sprintf(
+				"Hi %s,\n\nYour account and all of your chats will be permanently deleted on %s.\n\nTo keep your account, sign in before then and cancel the deletion.\n",
+				firstName, deleteAt.UTC().Format("January 2, 2006")),
+		}
+		if err := h.mailer.Send(c.Request.Context(), msg); err != nil {
+			log.Printf("❌ Failed to send deletion notice to user %s: %v\n", userID, err)
+		}
+	}
+
+	c.JSON(http.StatusAccepted, models.AccountDeletionResponse{
+		Message:             "Account scheduled for deletion",
+		DeletionScheduledAt: deleteAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// CancelDeletion godoc
+// @Summary Cancel account deletion
+// @Description Keeps an account that was closed with DELETE /me, as long as its grace period hasn't run out.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce  json
+// @Success 200 {object} map[string]string "Deletion cancelled"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "No deletion scheduled"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /me/deletion [delete]
+func (h *AuthHandler) CancelDeletion(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := h.db.Exec(`
+		UPDATE users SET deletion_scheduled_at = NULL
+		WHERE id = $1 AND deletion_scheduled_at IS NOT NULL
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no deletion scheduled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deletion cancelled"})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"personal-assistant-backend/internal/models"
+)
+
+const (
+	lockAccountQuery     = `SELECT password_hash, email, first_name FROM users WHERE id = \$1 FOR UPDATE`
+	scheduleDeleteQuery  = `UPDATE users SET deletion_scheduled_at = COALESCE\(deletion_scheduled_at, now\(\) \+ make_interval\(secs => \$2\)\) WHERE id = \$1 RETURNING deletion_scheduled_at`
+	cancelDeletionQuery  = `UPDATE users SET deletion_scheduled_at = NULL WHERE id = \$1 AND deletion_scheduled_at IS NOT NULL`
+	thirtyDaysInSeconds  = float64(30 * 24 * 60 * 60)
+	deleteAccountPayload = `{"password":"supersecret"}`
+)
+
+// --- TESTS ---
+
+func TestDeleteMe_SchedulesDeletion(t *testing.T) {
+	router, mock, mailer := setupMeRouter(t)
+	deleteAt := time.Date(2026, 11, 15, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockAccountQuery).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"password_hash", "email", "first_name"}).
+			AddRow(testHash("supersecret"), "jane@example.com", "Jane"))
+	mock.ExpectQuery(scheduleDeleteQuery).
+		WithArgs("user123", thirtyDaysInSeconds).
+		WillReturnRows(sqlmock.NewRows([]string{"deletion_scheduled_at"}).AddRow(deleteAt))
+	mock.ExpectExec(revokeSessionsQuery).
+		WithArgs("user123", "", "").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	w := serveJSON(router, "DELETE", "/me", deleteAccountPayload)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var resp models.AccountDeletionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "2026-11-15T12:00:00Z", resp.DeletionScheduledAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	sent := mailer.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "jane@example.com", sent[0].To)
+	assert.Contains(t, sent[0].Body, "November 15, 2026")
+}
+
+func TestDeleteMe_GraceFromEnv(t *testing.T) {
+	t.Setenv("ACCOUNT_DELETION_GRACE_DAYS", "7")
+	router, mock, _ := setupMeRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockAccountQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"password_hash", "email", "first_name"}).
+			AddRow(testHash("supersecret"), "jane@example.com", "Jane"))
+	mock.ExpectQuery(scheduleDeleteQuery).
+		WithArgs("user123", float64(7*24*60*60)).
+		WillReturnRows(sqlmock.NewRows([]string{"deletion_scheduled_at"}).AddRow(time.Now()))
+	mock.ExpectExec(revokeSessionsQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	w := serveJSON(router, "DELETE", "/me", deleteAccountPayload)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMe_WrongPassword(t *testing.T) {
+	router, mock, mailer := setupMeRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockAccountQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"password_hash", "email", "first_name"}).
+			AddRow(testHash("something-else"), "jane@example.com", "Jane"))
+	mock.ExpectRollback()
+
+	w := serveJSON(router, "DELETE", "/me", deleteAccountPayload)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, mailer.Sent())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMe_PasswordlessAccount(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockAccountQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"password_hash", "email", "first_name"}).
+			AddRow("", "jane@example.com", "Jane"))
+	mock.ExpectRollback()
+
+	w := serveJSON(router, "DELETE", "/me", deleteAccountPayload)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMe_MissingPassword(t *testing.T) {
+	router, _, _ := setupMeRouter(t)
+
+	w := serveJSON(router, "DELETE", "/me", `{}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCancelDeletion(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	mock.ExpectExec(cancelDeletionQuery).
+		WithArgs("user123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(cancelDeletionQuery).
+		WithArgs("user123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.Equal(t, http.StatusOK, serve(router, "DELETE", "/me/deletion").Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, "DELETE", "/me/deletion").Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/mail"
+)
+
+// sendVerificationEmail mints a signed verification token for the address
+// and mails it to the user.
+func (h *AuthHandler) sendVerificationEmail(ctx context.Context, userID, email, firstName string) error {
+	ttl := getEmailVerifyTTL()
+	token, err := h.generateJWT(tokenSpec{
+		UserID: userID, Email: email, Type: TokenTypeEmailVerify, TTL: ttl,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.mailer.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address using the link below. It expires in %d hours.\n\n%s\n",
+			firstName, int(ttl.Hours()), tokenLink("EMAIL_VERIFY_URL", token)),
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Marks the account's email as verified using the signed token from the verification email. The token is read from the `token` query parameter (GET, for email links) or the JSON body (POST). Tokens issued for a previous email address are rejected.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param token query string false "Verification token (GET)"
+// @Param payload body verifyEmailReq false "Verification token (POST)"
+// @Success 200 {object} map[string]interface{} "Email verified"
+// @Failure 400 {object} map[string]string "Missing, invalid or expired token"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /email/verify [get]
+// @Router /email/verify [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if c.Request.Method == http.MethodPost {
+		var req verifyEmailReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+			return
+		}
+		token = req.Token
+	}
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	claims, err := h.parseJWT(token, TokenTypeEmailVerify)
+	if err != nil || claims.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	// ✅ Only verifies the address the token was sent to; re-verifying is a no-op
+	var verifiedAt time.Time
+	err = h.db.QueryRow(`
+		UPDATE users SET email_verified_at = COALESCE(email_verified_at, now())
+		WHERE id = $1 AND email = $2
+		RETURNING email_verified_at
+	`, claims.UserID, claims.Email).Scan(&verifiedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Email verified",
+		"email_verified_at": verifiedAt.Format(time.RFC3339),
+	})
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Sends a fresh verification email to the authenticated user. Throttled per account (EMAIL_VERIFY_RESEND_SECONDS, default 60).
+// @Tags Auth
+// @Security BearerAuth
+// @Produce  json
+// @Success 202 {object} map[string]string "Verification email sent"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 409 {object} map[string]string "Email already verified"
+// @Failure 429 {object} map[string]string "Sent too recently; see Retry-After"
+// @Failure 500 {object} map[string]string "Database or mail error"
+// @Router /email/verify/resend [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var email, firstName string
+	var verified bool
+	var sentAt sql.NullTime
+	err := h.db.QueryRow(`
+		SELECT email, first_name, email_verified_at IS NOT NULL, email_verification_sent_at
+		FROM users WHERE id = $1
+	`, userID).Scan(&email, &firstName, &verified, &sentAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if verified {
+		c.JSON(http.StatusConflict, gin.H{"error": "email already verified"})
+		return
+	}
+
+	interval := getEmailVerifyResendInterval()
+	if sentAt.Valid {
+		if wait := time.Until(sentAt.Time.Add(interval)); wait > 0 {
+			tooSoon(c, wait)
+			return
+		}
+	}
+
+	// ✅ Claim the send slot atomically so concurrent resends mail only once
+	result, err := h.db.Exec(`
+		UPDATE users SET email_verification_sent_at = now()
+		WHERE id = $1 AND email_verified_at IS NULL
+			AND (email_verification_sent_at IS NULL OR email_verification_sent_at <= now() - make_interval(secs => $2))
+	`, userID, interval.Seconds())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		tooSoon(c, interval)
+		return
+	}
+
+	if err := h.sendVerificationEmail(c.Request.Context(), userID, email, firstName); err != nil {
+		log.Printf("❌ Failed to send verification email to user %s: %v\n", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// tooSoon answers 429 with a Retry-After header rounded up to whole seconds.
+func tooSoon(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+}
+
+// EmailVerified reports whether the user has verified their email.
+// RequireVerifiedEmail uses it to gate chat routes.
+func EmailVerified(db *sql.DB, userID string) (bool, error) {
+	var verified bool
+	err := db.QueryRow(`SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1`, userID).Scan(&verified)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return verified, err
+}
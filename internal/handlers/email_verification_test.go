@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"personal-assistant-backend/internal/mail"
+)
+
+// mockParseEmailToken accepts "good-token" as a verification token for jane@example.com
+func mockParseEmailToken(token, tokenType string) (*Claims, error) {
+	if token != "good-token" || tokenType != TokenTypeEmailVerify {
+		return nil, errors.New("invalid token")
+	}
+	return &Claims{UserID: "user123", TokenType: tokenType, Email: "jane@example.com"}, nil
+}
+
+// setupEmailVerifyRouter mounts the verification endpoints with mocked tokens and mail
+func setupEmailVerifyRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock, *mail.MemorySender) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	mailer := mail.NewMemorySender()
+	h := &AuthHandler{
+		db:          db,
+		generateJWT: mockGenerateJWT,
+		parseJWT:    mockParseEmailToken,
+		mailer:      mailer,
+	}
+
+	r := gin.Default()
+	r.GET("/email/verify", h.VerifyEmail)
+	r.POST("/email/verify", h.VerifyEmail)
+
+	authed := r.Group("/", func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+	authed.POST("/email/verify/resend", h.ResendVerification)
+	return r, mock, mailer
+}
+
+const markVerifiedQuery = `UPDATE users SET email_verified_at = COALESCE\(email_verified_at, now\(\)\) WHERE id = \$1 AND email = \$2 RETURNING email_verified_at`
+
+var resendColumns = []string{"email", "first_name", "verified", "email_verification_sent_at"}
+
+// --- TESTS ---
+
+func TestVerifyEmail_GetLink(t *testing.T) {
+	router, mock, _ := setupEmailVerifyRouter(t)
+
+	mock.ExpectQuery(markVerifiedQuery).
+		WithArgs("user123", "jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"email_verified_at"}).AddRow(time.Now()))
+
+	w := serve(router, "GET", "/email/verify?token=good-token")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Email verified")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyEmail_PostBody(t *testing.T) {
+	router, mock, _ := setupEmailVerifyRouter(t)
+
+	mock.ExpectQuery(markVerifiedQuery).
+		WithArgs("user123", "jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"email_verified_at"}).AddRow(time.Now()))
+
+	w := postJSON(router, "/email/verify", `{"token":"good-token"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyEmail_InvalidToken(t *testing.T) {
+	router, mock, _ := setupEmailVerifyRouter(t)
+
+	w := serve(router, "GET", "/email/verify?token=forged")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid or expired token")
+
+	w = serve(router, "GET", "/email/verify")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "token is required")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyEmail_EmailChanged(t *testing.T) {
+	router, mock, _ := setupEmailVerifyRouter(t)
+
+	// The token was issued for an address the account no longer has
+	mock.ExpectQuery(markVerifiedQuery).WillReturnError(sql.ErrNoRows)
+
+	w := serve(router, "GET", "/email/verify?token=good-token")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid or expired token")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResendVerification_Sends(t *testing.T) {
+	router, mock, mailer := setupEmailVerifyRouter(t)
+
+	mock.ExpectQuery(`SELECT email, first_name, email_verified_at IS NOT NULL, email_verification_sent_at FROM users WHERE id = \$1`).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows(resendColumns).AddRow("jane@example.com", "Jane", false, time.Now().Add(-time.Hour)))
+	mock.ExpectExec(`UPDATE users SET email_verification_sent_at = now\(\)`).
+		WithArgs("user123", float64(60)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := serve(router, "POST", "/email/verify/resend")
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	sent := mailer.Sent()
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, "jane@example.com", sent[0].To)
+		assert.Contains(t, sent[0].Body, "mock-email_verify-user123")
+	}
+}
+
+func TestResendVerification_Throttled(t *testing.T) {
+	router, mock, mailer := setupEmailVerifyRouter(t)
+
+	mock.ExpectQuery(`SELECT email, first_name`).
+		WillReturnRows(sqlmock.NewRows(resendColumns).AddRow("jane@example.com", "Jane", false, time.Now().Add(-20*time.Second)))
+
+	w := serve(router, "POST", "/email/verify/resend")
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "40", w.Header().Get("Retry-After"))
+	assert.Empty(t, mailer.Sent())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResendVerification_LostRace(t *testing.T) {
+	router, mock, mailer := setupEmailVerifyRouter(t)
+
+	mock.ExpectQuery(`SELECT email, first_name`).
+		WillReturnRows(sqlmock.NewRows(resendColumns).AddRow("jane@example.com", "Jane", false, nil))
+	mock.ExpectExec(`UPDATE users SET email_verification_sent_at`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := serve(router, "POST", "/email/verify/resend")
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Empty(t, mailer.Sent())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResendVerification_AlreadyVerified(t *testing.T) {
+	router, mock, _ := setupEmailVerifyRouter(t)
+
+	mock.ExpectQuery(`SELECT email, first_name`).
+		WillReturnRows(sqlmock.NewRows(resendColumns).AddRow("jane@example.com", "Jane", true, nil))
+
+	w := serve(router, "POST", "/email/verify/resend")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "email already verified")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResendVerification_MailError(t *testing.T) {
+	router, mock, mailer := setupEmailVerifyRouter(t)
+	mailer.Err = errors.New("smtp down")
+
+	mock.ExpectQuery(`SELECT email, first_name`).
+		WillReturnRows(sqlmock.NewRows(resendColumns).AddRow("jane@example.com", "Jane", false, nil))
+	mock.ExpectExec(`UPDATE users SET email_verification_sent_at`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := serve(router, "POST", "/email/verify/resend")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), "failed to send email"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,52 +1,131 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"personal-assistant-backend/internal/jwtkeys"
 )
 
-// Generate a JWT token for given userID and TTL
-func generateJWT(userID string, ttl time.Duration) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", errors.New("JWT_SECRET not set")
+// Token types carried in the "typ" claim. Each is only accepted where it belongs:
+// access tokens as bearer tokens, refresh tokens at /token/refresh, email
+// verification tokens at /email/verify and MFA challenges at /login/mfa.
+const (
+	TokenTypeAccess      = "access"
+	TokenTypeRefresh     = "refresh"
+	TokenTypeEmailVerify = "email_verify"
+	TokenTypeMFA         = "mfa"
+)
+
+// Default "iss" and "aud" claims, overridable with JWT_ISSUER and JWT_AUDIENCE
+const (
+	defaultJWTIssuer   = "personal-assistant-backend"
+	defaultJWTAudience = "personal-assistant-api"
+)
+
+// tokenSpec describes a token to mint.
+type tokenSpec struct {
+	UserID    string
+	SessionID string // session family the token belongs to
+	Type      string // TokenTypeAccess or TokenTypeRefresh
+	ID        string // jti; a random one is generated when empty
+	Email     string // address an email verification token vouches for
+	TTL       time.Duration
+}
+
+// Generate a JWT token from spec, signed with the active key
+func generateJWT(spec tokenSpec) (string, error) {
+	keys, err := jwtkeys.Current()
+	if err != nil {
+		return "", err
 	}
 
+	jti := spec.ID
+	if jti == "" {
+		if jti, err = newTokenID(); err != nil {
+			return "", err
+		}
+	}
+
+	now := time.Now()
 	claims := Claims{
-		UserID: userID,
+		UserID:    spec.UserID,
+		TokenType: spec.Type,
+		SessionID: spec.SessionID,
+		Email:     spec.Email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   userID,
+			Issuer:    jwtIssuer(),
+			Audience:  jwt.ClaimStrings{jwtAudience()},
+			ExpiresAt: jwt.NewNumericDate(now.Add(spec.TTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   spec.UserID,
+			ID:        jti,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return keys.Sign(claims)
 }
 
-// Parse and validate a JWT token
-func parseJWT(tokenStr string) (*Claims, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return nil, errors.New("JWT_SECRET not set")
+// Parse and validate a JWT token, requiring the given token type
+func parseJWT(tokenStr, tokenType string) (*Claims, error) {
+	keys, err := jwtkeys.Current()
+	if err != nil {
+		return nil, err
 	}
 
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+	token, err := keys.Parse(tokenStr, &Claims{},
+		jwt.WithIssuer(jwtIssuer()),
+		jwt.WithAudience(jwtAudience()),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.TokenType != tokenType {
+		return nil, errors.New("wrong token type")
+	}
+	if claims.ID == "" {
+		return nil, errors.New("token has no jti")
 	}
-	return nil, errors.New("invalid token")
+	return claims, nil
+}
+
+// ParseAccessToken validates a bearer token for the JWT middleware.
+func ParseAccessToken(tokenStr string) (*Claims, error) {
+	return parseJWT(tokenStr, TokenTypeAccess)
+}
+
+// newTokenID returns a random "jti" claim.
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func jwtIssuer() string {
+	if iss := os.Getenv("JWT_ISSUER"); iss != "" {
+		return iss
+	}
+	return defaultJWTIssuer
+}
+
+func jwtAudience() string {
+	if aud := os.Getenv("JWT_AUDIENCE"); aud != "" {
+		return aud
+	}
+	return defaultJWTAudience
 }
 
 // Access token TTL in minutes
@@ -74,3 +153,39 @@ func getRefreshTTL() time.Duration {
 	}
 	return time.Duration(days) * 24 * time.Hour
 }
+
+// Password reset token TTL in minutes
+func getPasswordResetTTL() time.Duration {
+	n, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TTL_MINUTES"))
+	if err != nil || n <= 0 {
+		return time.Hour
+	}
+	return time.Duration(n) * time.Minute
+}
+
+// Email verification token TTL in hours
+func getEmailVerifyTTL() time.Duration {
+	n, err := strconv.Atoi(os.Getenv("EMAIL_VERIFY_TTL_HOURS"))
+	if err != nil || n <= 0 {
+		return 48 * time.Hour
+	}
+	return time.Duration(n) * time.Hour
+}
+
+// Minimum wait between verification emails, in seconds
+func getEmailVerifyResendInterval() time.Duration {
+	n, err := strconv.Atoi(os.Getenv("EMAIL_VERIFY_RESEND_SECONDS"))
+	if err != nil || n <= 0 {
+		return time.Minute
+	}
+	return time.Duration(n) * time.Second
+}
+
+// Grace period before a closed account is hard deleted, in days
+func getAccountDeletionGrace() time.Duration {
+	n, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_DAYS"))
+	if err != nil || n <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(n) * 24 * time.Hour
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"personal-assistant-backend/internal/jwtkeys"
 )
 
 // --- generateJWT tests ---
@@ -15,7 +16,7 @@ func TestGenerateJWT_Success(t *testing.T) {
 	os.Setenv("JWT_SECRET", "testsecret")
 	defer os.Unsetenv("JWT_SECRET")
 
-	token, err := generateJWT("user123", time.Minute*10)
+	token, err := generateJWT(tokenSpec{UserID: "user123", Type: TokenTypeAccess, TTL: time.Minute * 10})
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -30,12 +31,52 @@ func TestGenerateJWT_Success(t *testing.T) {
 func TestGenerateJWT_MissingSecret(t *testing.T) {
 	os.Unsetenv("JWT_SECRET")
 
-	token, err := generateJWT("user123", time.Minute*10)
+	token, err := generateJWT(tokenSpec{UserID: "user123", Type: TokenTypeAccess, TTL: time.Minute * 10})
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "JWT_SECRET not set")
+	assert.ErrorIs(t, err, jwtkeys.ErrNoSigningKey)
 	assert.Empty(t, token)
 }
 
+func TestGenerateJWT_SigningKey(t *testing.T) {
+	t.Setenv("JWT_SIGNING_KEY", testSigningKeyPEM(t))
+	t.Setenv("JWT_SECRET", "legacySecret")
+	t.Setenv("JWT_LEGACY_SECRET_UNTIL", time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID:    "user123",
+		TokenType: TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    defaultJWTIssuer,
+			Audience:  jwt.ClaimStrings{defaultJWTAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			ID:        "jti-1",
+		},
+	}).SignedString([]byte("legacySecret"))
+
+	token, err := generateJWT(tokenSpec{UserID: "user123", Type: TokenTypeAccess, TTL: time.Minute})
+	assert.NoError(t, err)
+
+	// New tokens are EdDSA with a kid; HS256 ones minted before still pass
+	// during the transition
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	assert.NoError(t, err)
+	assert.Equal(t, jwtkeys.AlgEdDSA, parsed.Method.Alg())
+	assert.NotEmpty(t, parsed.Header["kid"])
+
+	_, err = ParseAccessToken(token)
+	assert.NoError(t, err)
+	_, err = ParseAccessToken(legacy)
+	assert.NoError(t, err)
+
+	// Passing the cut-off, or dropping the secret, ends legacy tokens
+	t.Setenv("JWT_LEGACY_SECRET_UNTIL", time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+	_, err = ParseAccessToken(legacy)
+	assert.Error(t, err)
+	t.Setenv("JWT_SECRET", "")
+	_, err = ParseAccessToken(legacy)
+	assert.Error(t, err)
+}
+
 // --- parseJWT tests ---
 
 func TestParseJWT_Success(t *testing.T) {
@@ -43,19 +84,86 @@ func TestParseJWT_Success(t *testing.T) {
 	defer os.Unsetenv("JWT_SECRET")
 
 	// create valid token
-	token, _ := generateJWT("validUser", time.Minute*5)
+	token, _ := generateJWT(tokenSpec{UserID: "validUser", Type: TokenTypeAccess, TTL: time.Minute * 5})
 
-	claims, err := parseJWT(token)
+	claims, err := parseJWT(token, TokenTypeAccess)
 	assert.NoError(t, err)
 	assert.NotNil(t, claims)
 	assert.Equal(t, "validUser", claims.UserID)
 }
 
+func TestGenerateJWT_Claims(t *testing.T) {
+	os.Setenv("JWT_SECRET", "testsecret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	first, _ := generateJWT(tokenSpec{UserID: "user123", Type: TokenTypeRefresh, TTL: time.Hour})
+	second, _ := generateJWT(tokenSpec{UserID: "user123", Type: TokenTypeRefresh, TTL: time.Hour})
+
+	claims, err := parseJWT(first, TokenTypeRefresh)
+	assert.NoError(t, err)
+	assert.Equal(t, TokenTypeRefresh, claims.TokenType)
+	assert.Equal(t, defaultJWTIssuer, claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{defaultJWTAudience}, claims.Audience)
+	assert.NotEmpty(t, claims.ID)
+
+	// Every token gets its own jti
+	other, _ := parseJWT(second, TokenTypeRefresh)
+	assert.NotEqual(t, claims.ID, other.ID)
+}
+
+func TestParseJWT_RejectsCrossUse(t *testing.T) {
+	os.Setenv("JWT_SECRET", "parseSecret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	access, _ := generateJWT(tokenSpec{UserID: "user123", Type: TokenTypeAccess, TTL: time.Minute})
+	refresh, _ := generateJWT(tokenSpec{UserID: "user123", Type: TokenTypeRefresh, TTL: time.Hour})
+
+	_, err := parseJWT(access, TokenTypeRefresh)
+	assert.Error(t, err)
+
+	_, err = ParseAccessToken(refresh)
+	assert.Error(t, err)
+}
+
+func TestParseJWT_RejectsForeignIssuerAndAudience(t *testing.T) {
+	os.Setenv("JWT_SECRET", "parseSecret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	os.Setenv("JWT_ISSUER", "someone-else")
+	foreignIssuer, _ := generateJWT(tokenSpec{UserID: "user123", Type: TokenTypeAccess, TTL: time.Minute})
+	os.Unsetenv("JWT_ISSUER")
+
+	os.Setenv("JWT_AUDIENCE", "another-api")
+	foreignAudience, _ := generateJWT(tokenSpec{UserID: "user123", Type: TokenTypeAccess, TTL: time.Minute})
+	os.Unsetenv("JWT_AUDIENCE")
+
+	_, err := ParseAccessToken(foreignIssuer)
+	assert.Error(t, err)
+	_, err = ParseAccessToken(foreignAudience)
+	assert.Error(t, err)
+}
+
+func TestParseJWT_RejectsUntypedLegacyToken(t *testing.T) {
+	os.Setenv("JWT_SECRET", "parseSecret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	// Tokens minted before typed claims carry no typ, iss, aud or jti
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID: "user123",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte("parseSecret"))
+
+	_, err := ParseAccessToken(legacy)
+	assert.Error(t, err)
+}
+
 func TestParseJWT_InvalidToken(t *testing.T) {
 	os.Setenv("JWT_SECRET", "parseSecret")
 	defer os.Unsetenv("JWT_SECRET")
 
-	claims, err := parseJWT("this-is-not-a-valid-token")
+	claims, err := parseJWT("this-is-not-a-valid-token", TokenTypeAccess)
 	assert.Error(t, err)
 	assert.Nil(t, claims)
 }
@@ -63,9 +171,9 @@ func TestParseJWT_InvalidToken(t *testing.T) {
 func TestParseJWT_MissingSecret(t *testing.T) {
 	os.Unsetenv("JWT_SECRET")
 
-	claims, err := parseJWT("whatever")
+	claims, err := parseJWT("whatever", TokenTypeAccess)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "JWT_SECRET not set")
+	assert.ErrorIs(t, err, jwtkeys.ErrNoSigningKey)
 	assert.Nil(t, claims)
 }
 
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/jwtkeys"
+)
+
+// JWKSPath is where the public signing keys are published
+const JWKSPath = "/.well-known/jwks.json"
+
+// JWKSHandler godoc
+// @Summary Public token signing keys
+// @Description Returns the JSON Web Key Set other services verify our tokens with: the active signing key plus any older keys still accepted during rotation. Tokens name their key in the "kid" header.
+// @Tags Auth
+// @Produce  json
+// @Success 200 {object} jwtkeys.JWKS "Public keys"
+// @Failure 500 {object} map[string]string "Signing keys misconfigured"
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(c *gin.Context) {
+	keys, err := jwtkeys.Current()
+	if err != nil {
+		log.Printf("❌ Failed to load JWT keys: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server misconfigured"})
+		return
+	}
+
+	// Verifiers may cache briefly; new keys are published before they sign
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, keys.JWKS())
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"personal-assistant-backend/internal/jwtkeys"
+)
+
+// testSigningKeyPEM returns a fresh Ed25519 private key in PEM form
+func testSigningKeyPEM(t *testing.T) string {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(priv)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func setupJWKSRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET(JWKSPath, JWKSHandler)
+	return r
+}
+
+// --- TESTS ---
+
+func TestJWKS_PublishesSigningAndVerificationKeys(t *testing.T) {
+	current, previous := testSigningKeyPEM(t), testSigningKeyPEM(t)
+	t.Setenv("JWT_SIGNING_KEY", current)
+	t.Setenv("JWT_VERIFY_KEYS", previous)
+	t.Setenv("JWT_SECRET", "legacySecret")
+
+	w := serve(setupJWKSRouter(), "GET", JWKSPath)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Cache-Control"), "max-age")
+
+	var set jwtkeys.JWKS
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &set))
+	assert.Len(t, set.Keys, 2)
+	for _, k := range set.Keys {
+		assert.Equal(t, "OKP", k.Kty)
+		assert.NotEmpty(t, k.Kid)
+	}
+	// Neither private keys nor the legacy secret leak
+	assert.NotContains(t, w.Body.String(), `"d"`)
+	assert.NotContains(t, w.Body.String(), "legacySecret")
+}
+
+func TestJWKS_Misconfigured(t *testing.T) {
+	t.Setenv("JWT_SIGNING_KEY", "not a pem")
+	t.Setenv("JWT_SECRET", "")
+
+	w := serve(setupJWKSRouter(), "GET", JWKSPath)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
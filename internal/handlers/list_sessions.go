@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// ListSessions godoc
+// @Summary List active sessions
+// @Description Returns the devices the logged-in user is signed in on, most recently used first. The session making the request is marked current.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.SessionListResponse
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	currentID := c.GetString("sessionID")
+
+	// The live row of each family is its latest, unrotated refresh token
+	rows, err := h.db.Query(`
+		SELECT s.family_id, s.user_agent, s.ip_address, f.started_at, s.last_used_at, s.expires_at
+		FROM sessions s
+		JOIN (
+			SELECT family_id, MIN(created_at) AS started_at
+			FROM sessions
+			WHERE user_id = $1
+			GROUP BY family_id
+		) f ON f.family_id = s.family_id
+		WHERE s.user_id = $1
+			AND s.rotated_at IS NULL AND s.revoked_at IS NULL AND s.expires_at > now()
+		ORDER BY s.last_used_at DESC
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan error"})
+			return
+		}
+		s.Current = s.ID == currentID
+		sessions = append(sessions, s)
+	}
+
+	c.JSON(http.StatusOK, models.SessionListResponse{Sessions: sessions})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"personal-assistant-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- TESTS ---
+
+func TestListSessions_Success(t *testing.T) {
+	router, mock := setupSessionRouter(t)
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT s.family_id, s.user_agent, s.ip_address, f.started_at, s.last_used_at, s.expires_at FROM sessions s JOIN .* WHERE s.user_id = \$1 AND s.rotated_at IS NULL AND s.revoked_at IS NULL AND s.expires_at > now\(\) ORDER BY s.last_used_at DESC`).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id", "user_agent", "ip_address", "started_at", "last_used_at", "expires_at"}).
+			AddRow("session-2", "Firefox", "10.0.0.2", now.Add(-48*time.Hour), now, now.Add(time.Hour)).
+			AddRow("session-1", "curl/8.0", "10.0.0.1", now.Add(-time.Hour), now.Add(-time.Minute), now.Add(time.Hour)))
+
+	w := serve(router, "GET", "/sessions")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.SessionListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Sessions, 2) {
+		assert.Equal(t, "Firefox", resp.Sessions[0].UserAgent)
+		assert.False(t, resp.Sessions[0].Current)
+		assert.Equal(t, "10.0.0.1", resp.Sessions[1].IPAddress)
+		assert.True(t, resp.Sessions[1].Current)
+		assert.NotEmpty(t, resp.Sessions[1].LastUsedAt)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListSessions_DBError(t *testing.T) {
+	router, mock := setupSessionRouter(t)
+
+	mock.ExpectQuery(`SELECT s.family_id`).WillReturnError(errors.New("db exploded"))
+
+	w := serve(router, "GET", "/sessions")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
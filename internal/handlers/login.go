@@ -2,23 +2,26 @@ package handlers
 
 import (
 	"database/sql"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 	"personal-assistant-backend/internal/models"
 )
 
 // Login godoc
 // @Summary Login a user
-// @Description Authenticates a user with email and password, returning account info with JWT access and refresh tokens.
+// @Description Authenticates a user with email and password, returning account info with JWT access and refresh tokens. Accounts with two-factor authentication get an MFA challenge instead, to be completed at /login/mfa.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
 // @Param payload body loginReq true "User login credentials"
 // @Success 200 {object} models.AuthWithTokensResponse "Authenticated user with access and refresh tokens"
+// @Success 202 {object} models.MFAChallengeResponse "Password accepted; a second factor is required"
 // @Failure 400 {object} map[string]string "Invalid payload"
 // @Failure 401 {object} map[string]string "Invalid credentials"
+// @Failure 403 {object} map[string]string "Account disabled"
+// @Failure 429 {object} map[string]string "Too many failed attempts for this email or IP; see Retry-After"
 // @Failure 500 {object} map[string]string "Database or token generation error"
 // @Router /login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -31,42 +34,81 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// ✅ Locked-out emails and IPs are refused before any password check
+	emailKey := emailAttemptKey(req.Email)
+	if h.throttle != nil {
+		wait, err := h.throttle.retryAfter(emailKey, ipAttemptKey(c.ClientIP()))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if wait > 0 {
+			tooSoon(c, wait)
+			return
+		}
+	}
+
 	var user models.User
 	var hash string
+	var mfaEnabled bool
 
 	// ✅ Fetch user from DB
 	err := h.db.QueryRow(`
-		SELECT id, first_name, last_name, email, phone_number, password_hash, created_at
+		SELECT id, first_name, last_name, email, phone_number, password_hash, created_at, totp_enabled_at IS NOT NULL
 		FROM users WHERE email=$1
 	`, req.Email).Scan(
 		&user.ID, &user.FirstName, &user.LastName,
-		&user.Email, &user.PhoneNumber, &hash, &user.CreatedAt,
+		&user.Email, &user.PhoneNumber, &hash, &user.CreatedAt, &mfaEnabled,
 	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-			return
-		}
+	if err != nil && err != sql.ErrNoRows {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 		return
 	}
 
-	// ✅ Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password)); err != nil {
+	// ✅ Verify password; unknown emails, and social-login accounts without a
+	// password, burn the same hashing time and count as failures too, so
+	// neither timing nor lockouts reveal which accounts exist
+	usable := err == nil && hash != ""
+	if !usable {
+		hash = h.hasher().Dummy()
+	}
+	passwordOK, err := h.hasher().Verify(hash, req.Password)
+	if err != nil {
+		log.Printf("❌ Unreadable password hash for user %s: %v\n", user.ID, err)
+	}
+	if !usable || !passwordOK {
+		if h.throttle != nil {
+			h.throttle.failed(c.ClientIP(), emailKey)
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		return
 	}
+	if h.throttle != nil {
+		if err := h.throttle.reset(emailKey); err != nil {
+			log.Printf("❌ Failed to reset login failures for user %s: %v\n", user.ID, err)
+		}
+	}
 
-	// ✅ Generate tokens using injected dependencies
-	accessToken, err := h.generateJWT(user.ID, h.getAccessTTL())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create access token"})
+	// ✅ Upgrade bcrypt and outdated argon2id hashes while we have the password
+	if h.hasher().NeedsRehash(hash) {
+		h.rehashPassword(user.ID, hash, req.Password)
+	}
+
+	// ✅ Two-factor accounts get no tokens until /login/mfa
+	if mfaEnabled {
+		mfaToken, err := h.mfaChallenge(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create mfa token"})
+			return
+		}
+		c.JSON(http.StatusAccepted, models.MFAChallengeResponse{MFARequired: true, MFAToken: mfaToken})
 		return
 	}
 
-	refreshToken, err := h.generateJWT(user.ID, h.getRefreshTTL())
+	// ✅ Start a session and mint its token pair
+	accessToken, refreshToken, err := h.issueTokens(h.db, c, user.ID, "")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create refresh token"})
+		c.JSON(tokenErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -79,3 +121,20 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// rehashPassword replaces oldHash with a hash under the current parameters.
+// It only logs on failure; the old hash still works, so the login goes on.
+func (h *AuthHandler) rehashPassword(userID, oldHash, plain string) {
+	hash, err := hashPassword(h.hasher(), plain)
+	if err != nil {
+		log.Printf("❌ Failed to rehash password for user %s: %v\n", userID, err)
+		return
+	}
+	// Skip it if the password changed since we read it
+	_, err = h.db.Exec(`
+		UPDATE users SET password_hash = $2 WHERE id = $1 AND password_hash = $3
+	`, userID, hash, oldHash)
+	if err != nil {
+		log.Printf("❌ Failed to store rehashed password for user %s: %v\n", userID, err)
+	}
+}
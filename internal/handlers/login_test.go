@@ -15,8 +15,18 @@ import (
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
 	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/password"
 )
 
+// testPasswords hashes cheaply so login tests stay fast
+var testPasswords = password.New(password.Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+
+// testHash hashes pw the way the test handlers expect, so no rehash is due
+func testHash(pw string) string {
+	hash, _ := testPasswords.Hash(pw)
+	return hash
+}
+
 // setupLoginRouter initializes router + AuthHandler with mock dependencies
 func setupLoginRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
 	gin.SetMode(gin.TestMode)
@@ -27,15 +37,11 @@ func setupLoginRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
 	}
 
 	h := &AuthHandler{
-		db: db,
-		generateJWT: func(userID string, _ time.Duration) (string, error) {
-			if userID == "fail-token" {
-				return "", errors.New("token error")
-			}
-			return "mock-token-" + userID, nil
-		},
+		db:            db,
+		generateJWT:   mockGenerateJWT,
 		getAccessTTL:  func() time.Duration { return 15 * time.Minute },
 		getRefreshTTL: func() time.Duration { return 30 * 24 * time.Hour },
+		passwords:     testPasswords,
 	}
 
 	r := gin.Default()
@@ -44,24 +50,27 @@ func setupLoginRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
 	return r, mock
 }
 
+const loginQuery = `SELECT id, first_name, last_name, email, phone_number, password_hash, created_at, totp_enabled_at IS NOT NULL FROM users WHERE email=\$1`
+
 // --- tests ---
 
 func TestLogin_Success(t *testing.T) {
 	router, mock := setupLoginRouter(t)
 
-	password := "supersecret"
-	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash := testHash("supersecret")
 	now := time.Now()
 
-	mock.ExpectQuery(`SELECT id, first_name, last_name, email, phone_number, password_hash, created_at FROM users WHERE email=\$1`).
+	mock.ExpectQuery(loginQuery).
 		WithArgs("jane@example.com").
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at",
-		}).AddRow("123", "Jane", "Doe", "jane@example.com", "555-1234", string(hash), now))
+			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at", "mfa_enabled",
+		}).AddRow("123", "Jane", "Doe", "jane@example.com", "555-1234", hash, now, false))
+	expectSessionInsert(mock, "123", "")
 
 	body := `{"email":"jane@example.com","password":"supersecret"}`
 	req, _ := http.NewRequest("POST", "/login", strings.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "test-agent")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -72,21 +81,22 @@ func TestLogin_Success(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
 	assert.Equal(t, "Jane", resp.User.FirstName)
-	assert.Equal(t, "mock-token-123", resp.AccessToken)
+	assert.Equal(t, "mock-access-123", resp.AccessToken)
+	assert.Equal(t, "mock-refresh-123", resp.RefreshToken)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestLogin_InvalidPassword(t *testing.T) {
 	router, mock := setupLoginRouter(t)
 
-	hash, _ := bcrypt.GenerateFromPassword([]byte("differentpass"), bcrypt.DefaultCost)
+	hash := testHash("differentpass")
 	now := time.Now()
 
-	mock.ExpectQuery(`SELECT id, first_name, last_name, email, phone_number, password_hash, created_at FROM users WHERE email=\$1`).
+	mock.ExpectQuery(loginQuery).
 		WithArgs("bob@example.com").
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at",
-		}).AddRow("456", "Bob", "Smith", "bob@example.com", "555-4567", string(hash), now))
+			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at", "mfa_enabled",
+		}).AddRow("456", "Bob", "Smith", "bob@example.com", "555-4567", hash, now, false))
 
 	body := `{"email":"bob@example.com","password":"wrongpass"}`
 	req, _ := http.NewRequest("POST", "/login", strings.NewReader(body))
@@ -102,7 +112,7 @@ func TestLogin_InvalidPassword(t *testing.T) {
 func TestLogin_UserNotFound(t *testing.T) {
 	router, mock := setupLoginRouter(t)
 
-	mock.ExpectQuery(`SELECT id, first_name, last_name, email, phone_number, password_hash, created_at FROM users WHERE email=\$1`).
+	mock.ExpectQuery(loginQuery).
 		WithArgs("ghost@example.com").
 		WillReturnError(sql.ErrNoRows)
 
@@ -120,7 +130,7 @@ func TestLogin_UserNotFound(t *testing.T) {
 func TestLogin_DBError(t *testing.T) {
 	router, mock := setupLoginRouter(t)
 
-	mock.ExpectQuery(`SELECT id, first_name, last_name, email, phone_number, password_hash, created_at FROM users WHERE email=\$1`).
+	mock.ExpectQuery(loginQuery).
 		WithArgs("crash@example.com").
 		WillReturnError(errors.New("db exploded"))
 
@@ -147,3 +157,141 @@ func TestLogin_BadRequest(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Contains(t, w.Body.String(), "invalid payload")
 }
+
+func TestLogin_SessionError(t *testing.T) {
+	router, mock := setupLoginRouter(t)
+
+	hash := testHash("supersecret")
+	mock.ExpectQuery(loginQuery).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at", "mfa_enabled",
+		}).AddRow("123", "Jane", "Doe", "jane@example.com", "", hash, time.Now(), false))
+	mock.ExpectQuery(`INSERT INTO sessions`).WillReturnError(errors.New("db exploded"))
+
+	body := `{"email":"jane@example.com","password":"supersecret"}`
+	req, _ := http.NewRequest("POST", "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "failed to create session")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogin_MFARequired(t *testing.T) {
+	router, mock := setupLoginRouter(t)
+
+	hash := testHash("supersecret")
+	mock.ExpectQuery(loginQuery).
+		WithArgs("jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at", "mfa_enabled",
+		}).AddRow("123", "Jane", "Doe", "jane@example.com", "", hash, time.Now(), true))
+
+	body := `{"email":"jane@example.com","password":"supersecret"}`
+	req, _ := http.NewRequest("POST", "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// No session is created until the second factor checks out
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var resp models.MFAChallengeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.MFARequired)
+	assert.Equal(t, "mock-mfa-123", resp.MFAToken)
+	assert.NotContains(t, w.Body.String(), "access_token")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogin_PasswordlessAccount(t *testing.T) {
+	router, mock := setupLoginRouter(t)
+
+	// Accounts created through social login have no password to match
+	mock.ExpectQuery(loginQuery).
+		WithArgs("jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at", "mfa_enabled",
+		}).AddRow("789", "Jane", "Doe", "jane@example.com", "", "", time.Now(), false))
+
+	body := `{"email":"jane@example.com","password":"anything"}`
+	req, _ := http.NewRequest("POST", "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid credentials")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+const rehashQuery = `UPDATE users SET password_hash = \$2 WHERE id = \$1 AND password_hash = \$3`
+
+func TestLogin_UpgradesOutdatedHashes(t *testing.T) {
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("supersecret"), bcrypt.MinCost)
+	weaker, _ := password.New(password.Params{Memory: 32, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}).Hash("supersecret")
+
+	for name, old := range map[string]string{"bcrypt": string(legacy), "weaker argon2id": weaker} {
+		t.Run(name, func(t *testing.T) {
+			router, mock := setupLoginRouter(t)
+
+			mock.ExpectQuery(loginQuery).
+				WithArgs("jane@example.com").
+				WillReturnRows(sqlmock.NewRows([]string{
+					"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at", "mfa_enabled",
+				}).AddRow("123", "Jane", "Doe", "jane@example.com", "", old, time.Now(), false))
+			var upgraded string
+			mock.ExpectExec(rehashQuery).
+				WithArgs("123", capture{&upgraded}, old).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectSessionInsert(mock, "123", "")
+
+			w := serveJSON(router, "POST", "/login", `{"email":"jane@example.com","password":"supersecret"}`)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.False(t, testPasswords.NeedsRehash(upgraded))
+			ok, _ := testPasswords.Verify(upgraded, "supersecret")
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestLogin_RehashFailureStillLogsIn(t *testing.T) {
+	router, mock := setupLoginRouter(t)
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("supersecret"), bcrypt.MinCost)
+
+	mock.ExpectQuery(loginQuery).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at", "mfa_enabled",
+		}).AddRow("123", "Jane", "Doe", "jane@example.com", "", string(legacy), time.Now(), false))
+	mock.ExpectExec(rehashQuery).WillReturnError(errors.New("db down"))
+	expectSessionInsert(mock, "123", "")
+
+	w := serveJSON(router, "POST", "/login", `{"email":"jane@example.com","password":"supersecret"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogin_DisabledAccount(t *testing.T) {
+	router, mock := setupLoginRouter(t)
+
+	mock.ExpectQuery(loginQuery).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at", "mfa_enabled",
+		}).AddRow("123", "Jane", "Doe", "jane@example.com", "", testHash("supersecret"), time.Now(), false))
+	// The session insert finds no enabled user to attach to
+	mock.ExpectQuery(`INSERT INTO sessions`).WillReturnError(sql.ErrNoRows)
+
+	w := serveJSON(router, "POST", "/login", `{"email":"jane@example.com","password":"supersecret"}`)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "account disabled")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// lockoutPolicy allows FreeFailures failed attempts, then locks the key for
+// BaseLock, doubling with every further failure up to MaxLock. Counters
+// start over once a key has gone ResetAfter without failing.
+type lockoutPolicy struct {
+	FreeFailures int
+	BaseLock     time.Duration
+	MaxLock      time.Duration
+	ResetAfter   time.Duration
+}
+
+var (
+	// accountLockout guards a single account (by email, or by user for MFA codes)
+	accountLockout = lockoutPolicy{FreeFailures: 5, BaseLock: 30 * time.Second, MaxLock: 15 * time.Minute, ResetAfter: time.Hour}
+	// ipLockout is looser since NAT puts many users behind one address
+	ipLockout = lockoutPolicy{FreeFailures: 20, BaseLock: 30 * time.Second, MaxLock: 15 * time.Minute, ResetAfter: time.Hour}
+	// resetEmailLimit caps password reset mails to one inbox; every request counts
+	resetEmailLimit = lockoutPolicy{FreeFailures: 3, BaseLock: 5 * time.Minute, MaxLock: time.Hour, ResetAfter: time.Hour}
+	// resetIPLimit stops one client from mailing many inboxes
+	resetIPLimit = lockoutPolicy{FreeFailures: 20, BaseLock: 5 * time.Minute, MaxLock: time.Hour, ResetAfter: time.Hour}
+)
+
+// lockFor returns how long to lock a key after its nth consecutive failure.
+func (p lockoutPolicy) lockFor(failures int) time.Duration {
+	over := failures - p.FreeFailures
+	if over <= 0 {
+		return 0
+	}
+	lock := p.BaseLock
+	for i := 1; i < over && lock < p.MaxLock; i++ {
+		lock *= 2
+	}
+	return min(lock, p.MaxLock)
+}
+
+// loginThrottle tracks failed logins in Postgres so every app instance
+// shares the same counters.
+type loginThrottle struct {
+	db *sql.DB
+}
+
+func emailAttemptKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string { return "ip:" + ip }
+
+func mfaAttemptKey(userID string) string { return "mfa:" + userID }
+
+func resetEmailKey(email string) string {
+	return "reset:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func resetIPKey(ip string) string { return "reset-ip:" + ip }
+
+// retryAfter returns how long the most restrictive of keys stays locked, or
+// zero when none is.
+func (t *loginThrottle) retryAfter(keys ...string) (time.Duration, error) {
+	placeholders := make([]string, len(keys))
+	args := make([]any, len(keys))
+	for i, k := range keys {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = k
+	}
+
+	var seconds float64
+	err := t.db.QueryRow(`
+		SELECT COALESCE(EXTRACT(EPOCH FROM MAX(locked_until) - now()), 0)::float8
+		FROM login_attempts
+		WHERE key IN (`+strings.Join(placeholders, ", ")+`) AND locked_until > now()
+	`, args...).Scan(&seconds)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// recordFailure counts a failed attempt against key and locks it once the
+// policy's free attempts are used up.
+func (t *loginThrottle) recordFailure(key string, p lockoutPolicy) error {
+	var failures int
+	err := t.db.QueryRow(`
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, now())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.last_failure_at < now() - make_interval(secs => $2) THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = now()
+		RETURNING failures
+	`, key, p.ResetAfter.Seconds()).Scan(&failures)
+	if err != nil {
+		return err
+	}
+
+	lock := p.lockFor(failures)
+	if lock == 0 {
+		return nil
+	}
+	_, err = t.db.Exec(`
+		UPDATE login_attempts SET locked_until = now() + make_interval(secs => $2) WHERE key = $1
+	`, key, lock.Seconds())
+	if err == nil {
+		log.Printf("⚠️ Locked %s for %s after %d failed attempts\n", key, lock, failures)
+	}
+	return err
+}
+
+// reset forgets key's failures after a successful login.
+func (t *loginThrottle) reset(key string) error {
+	_, err := t.db.Exec(`DELETE FROM login_attempts WHERE key = $1`, key)
+	return err
+}
+
+// failed records a failed attempt against each key, logging errors since the
+// caller is already answering 401.
+func (t *loginThrottle) failed(ip, accountKey string) {
+	if err := t.recordFailure(accountKey, accountLockout); err != nil {
+		log.Printf("❌ Failed to record login failure for %s: %v\n", accountKey, err)
+	}
+	if err := t.recordFailure(ipAttemptKey(ip), ipLockout); err != nil {
+		log.Printf("❌ Failed to record login failure for ip %s: %v\n", ip, err)
+	}
+}
+
+// resetRequested counts a password reset request against the email and the
+// client IP, whether or not the account exists, logging errors since the
+// caller answers generically either way.
+func (t *loginThrottle) resetRequested(email, ip string) {
+	if err := t.recordFailure(resetEmailKey(email), resetEmailLimit); err != nil {
+		log.Printf("❌ Failed to record reset request for %s: %v\n", email, err)
+	}
+	if err := t.recordFailure(resetIPKey(ip), resetIPLimit); err != nil {
+		log.Printf("❌ Failed to record reset request for ip %s: %v\n", ip, err)
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// setupThrottledLoginRouter is setupLoginRouter with brute-force protection on
+func setupThrottledLoginRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	h := &AuthHandler{
+		db:            db,
+		generateJWT:   mockGenerateJWT,
+		getAccessTTL:  mockGetAccessTTL,
+		getRefreshTTL: mockGetRefreshTTL,
+		throttle:      &loginThrottle{db: db},
+		passwords:     testPasswords,
+	}
+
+	r := gin.Default()
+	r.POST("/login", h.Login)
+	return r, mock
+}
+
+const (
+	retryAfterQuery     = `SELECT COALESCE\(EXTRACT\(EPOCH FROM MAX\(locked_until\) - now\(\)\), 0\)::float8 FROM login_attempts WHERE key IN \(\$1, \$2\) AND locked_until > now\(\)`
+	recordFailureQuery  = `INSERT INTO login_attempts \(key, failures, last_failure_at\) VALUES \(\$1, 1, now\(\)\) ON CONFLICT \(key\) DO UPDATE`
+	lockKeyQuery        = `UPDATE login_attempts SET locked_until = now\(\) \+ make_interval\(secs => \$2\) WHERE key = \$1`
+	resetAttemptsQuery  = `DELETE FROM login_attempts WHERE key = \$1`
+	testClientIPKey     = "ip:192.0.2.1"
+	testLoginRemoteAddr = "192.0.2.1:1234"
+)
+
+func expectRetryAfter(mock sqlmock.Sqlmock, key string, seconds float64) {
+	mock.ExpectQuery(retryAfterQuery).
+		WithArgs(key, testClientIPKey).
+		WillReturnRows(sqlmock.NewRows([]string{"seconds"}).AddRow(seconds))
+}
+
+func expectFailure(mock sqlmock.Sqlmock, key string, failures int) {
+	mock.ExpectQuery(recordFailureQuery).
+		WithArgs(key, float64(3600)).
+		WillReturnRows(sqlmock.NewRows([]string{"failures"}).AddRow(failures))
+}
+
+func postLogin(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = testLoginRemoteAddr
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// --- TESTS ---
+
+func TestLockoutPolicy_LockFor(t *testing.T) {
+	p := lockoutPolicy{FreeFailures: 5, BaseLock: 30 * time.Second, MaxLock: 5 * time.Minute}
+
+	assert.Equal(t, time.Duration(0), p.lockFor(5))
+	assert.Equal(t, 30*time.Second, p.lockFor(6))
+	assert.Equal(t, time.Minute, p.lockFor(7))
+	assert.Equal(t, 2*time.Minute, p.lockFor(8))
+	assert.Equal(t, 4*time.Minute, p.lockFor(9))
+	assert.Equal(t, 5*time.Minute, p.lockFor(10))
+	assert.Equal(t, 5*time.Minute, p.lockFor(100))
+}
+
+func TestLogin_LockedOut(t *testing.T) {
+	router, mock := setupThrottledLoginRouter(t)
+
+	expectRetryAfter(mock, "email:jane@example.com", 89.2)
+
+	w := postLogin(router, `{"email":"Jane@Example.com","password":"supersecret"}`)
+
+	// Refused without looking at the password
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogin_WrongPasswordCountsFailures(t *testing.T) {
+	router, mock := setupThrottledLoginRouter(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("supersecret"), bcrypt.MinCost)
+	expectRetryAfter(mock, "email:jane@example.com", 0)
+	mock.ExpectQuery(loginQuery).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at", "mfa_enabled",
+		}).AddRow("123", "Jane", "Doe", "jane@example.com", "", string(hash), time.Now(), false))
+	// The sixth failure locks the email; the IP still has free attempts
+	expectFailure(mock, "email:jane@example.com", 6)
+	mock.ExpectExec(lockKeyQuery).
+		WithArgs("email:jane@example.com", float64(30)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectFailure(mock, testClientIPKey, 6)
+
+	w := postLogin(router, `{"email":"jane@example.com","password":"wrong"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogin_UnknownEmailLooksLikeWrongPassword(t *testing.T) {
+	router, mock := setupThrottledLoginRouter(t)
+
+	expectRetryAfter(mock, "email:ghost@example.com", 0)
+	mock.ExpectQuery(loginQuery).WillReturnError(sql.ErrNoRows)
+	expectFailure(mock, "email:ghost@example.com", 1)
+	expectFailure(mock, testClientIPKey, 1)
+
+	w := postLogin(router, `{"email":"ghost@example.com","password":"supersecret"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid credentials")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogin_SuccessResetsFailures(t *testing.T) {
+	router, mock := setupThrottledLoginRouter(t)
+
+	hash := testHash("supersecret")
+	expectRetryAfter(mock, "email:jane@example.com", 0)
+	mock.ExpectQuery(loginQuery).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "first_name", "last_name", "email", "phone_number", "password_hash", "created_at", "mfa_enabled",
+		}).AddRow("123", "Jane", "Doe", "jane@example.com", "", hash, time.Now(), false))
+	mock.ExpectExec(resetAttemptsQuery).
+		WithArgs("email:jane@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectSessionInsert(mock, "123", "")
+
+	w := postLogin(router, `{"email":"jane@example.com","password":"supersecret"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logout godoc
+// @Summary Log out
+// @Description Revokes the session the access token belongs to. Its refresh token stops working immediately and its access tokens are rejected from then on.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]string "Logged out"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID := c.GetString("userID")
+	sessionID := c.GetString("sessionID")
+	if userID == "" || sessionID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if _, err := revokeSessions(h.db, userID, sessionID, ""); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupSessionRouter mounts the session endpoints behind a fake JWT middleware
+func setupSessionRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	h := NewAuthHandler(db, nil, nil)
+	r := gin.Default()
+
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Set("sessionID", "session-1")
+		c.Next()
+	})
+
+	r.POST("/logout", h.Logout)
+	r.GET("/sessions", h.ListSessions)
+	r.DELETE("/sessions", h.RevokeOtherSessions)
+	r.DELETE("/sessions/:session_id", h.RevokeSession)
+	return r, mock
+}
+
+const revokeSessionsQuery = `UPDATE sessions SET revoked_at = now\(\) WHERE user_id = \$1 AND revoked_at IS NULL AND \(\$2 = '' OR family_id::text = \$2\) AND \(\$3 = '' OR family_id::text <> \$3\)`
+
+func serve(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func serveJSON(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// --- TESTS ---
+
+func TestLogout_RevokesCurrentSession(t *testing.T) {
+	router, mock := setupSessionRouter(t)
+
+	mock.ExpectExec(revokeSessionsQuery).
+		WithArgs("user123", "session-1", "").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	w := serve(router, "POST", "/logout")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Logged out")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogout_DBError(t *testing.T) {
+	router, mock := setupSessionRouter(t)
+
+	mock.ExpectExec(revokeSessionsQuery).WillReturnError(errors.New("db exploded"))
+
+	w := serve(router, "POST", "/logout")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "db error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogout_NoSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewAuthHandler(nil, nil, nil)
+	r := gin.Default()
+	r.POST("/logout", h.Logout)
+
+	w := serve(r, "POST", "/logout")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
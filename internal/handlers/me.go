@@ -1,28 +1,229 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"personal-assistant-backend/internal/mail"
+	"personal-assistant-backend/internal/models"
 )
 
 // Me godoc
 // @Summary Get current user info
-// @Description Returns the authenticated user's ID extracted from the JWT access token.
-// @Tags Misc
+// @Description Returns the authenticated user's profile.
+// @Tags Auth
 // @Security BearerAuth
 // @Produce  json
-// @Success 200 {object} map[string]interface{} "User ID returned successfully"
+// @Success 200 {object} models.MeResponse "Current user"
 // @Failure 401 {object} map[string]string "Unauthorized or missing user ID"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 500 {object} map[string]string "Database error"
 // @Router /me [get]
 func (h *AuthHandler) Me(c *gin.Context) {
-	userID, ok := c.Get("userID")
-	if !ok || userID == "" {
+	userID := c.GetString("userID")
+	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "userID not found in context"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"user_id": userID,
-	})
+	var user models.User
+	err := h.db.QueryRow(`
+		SELECT id, first_name, last_name, email, phone_number, created_at, email_verified_at, deletion_scheduled_at, role
+		FROM users WHERE id = $1
+	`, userID).Scan(
+		&user.ID, &user.FirstName, &user.LastName, &user.Email,
+		&user.PhoneNumber, &user.CreatedAt, &user.EmailVerifiedAt, &user.DeletionScheduledAt, &user.Role,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MeResponse{User: user})
+}
+
+// UpdateMe godoc
+// @Summary Update current user profile
+// @Description Changes name, email or phone number. Omitted fields keep their value; phone numbers must be E.164 (an empty string clears it). Changing the email requires the current password, marks the new address unverified, sends it a verification email and notifies the previous address.
+// @Tags Auth
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param payload body updateProfileReq true "Fields to change"
+// @Success 200 {object} models.MeResponse "Updated user"
+// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Current password is incorrect"
+// @Failure 404 {object} map[string]string "User not found"
+// @Failure 409 {object} map[string]string "Email already exists"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /me [patch]
+func (h *AuthHandler) UpdateMe(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req updateProfileReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// ✅ A stolen access token alone must not be enough to redirect the
+	// account's email, and with it /password/forgot
+	var oldEmail string
+	if req.Email != nil {
+		var current string
+		err := h.db.QueryRow(`SELECT email, password_hash FROM users WHERE id = $1`, userID).Scan(&oldEmail, &current)
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if ok, _ := h.hasher().Verify(current, req.CurrentPassword); !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "current password is incorrect"})
+			return
+		}
+	}
+
+	// ✅ A new address starts unverified; old.email is the pre-update value
+	var user models.User
+	var emailChanged bool
+	err := h.db.QueryRow(`
+		WITH old AS (SELECT email FROM users WHERE id = $1 FOR UPDATE)
+		UPDATE users u
+		SET first_name = COALESCE($2, u.first_name),
+			last_name = COALESCE($3, u.last_name),
+			phone_number = COALESCE($4, u.phone_number),
+			email = COALESCE($5, u.email),
+			email_verified_at = CASE WHEN COALESCE($5, u.email) <> old.email THEN NULL ELSE u.email_verified_at END,
+			email_verification_sent_at = CASE WHEN COALESCE($5, u.email) <> old.email THEN now() ELSE u.email_verification_sent_at END
+		FROM old
+		WHERE u.id = $1
+		RETURNING u.id, u.first_name, u.last_name, u.email, u.phone_number, u.created_at, u.email_verified_at,
+			u.deletion_scheduled_at, u.role, u.email <> old.email
+	`, userID, req.FirstName, req.LastName, req.PhoneNumber, req.Email).Scan(
+		&user.ID, &user.FirstName, &user.LastName, &user.Email,
+		&user.PhoneNumber, &user.CreatedAt, &user.EmailVerifiedAt, &user.DeletionScheduledAt, &user.Role, &emailChanged,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			c.JSON(http.StatusConflict, gin.H{"error": "email already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	if emailChanged {
+		if err := h.sendVerificationEmail(c.Request.Context(), user.ID, user.Email, user.FirstName); err != nil {
+			log.Printf("❌ Failed to send verification email to user %s: %v\n", user.ID, err)
+		}
+		// A heads-up in case someone else changed the address
+		msg := mail.Message{
+			To:      oldEmail,
+			Subject: "Your email address was changed",
+			Body: fmt.Sprintf(
+				"Hi %s,\n\nThe email address on your account was changed to %s.\n\nIf you didn't make this change, sign in, change your password and contact support right away.\n",
+				user.FirstName, user.Email),
+		}
+		if err := h.mailer.Send(c.Request.Context(), msg); err != nil {
+			log.Printf("❌ Failed to send email change notice to user %s: %v\n", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, models.MeResponse{User: user})
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Sets a new password after checking the current one. Every other session of the user is revoked; the calling session stays logged in.
+// @Tags Auth
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param payload body changePasswordReq true "Current and new password"
+// @Success 200 {object} map[string]string "Password changed"
+// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Current password is incorrect"
+// @Failure 500 {object} map[string]string "Database or hashing error"
+// @Router /me/password [post]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req changePasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer tx.Rollback()
+
+	var current string
+	err = tx.QueryRow(`SELECT password_hash FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&current)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if ok, _ := h.hasher().Verify(current, req.CurrentPassword); !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "current password is incorrect"})
+		return
+	}
+
+	hash, err := hashPassword(h.hasher(), req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		return
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET password_hash = $2 WHERE id = $1`, userID, hash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if _, err := revokeSessions(tx, userID, "", c.GetString("sessionID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed"})
 }
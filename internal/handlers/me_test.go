@@ -1,39 +1,86 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"personal-assistant-backend/internal/mail"
+	"personal-assistant-backend/internal/models"
 )
 
-// TestMe_Success ensures Me returns user_id when present in context
-func TestMe_Success(t *testing.T) {
+// setupMeRouter mounts the profile endpoints for user123 in session-1
+func setupMeRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock, *mail.MemorySender) {
 	gin.SetMode(gin.TestMode)
 
-	h := &AuthHandler{}
-	router := gin.Default()
-	router.GET("/me", h.Me)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
 
-	req, _ := http.NewRequest("GET", "/me", nil)
-	w := httptest.NewRecorder()
+	mailer := mail.NewMemorySender()
+	h := &AuthHandler{db: db, generateJWT: mockGenerateJWT, mailer: mailer, passwords: testPasswords}
 
-	// Simulate middleware setting "userID"
-	c, _ := gin.CreateTestContext(w)
-	c.Request = req
-	c.Set("userID", "user-123")
+	r := gin.Default()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Set("sessionID", "session-1")
+		c.Next()
+	})
+	r.GET("/me", h.Me)
+	r.PATCH("/me", h.UpdateMe)
+	r.POST("/me/password", h.ChangePassword)
+	r.DELETE("/me", h.DeleteMe)
+	r.DELETE("/me/deletion", h.CancelDeletion)
+	r.POST("/me/export", h.RequestExport)
+	r.GET("/me/export/:export_id", h.GetExport)
+	r.GET("/me/export/:export_id/download", h.DownloadExport)
+	return r, mock, mailer
+}
 
-	h.Me(c)
+func patchJSON(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	return serveJSON(router, "PATCH", path, body)
+}
+
+var updateProfileColumns = []string{
+	"id", "first_name", "last_name", "email", "phone_number", "created_at", "email_verified_at",
+	"deletion_scheduled_at", "role", "email_changed",
+}
+
+// --- TESTS ---
+
+// TestMe_Success ensures Me returns the full profile of the user in context
+func TestMe_Success(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, first_name, last_name, email, phone_number, created_at, email_verified_at, deletion_scheduled_at, role FROM users WHERE id = \$1`).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "first_name", "last_name", "email", "phone_number", "created_at", "email_verified_at", "deletion_scheduled_at", "role",
+		}).AddRow("user123", "Jane", "Doe", "jane@example.com", "+14155552671", now, now, nil, "user"))
+
+	w := serve(router, "GET", "/me")
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var resp map[string]interface{}
-	err := json.Unmarshal(w.Body.Bytes(), &resp)
-	assert.NoError(t, err)
-	assert.Equal(t, "user-123", resp["user_id"])
+	var resp models.MeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "user123", resp.User.ID)
+	assert.Equal(t, "jane@example.com", resp.User.Email)
+	assert.Equal(t, "+14155552671", resp.User.PhoneNumber)
+	assert.NotNil(t, resp.User.EmailVerifiedAt)
+	assert.Equal(t, "user", resp.User.Role)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 // TestMe_Unauthorized ensures Me returns 401 if userID missing
@@ -56,3 +103,187 @@ func TestMe_Unauthorized(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 	assert.Contains(t, w.Body.String(), "userID not found")
 }
+
+func TestMe_NotFound(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	mock.ExpectQuery(`SELECT id, first_name`).WillReturnError(sql.ErrNoRows)
+
+	w := serve(router, "GET", "/me")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateMe_Profile(t *testing.T) {
+	router, mock, mailer := setupMeRouter(t)
+
+	first, phone := "Janet", "+14155552671"
+	mock.ExpectQuery(`WITH old AS \(SELECT email FROM users WHERE id = \$1 FOR UPDATE\) UPDATE users u SET first_name = COALESCE\(\$2, u.first_name\)`).
+		WithArgs("user123", &first, nil, &phone, nil).
+		WillReturnRows(sqlmock.NewRows(updateProfileColumns).
+			AddRow("user123", "Janet", "Doe", "jane@example.com", phone, time.Now(), nil, nil, "user", false))
+
+	w := patchJSON(router, "/me", `{"first_name":"Janet","phone_number":"+14155552671"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.MeResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Janet", resp.User.FirstName)
+	assert.Equal(t, "user", resp.User.Role)
+	assert.Empty(t, mailer.Sent())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateMe_ClearPhone(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	empty := ""
+	mock.ExpectQuery(`UPDATE users u`).
+		WithArgs("user123", nil, nil, &empty, nil).
+		WillReturnRows(sqlmock.NewRows(updateProfileColumns).
+			AddRow("user123", "Jane", "Doe", "jane@example.com", "", time.Now(), nil, nil, "user", false))
+
+	w := patchJSON(router, "/me", `{"phone_number":""}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expectPasswordCheck mocks the lookup UpdateMe does before an email change
+func expectPasswordCheck(mock sqlmock.Sqlmock, email, password string) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	mock.ExpectQuery(`SELECT email, password_hash FROM users WHERE id = \$1`).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"email", "password_hash"}).AddRow(email, string(hash)))
+}
+
+func TestUpdateMe_EmailChangeSendsVerification(t *testing.T) {
+	router, mock, mailer := setupMeRouter(t)
+
+	expectPasswordCheck(mock, "jane@example.com", "old-password")
+	mock.ExpectQuery(`UPDATE users u`).
+		WillReturnRows(sqlmock.NewRows(updateProfileColumns).
+			AddRow("user123", "Jane", "Doe", "new@example.com", "", time.Now(), nil, nil, "user", true))
+
+	w := patchJSON(router, "/me", `{"email":"new@example.com","current_password":"old-password"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "email_verified_at")
+
+	// A verification link to the new address, a notice to the old one
+	sent := mailer.Sent()
+	if assert.Len(t, sent, 2) {
+		assert.Equal(t, "new@example.com", sent[0].To)
+		assert.Equal(t, "jane@example.com", sent[1].To)
+		assert.Contains(t, sent[1].Body, "new@example.com")
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateMe_EmailChangeNeedsPassword(t *testing.T) {
+	router, mock, mailer := setupMeRouter(t)
+
+	w := patchJSON(router, "/me", `{"email":"new@example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	expectPasswordCheck(mock, "jane@example.com", "old-password")
+	w = patchJSON(router, "/me", `{"email":"new@example.com","current_password":"guess"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "current password is incorrect")
+
+	assert.Empty(t, mailer.Sent())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateMe_InvalidPhone(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	for _, body := range []string{
+		`{"phone_number":"555-1234"}`,
+		`{"phone_number":"14155552671"}`,
+		`{"email":"not-an-email"}`,
+		`{"first_name":""}`,
+	} {
+		w := patchJSON(router, "/me", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), "invalid payload", body)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateMe_EmailTaken(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	expectPasswordCheck(mock, "jane@example.com", "old-password")
+	mock.ExpectQuery(`UPDATE users u`).WillReturnError(&pgconn.PgError{Code: "23505"})
+
+	w := patchJSON(router, "/me", `{"email":"taken@example.com","current_password":"old-password"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "email already exists")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT password_hash FROM users WHERE id = \$1 FOR UPDATE`).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"password_hash"}).AddRow(string(hash)))
+	mock.ExpectExec(`UPDATE users SET password_hash = \$2 WHERE id = \$1`).
+		WithArgs("user123", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Every session except the caller's is revoked
+	mock.ExpectExec(revokeSessionsQuery).
+		WithArgs("user123", "", "session-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	w := postJSON(router, "/me/password", `{"current_password":"old-password","new_password":"new-password"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Password changed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangePassword_WrongCurrent(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT password_hash FROM users`).
+		WillReturnRows(sqlmock.NewRows([]string{"password_hash"}).AddRow(string(hash)))
+	mock.ExpectRollback()
+
+	w := postJSON(router, "/me/password", `{"current_password":"guess","new_password":"new-password"}`)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "current password is incorrect")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangePassword_InvalidPayload(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	w := postJSON(router, "/me/password", `{"current_password":"old-password","new_password":"short"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangePassword_DBError(t *testing.T) {
+	router, mock, _ := setupMeRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT password_hash FROM users`).WillReturnError(errors.New("db exploded"))
+	mock.ExpectRollback()
+
+	w := postJSON(router, "/me/password", `{"current_password":"old-password","new_password":"new-password"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/totp"
+)
+
+const (
+	// mfaChallengeTTL is how long the second login step may take.
+	mfaChallengeTTL = 5 * time.Minute
+	// recoveryCodeCount codes are issued when TOTP is enabled.
+	recoveryCodeCount = 10
+)
+
+// EnrollTOTP godoc
+// @Summary Start TOTP enrollment
+// @Description Generates a new TOTP secret and otpauth:// URI for an authenticator app. Two-factor authentication is only enabled once a code is confirmed at /me/mfa/totp/confirm; enrolling again before that replaces the secret.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce  json
+// @Success 200 {object} models.TOTPEnrollResponse "Secret and otpauth URI"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Two-factor authentication already enabled"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /me/mfa/totp [post]
+func (h *AuthHandler) EnrollTOTP(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate secret"})
+		return
+	}
+
+	var email string
+	err = h.db.QueryRow(`
+		UPDATE users SET totp_secret = $2
+		WHERE id = $1 AND totp_enabled_at IS NULL
+		RETURNING email
+	`, userID, secret).Scan(&email)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication already enabled"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TOTPEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(totpIssuer(), email, secret),
+	})
+}
+
+// ConfirmTOTP godoc
+// @Summary Confirm TOTP enrollment
+// @Description Enables two-factor authentication once the authenticator app produces a valid code, and returns single-use recovery codes. They are not shown again.
+// @Tags Auth
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param payload body totpCodeReq true "Current TOTP code"
+// @Success 200 {object} models.RecoveryCodesResponse "Recovery codes"
+// @Failure 400 {object} map[string]string "Invalid payload, invalid code or no pending enrollment"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 409 {object} map[string]string "Two-factor authentication already enabled"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /me/mfa/totp/confirm [post]
+func (h *AuthHandler) ConfirmTOTP(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req totpCodeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer tx.Rollback()
+
+	var secret sql.NullString
+	var enabled bool
+	err = tx.QueryRow(`
+		SELECT totp_secret, totp_enabled_at IS NOT NULL FROM users WHERE id = $1 FOR UPDATE
+	`, userID).Scan(&secret, &enabled)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication already enabled"})
+		return
+	}
+	if !secret.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no pending enrollment"})
+		return
+	}
+
+	step, ok := totp.Validate(secret.String, req.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+		return
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE users SET totp_enabled_at = now(), totp_last_step = $2 WHERE id = $1
+	`, userID, step); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP godoc
+// @Summary Disable TOTP
+// @Description Turns two-factor authentication off after checking the account password, and deletes the recovery codes.
+// @Tags Auth
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param payload body disableTOTPReq true "Account password"
+// @Success 200 {object} map[string]string "Two-factor authentication disabled"
+// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 403 {object} map[string]string "Password is incorrect"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /me/mfa/totp [delete]
+func (h *AuthHandler) DisableTOTP(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req disableTOTPReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer tx.Rollback()
+
+	var hash string
+	if err := tx.QueryRow(`SELECT password_hash FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&hash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if ok, _ := h.hasher().Verify(hash, req.Password); !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "password is incorrect"})
+		return
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL WHERE id = $1
+	`, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// LoginMFA godoc
+// @Summary Complete a two-factor login
+// @Description Exchanges the mfa_token returned by /login plus a TOTP code (or an unused recovery code) for account info with JWT access and refresh tokens.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param payload body loginMFAReq true "MFA challenge token and code"
+// @Success 200 {object} models.AuthWithTokensResponse "Authenticated user with access and refresh tokens"
+// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 401 {object} map[string]string "Invalid or expired MFA token, or invalid code"
+// @Failure 403 {object} map[string]string "Account disabled"
+// @Failure 429 {object} map[string]string "Too many failed codes; see Retry-After"
+// @Failure 500 {object} map[string]string "Database or token generation error"
+// @Router /login/mfa [post]
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req loginMFAReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	claims, err := h.parseJWT(req.MFAToken, TokenTypeMFA)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired mfa token"})
+		return
+	}
+
+	var user models.User
+	var secret string
+	err = h.db.QueryRow(`
+		SELECT id, first_name, last_name, email, phone_number, created_at, totp_secret
+		FROM users WHERE id = $1 AND totp_enabled_at IS NOT NULL
+	`, claims.UserID).Scan(
+		&user.ID, &user.FirstName, &user.LastName,
+		&user.Email, &user.PhoneNumber, &user.CreatedAt, &secret,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired mfa token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	// ✅ Six digits are quick to guess, so codes get the same lockout as passwords
+	mfaKey := mfaAttemptKey(user.ID)
+	if h.throttle != nil {
+		wait, err := h.throttle.retryAfter(mfaKey, ipAttemptKey(c.ClientIP()))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if wait > 0 {
+			tooSoon(c, wait)
+			return
+		}
+	}
+
+	ok, err := h.checkSecondFactor(user.ID, secret, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if !ok {
+		if h.throttle != nil {
+			h.throttle.failed(c.ClientIP(), mfaKey)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid code"})
+		return
+	}
+	if h.throttle != nil {
+		if err := h.throttle.reset(mfaKey); err != nil {
+			log.Printf("❌ Failed to reset MFA failures for user %s: %v\n", user.ID, err)
+		}
+	}
+
+	accessToken, refreshToken, err := h.issueTokens(h.db, c, user.ID, "")
+	if err != nil {
+		c.JSON(tokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuthWithTokensResponse{
+		User:         user,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	})
+}
+
+// checkSecondFactor accepts a TOTP code for a step newer than the last one
+// used, or burns an unused recovery code.
+func (h *AuthHandler) checkSecondFactor(userID, secret, code string) (bool, error) {
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		result, err := h.db.Exec(`
+			UPDATE users SET totp_last_step = $2
+			WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)
+		`, userID, step)
+		if err != nil {
+			return false, err
+		}
+		n, err := result.RowsAffected()
+		return n == 1, err
+	}
+
+	result, err := h.db.Exec(`
+		UPDATE recovery_codes SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// mfaChallenge mints the short-lived token Login returns instead of a token
+// pair when the account has two-factor authentication enabled.
+func (h *AuthHandler) mfaChallenge(userID string) (string, error) {
+	return h.generateJWT(tokenSpec{UserID: userID, Type: TokenTypeMFA, TTL: mfaChallengeTTL})
+}
+
+// replaceRecoveryCodes discards the user's recovery codes and stores a new
+// set, returning the plaintext codes.
+func replaceRecoveryCodes(tx *sql.Tx, userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+	// Hex hashes never contain commas
+	if _, err := tx.Exec(`
+		INSERT INTO recovery_codes (user_id, code_hash)
+		SELECT $1, unnest(string_to_array($2, ','))
+	`, userID, strings.Join(hashes, ",")); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// newRecoveryCode returns a random code like "k3v9q-x2m7p" (50 bits).
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	s := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))
+	return s[:5] + "-" + s[5:10], nil
+}
+
+// normalizeRecoveryCode ignores case, dashes and spaces in typed codes.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// totpIssuer names the account in authenticator apps (TOTP_ISSUER).
+func totpIssuer() string {
+	if iss := os.Getenv("TOTP_ISSUER"); iss != "" {
+		return iss
+	}
+	return "Personal Assistant"
+}
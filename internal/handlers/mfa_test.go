@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/totp"
+)
+
+var testTOTPSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+// mockParseMFAToken accepts "mfa-ok" as an MFA challenge for user123
+func mockParseMFAToken(token, tokenType string) (*Claims, error) {
+	if token != "mfa-ok" || tokenType != TokenTypeMFA {
+		return nil, errors.New("invalid token")
+	}
+	return &Claims{UserID: "user123", TokenType: tokenType}, nil
+}
+
+// setupMFARouter mounts the TOTP endpoints for user123 and the public /login/mfa
+func setupMFARouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	h := &AuthHandler{
+		db:            db,
+		generateJWT:   mockGenerateJWT,
+		parseJWT:      mockParseMFAToken,
+		getAccessTTL:  mockGetAccessTTL,
+		getRefreshTTL: mockGetRefreshTTL,
+	}
+
+	r := gin.Default()
+	r.POST("/login/mfa", h.LoginMFA)
+
+	authed := r.Group("/", func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+	authed.POST("/me/mfa/totp", h.EnrollTOTP)
+	authed.POST("/me/mfa/totp/confirm", h.ConfirmTOTP)
+	authed.DELETE("/me/mfa/totp", h.DisableTOTP)
+	return r, mock
+}
+
+func currentCode(t *testing.T) string {
+	code, err := totp.Code(testTOTPSecret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("failed to compute code: %v", err)
+	}
+	return code
+}
+
+const mfaUserQuery = `SELECT id, first_name, last_name, email, phone_number, created_at, totp_secret FROM users WHERE id = \$1 AND totp_enabled_at IS NOT NULL`
+
+var mfaUserColumns = []string{"id", "first_name", "last_name", "email", "phone_number", "created_at", "totp_secret"}
+
+// --- TESTS ---
+
+func TestEnrollTOTP_Success(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	mock.ExpectQuery(`UPDATE users SET totp_secret = \$2 WHERE id = \$1 AND totp_enabled_at IS NULL RETURNING email`).
+		WithArgs("user123", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("jane@example.com"))
+
+	w := serve(router, "POST", "/me/mfa/totp")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.TOTPEnrollResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Secret, 32)
+	assert.True(t, strings.HasPrefix(resp.OTPAuthURI, "otpauth://totp/"))
+	assert.Contains(t, resp.OTPAuthURI, "jane@example.com")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnrollTOTP_AlreadyEnabled(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	mock.ExpectQuery(`UPDATE users SET totp_secret`).WillReturnError(sql.ErrNoRows)
+
+	w := serve(router, "POST", "/me/mfa/totp")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConfirmTOTP_Success(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT totp_secret, totp_enabled_at IS NOT NULL FROM users WHERE id = \$1 FOR UPDATE`).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"totp_secret", "enabled"}).AddRow(testTOTPSecret, false))
+	mock.ExpectExec(`UPDATE users SET totp_enabled_at = now\(\), totp_last_step = \$2 WHERE id = \$1`).
+		WithArgs("user123", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM recovery_codes WHERE user_id = \$1`).
+		WithArgs("user123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO recovery_codes \(user_id, code_hash\) SELECT \$1, unnest\(string_to_array\(\$2, ','\)\)`).
+		WithArgs("user123", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, recoveryCodeCount))
+	mock.ExpectCommit()
+
+	w := postJSON(router, "/me/mfa/totp/confirm", `{"code":"`+currentCode(t)+`"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.RecoveryCodesResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.RecoveryCodes, recoveryCodeCount)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, resp.RecoveryCodes[0])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConfirmTOTP_InvalidCode(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT totp_secret`).
+		WillReturnRows(sqlmock.NewRows([]string{"totp_secret", "enabled"}).AddRow(testTOTPSecret, false))
+	mock.ExpectRollback()
+
+	w := postJSON(router, "/me/mfa/totp/confirm", `{"code":"abcdef"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid code")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConfirmTOTP_NoPendingEnrollment(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT totp_secret`).
+		WillReturnRows(sqlmock.NewRows([]string{"totp_secret", "enabled"}).AddRow(nil, false))
+	mock.ExpectRollback()
+
+	w := postJSON(router, "/me/mfa/totp/confirm", `{"code":"123456"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "no pending enrollment")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDisableTOTP_Success(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("supersecret"), bcrypt.MinCost)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT password_hash FROM users WHERE id = \$1 FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"password_hash"}).AddRow(string(hash)))
+	mock.ExpectExec(`UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL WHERE id = \$1`).
+		WithArgs("user123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM recovery_codes WHERE user_id = \$1`).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectCommit()
+
+	w := serveJSON(router, "DELETE", "/me/mfa/totp", `{"password":"supersecret"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDisableTOTP_WrongPassword(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("supersecret"), bcrypt.MinCost)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT password_hash FROM users`).
+		WillReturnRows(sqlmock.NewRows([]string{"password_hash"}).AddRow(string(hash)))
+	mock.ExpectRollback()
+
+	w := serveJSON(router, "DELETE", "/me/mfa/totp", `{"password":"guess"}`)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginMFA_TOTPCode(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	mock.ExpectQuery(mfaUserQuery).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows(mfaUserColumns).
+			AddRow("user123", "Jane", "Doe", "jane@example.com", "", time.Now(), testTOTPSecret))
+	mock.ExpectExec(`UPDATE users SET totp_last_step = \$2 WHERE id = \$1 AND \(totp_last_step IS NULL OR totp_last_step < \$2\)`).
+		WithArgs("user123", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectSessionInsert(mock, "user123", "")
+
+	w := postJSON(router, "/login/mfa", `{"mfa_token":"mfa-ok","code":"`+currentCode(t)+`"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.AuthWithTokensResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "mock-access-user123", resp.AccessToken)
+	assert.Equal(t, "mock-refresh-user123", resp.RefreshToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginMFA_ReplayedCode(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	mock.ExpectQuery(mfaUserQuery).
+		WillReturnRows(sqlmock.NewRows(mfaUserColumns).
+			AddRow("user123", "Jane", "Doe", "jane@example.com", "", time.Now(), testTOTPSecret))
+	// The step was already used
+	mock.ExpectExec(`UPDATE users SET totp_last_step`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := postJSON(router, "/login/mfa", `{"mfa_token":"mfa-ok","code":"`+currentCode(t)+`"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid code")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginMFA_RecoveryCode(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	mock.ExpectQuery(mfaUserQuery).
+		WillReturnRows(sqlmock.NewRows(mfaUserColumns).
+			AddRow("user123", "Jane", "Doe", "jane@example.com", "", time.Now(), testTOTPSecret))
+	mock.ExpectExec(`UPDATE recovery_codes SET used_at = now\(\) WHERE user_id = \$1 AND code_hash = \$2 AND used_at IS NULL`).
+		WithArgs("user123", hashToken("abcdefghij")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectSessionInsert(mock, "user123", "")
+
+	w := postJSON(router, "/login/mfa", `{"mfa_token":"mfa-ok","code":"ABCDE-FGHIJ"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginMFA_UsedRecoveryCode(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	mock.ExpectQuery(mfaUserQuery).
+		WillReturnRows(sqlmock.NewRows(mfaUserColumns).
+			AddRow("user123", "Jane", "Doe", "jane@example.com", "", time.Now(), testTOTPSecret))
+	mock.ExpectExec(`UPDATE recovery_codes`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := postJSON(router, "/login/mfa", `{"mfa_token":"mfa-ok","code":"abcde-fghij"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginMFA_InvalidToken(t *testing.T) {
+	router, mock := setupMFARouter(t)
+
+	w := postJSON(router, "/login/mfa", `{"mfa_token":"valid-refresh","code":"123456"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid or expired mfa token")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecoveryCodeHelpers(t *testing.T) {
+	code, err := newRecoveryCode()
+	assert.NoError(t, err)
+	assert.Len(t, code, 11)
+	assert.Equal(t, normalizeRecoveryCode(code), normalizeRecoveryCode(strings.ToUpper(code)))
+	assert.Equal(t, "abcdefghij", normalizeRecoveryCode(" ABCDE-fghij "))
+}
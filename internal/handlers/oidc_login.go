@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/oidc"
+)
+
+// oidcLoginTTL is how long a user has to finish signing in at the provider
+const oidcLoginTTL = 10 * time.Minute
+
+// Errors from resolveIdentity that map to client errors
+var (
+	errOIDCNoEmail        = errors.New("provider did not share an email address")
+	errOIDCEmailUnproven  = errors.New("an account with this email already exists; sign in with your password")
+	errOIDCEmailDuplicate = errors.New("email already exists")
+)
+
+// ListProviders godoc
+// @Summary List social login providers
+// @Description Returns the names of the configured OpenID Connect providers, for use in /auth/oidc/{provider} routes.
+// @Tags Auth
+// @Produce  json
+// @Success 200 {object} models.OIDCProvidersResponse "Provider names"
+// @Router /auth/oidc/providers [get]
+func (h *OIDCHandler) ListProviders(c *gin.Context) {
+	names := make([]string, 0, len(h.providers))
+	for name := range h.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	c.JSON(http.StatusOK, models.OIDCProvidersResponse{Providers: names})
+}
+
+// Start godoc
+// @Summary Start a social login
+// @Description Begins the authorization code flow with PKCE. Send the user to the returned URL; the provider redirects back to the configured redirect URL with `code` and `state`, which the client posts to the callback. The state expires after 10 minutes.
+// @Tags Auth
+// @Produce  json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} models.OIDCStartResponse "Where to send the user"
+// @Failure 404 {object} map[string]string "Unknown provider"
+// @Failure 500 {object} map[string]string "Database error"
+// @Failure 502 {object} map[string]string "Provider unavailable"
+// @Router /auth/oidc/{provider}/start [post]
+func (h *OIDCHandler) Start(c *gin.Context) {
+	p, ok := h.providers[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown provider"})
+		return
+	}
+
+	// ✅ state ties the callback to this start, nonce ties the ID token to
+	// it, and the PKCE verifier proves we're the ones redeeming the code
+	var state, nonce, verifier string
+	for _, v := range []*string{&state, &nonce, &verifier} {
+		token, err := oidc.RandomToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start login"})
+			return
+		}
+		*v = token
+	}
+
+	authURL, err := p.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Printf("❌ OIDC provider %s unavailable: %v\n", p.Name, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "provider unavailable"})
+		return
+	}
+
+	// ✅ Remember the flow server-side, clearing out abandoned ones
+	_, err = h.auth.db.Exec(`
+		WITH expired AS (DELETE FROM oidc_logins WHERE expires_at < now())
+		INSERT INTO oidc_logins (state_hash, provider, code_verifier, nonce, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, hashToken(state), p.Name, verifier, nonce, time.Now().Add(oidcLoginTTL))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.OIDCStartResponse{AuthorizationURL: authURL, State: state})
+}
+
+// Callback godoc
+// @Summary Finish a social login
+// @Description Redeems the code the provider returned and verifies its ID token. A known provider account signs in its user; otherwise an account with the same email is linked when the provider has verified that email, or a new account is created. Accounts created this way have no password until one is set through /password/forgot. Accounts with two-factor authentication get an MFA challenge, as with /login.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param provider path string true "Provider name"
+// @Param payload body oidcCallbackReq true "Code and state from the provider redirect"
+// @Success 200 {object} models.AuthWithTokensResponse "Signed in to an existing account"
+// @Success 201 {object} models.AuthWithTokensResponse "New account created"
+// @Success 202 {object} models.MFAChallengeResponse "A second factor is required"
+// @Failure 400 {object} map[string]string "Invalid payload, expired state or no email shared"
+// @Failure 401 {object} map[string]string "Provider rejected the code or the ID token is invalid"
+// @Failure 403 {object} map[string]string "Account disabled"
+// @Failure 404 {object} map[string]string "Unknown provider"
+// @Failure 409 {object} map[string]string "Email belongs to an account the provider can't prove ownership of"
+// @Failure 500 {object} map[string]string "Database or token generation error"
+// @Router /auth/oidc/{provider}/callback [post]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req oidcCallbackReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	p, ok := h.providers[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown provider"})
+		return
+	}
+
+	// ✅ Each state is good for one callback, to the provider it started with
+	var verifier, nonce string
+	var live bool
+	err := h.auth.db.QueryRow(`
+		DELETE FROM oidc_logins WHERE state_hash = $1 AND provider = $2
+		RETURNING code_verifier, nonce, expires_at > now()
+	`, hashToken(req.State), p.Name).Scan(&verifier, &nonce, &live)
+	if err == sql.ErrNoRows || err == nil && !live {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired state"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	claims, err := p.Exchange(c.Request.Context(), req.Code, verifier, nonce)
+	if err != nil {
+		log.Printf("❌ OIDC login with %s failed: %v\n", p.Name, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "sign-in with provider failed"})
+		return
+	}
+
+	tx, err := h.auth.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer tx.Rollback()
+
+	userID, created, err := resolveIdentity(tx, p.Issuer, claims)
+	switch {
+	case errors.Is(err, errOIDCNoEmail):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errOIDCEmailUnproven), errors.Is(err, errOIDCEmailDuplicate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("❌ Failed to resolve %s identity %s: %v\n", p.Name, claims.Subject, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	var user models.User
+	var mfaEnabled bool
+	err = tx.QueryRow(`
+		SELECT id, first_name, last_name, email, phone_number, created_at, totp_enabled_at IS NOT NULL
+		FROM users WHERE id = $1
+	`, userID).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.PhoneNumber, &user.CreatedAt, &mfaEnabled)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	// ✅ Two-factor accounts get no tokens until /login/mfa, however they signed in
+	if mfaEnabled {
+		if err := tx.Commit(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		mfaToken, err := h.auth.mfaChallenge(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create mfa token"})
+			return
+		}
+		c.JSON(http.StatusAccepted, models.MFAChallengeResponse{MFARequired: true, MFAToken: mfaToken})
+		return
+	}
+
+	accessToken, refreshToken, err := h.auth.issueTokens(tx, c, user.ID, "")
+	if err != nil {
+		c.JSON(tokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		// An address the provider hasn't verified gets our own verification mail
+		if !claims.Verified() && h.auth.mailer != nil {
+			if err := h.auth.sendVerificationEmail(c.Request.Context(), user.ID, user.Email, user.FirstName); err != nil {
+				log.Printf("❌ Failed to send verification email to user %s: %v\n", user.ID, err)
+			}
+		}
+	}
+
+	c.JSON(status, models.AuthWithTokensResponse{
+		User:         user,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	})
+}
+
+// resolveIdentity finds the user behind a provider account, linking it to
+// the account with the same email or creating a new account on first login.
+func resolveIdentity(tx *sql.Tx, issuer string, claims *oidc.Claims) (userID string, created bool, err error) {
+	// ✅ Known provider account
+	err = tx.QueryRow(`
+		UPDATE identities SET last_login_at = now(), email = $3
+		WHERE issuer = $1 AND subject = $2
+		RETURNING user_id
+	`, issuer, claims.Subject, claims.Email).Scan(&userID)
+	if err != sql.ErrNoRows {
+		return userID, false, err
+	}
+	if claims.Email == "" {
+		return "", false, errOIDCNoEmail
+	}
+
+	err = tx.QueryRow(`SELECT id FROM users WHERE email = $1`, claims.Email).Scan(&userID)
+	switch {
+	case err == nil:
+		// ✅ Only link an existing account on an address the provider vouches
+		// for, or anyone could claim an account by typing its email elsewhere
+		if !claims.Verified() {
+			return "", false, errOIDCEmailUnproven
+		}
+		if _, err := tx.Exec(`
+			UPDATE users SET email_verified_at = COALESCE(email_verified_at, now()) WHERE id = $1
+		`, userID); err != nil {
+			return "", false, err
+		}
+
+	case err == sql.ErrNoRows:
+		// ✅ New account without a password; an empty hash never matches at /login
+		first, last := claims.GivenName, claims.FamilyName
+		if first == "" && last == "" {
+			first, last, _ = strings.Cut(claims.Name, " ")
+		}
+		err = tx.QueryRow(`
+			INSERT INTO users (first_name, last_name, email, password_hash, email_verified_at, email_verification_sent_at)
+			VALUES ($1, $2, $3, '', CASE WHEN $4::boolean THEN now() END, CASE WHEN $4::boolean THEN NULL ELSE now() END)
+			RETURNING id
+		`, first, last, claims.Email, claims.Verified()).Scan(&userID)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return "", false, errOIDCEmailDuplicate
+		}
+		if err != nil {
+			return "", false, err
+		}
+		created = true
+
+	default:
+		return "", false, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO identities (user_id, issuer, subject, email) VALUES ($1, $2, $3, $4)
+	`, userID, issuer, claims.Subject, claims.Email)
+	if err != nil {
+		return "", false, err
+	}
+	return userID, created, nil
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"personal-assistant-backend/internal/mail"
+	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/oidc"
+	"personal-assistant-backend/internal/oidc/oidctest"
+)
+
+// setupOIDCRouter wires an OIDCHandler to a stub issuer named "stub"
+func setupOIDCRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock, *oidctest.Issuer, *mail.MemorySender) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	iss := oidctest.NewIssuer(t)
+	provider := oidc.NewProvider(oidc.Config{
+		Name:         "stub",
+		Issuer:       iss.URL,
+		ClientID:     iss.ClientID,
+		ClientSecret: iss.ClientSecret,
+		RedirectURL:  "https://app.example.com/auth/callback",
+	}, nil)
+
+	mailer := mail.NewMemorySender()
+	auth := &AuthHandler{
+		db:            db,
+		generateJWT:   mockGenerateJWT,
+		getAccessTTL:  mockGetAccessTTL,
+		getRefreshTTL: mockGetRefreshTTL,
+		mailer:        mailer,
+	}
+	h := NewOIDCHandler(auth, map[string]*oidc.Provider{"stub": provider})
+
+	r := gin.Default()
+	r.GET("/auth/oidc/providers", h.ListProviders)
+	r.POST("/auth/oidc/:provider/start", h.Start)
+	r.POST("/auth/oidc/:provider/callback", h.Callback)
+	return r, mock, iss, mailer
+}
+
+const (
+	insertOIDCLoginQuery  = `WITH expired AS \(DELETE FROM oidc_logins WHERE expires_at < now\(\)\) INSERT INTO oidc_logins \(state_hash, provider, code_verifier, nonce, expires_at\) VALUES \(\$1, \$2, \$3, \$4, \$5\)`
+	consumeOIDCLoginQuery = `DELETE FROM oidc_logins WHERE state_hash = \$1 AND provider = \$2 RETURNING code_verifier, nonce, expires_at > now\(\)`
+	touchIdentityQuery    = `UPDATE identities SET last_login_at = now\(\), email = \$3 WHERE issuer = \$1 AND subject = \$2 RETURNING user_id`
+	userByEmailQuery      = `SELECT id FROM users WHERE email = \$1`
+	insertIdentityQuery   = `INSERT INTO identities \(user_id, issuer, subject, email\) VALUES \(\$1, \$2, \$3, \$4\)`
+	oidcUserQuery         = `SELECT id, first_name, last_name, email, phone_number, created_at, totp_enabled_at IS NOT NULL FROM users WHERE id = \$1`
+)
+
+// capture matches any argument and keeps it as a string
+type capture struct{ v *string }
+
+func (a capture) Match(v driver.Value) bool {
+	*a.v, _ = v.(string)
+	return true
+}
+
+// oidcFlow is a login started against the stub, with what the server stored
+type oidcFlow struct {
+	code, state, stateHash, verifier, nonce string
+}
+
+// startOIDC calls /start, then signs in at the stub issuer like a browser would
+func startOIDC(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock, iss *oidctest.Issuer) oidcFlow {
+	var f oidcFlow
+	mock.ExpectExec(insertOIDCLoginQuery).
+		WithArgs(capture{&f.stateHash}, "stub", capture{&f.verifier}, capture{&f.nonce}, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := serve(router, "POST", "/auth/oidc/stub/start")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.OIDCStartResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, hashToken(resp.State), f.stateHash)
+
+	f.code, f.state = iss.Authorize(t, resp.AuthorizationURL)
+	assert.Equal(t, resp.State, f.state)
+	return f
+}
+
+// expectConsume mocks redeeming the stored flow
+func expectConsume(mock sqlmock.Sqlmock, f oidcFlow) {
+	mock.ExpectQuery(consumeOIDCLoginQuery).
+		WithArgs(f.stateHash, "stub").
+		WillReturnRows(sqlmock.NewRows([]string{"code_verifier", "nonce", "live"}).AddRow(f.verifier, f.nonce, true))
+}
+
+func expectOIDCUser(mock sqlmock.Sqlmock, mfa bool) {
+	mock.ExpectQuery(oidcUserQuery).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "first_name", "last_name", "email", "phone_number", "created_at", "mfa",
+		}).AddRow("user-1", "Jane", "Doe", "jane@example.com", "", time.Now(), mfa))
+}
+
+func finishOIDC(router *gin.Engine, f oidcFlow) *httptest.ResponseRecorder {
+	return serveJSON(router, "POST", "/auth/oidc/stub/callback", `{"code":"`+f.code+`","state":"`+f.state+`"}`)
+}
+
+// --- TESTS ---
+
+func TestOIDC_ListProviders(t *testing.T) {
+	router, _, _, _ := setupOIDCRouter(t)
+
+	w := serve(router, "GET", "/auth/oidc/providers")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"providers":["stub"]}`, w.Body.String())
+}
+
+func TestOIDC_UnknownProvider(t *testing.T) {
+	router, _, _, _ := setupOIDCRouter(t)
+
+	assert.Equal(t, http.StatusNotFound, serve(router, "POST", "/auth/oidc/nope/start").Code)
+}
+
+func TestOIDC_NewUser(t *testing.T) {
+	router, mock, iss, mailer := setupOIDCRouter(t)
+	f := startOIDC(t, router, mock, iss)
+
+	expectConsume(mock, f)
+	mock.ExpectBegin()
+	mock.ExpectQuery(touchIdentityQuery).
+		WithArgs(iss.URL, "subject-1", "jane@example.com").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(userByEmailQuery).WithArgs("jane@example.com").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`INSERT INTO users \(first_name, last_name, email, password_hash, email_verified_at, email_verification_sent_at\)`).
+		WithArgs("Jane", "Doe", "jane@example.com", true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("user-1"))
+	mock.ExpectExec(insertIdentityQuery).
+		WithArgs("user-1", iss.URL, "subject-1", "jane@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectOIDCUser(mock, false)
+	expectSessionInsert(mock, "user-1", "")
+	mock.ExpectCommit()
+
+	w := finishOIDC(router, f)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var resp models.AuthWithTokensResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "user-1", resp.User.ID)
+	assert.Equal(t, "mock-access-user-1", resp.AccessToken)
+	// The provider verified the address, so no mail of our own
+	assert.Empty(t, mailer.Sent())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOIDC_ReturningUserWithMFA(t *testing.T) {
+	router, mock, iss, _ := setupOIDCRouter(t)
+	f := startOIDC(t, router, mock, iss)
+
+	expectConsume(mock, f)
+	mock.ExpectBegin()
+	mock.ExpectQuery(touchIdentityQuery).
+		WithArgs(iss.URL, "subject-1", "jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user-1"))
+	expectOIDCUser(mock, true)
+	mock.ExpectCommit()
+
+	w := finishOIDC(router, f)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), "mock-mfa-user-1")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOIDC_LinksVerifiedEmail(t *testing.T) {
+	router, mock, iss, _ := setupOIDCRouter(t)
+	f := startOIDC(t, router, mock, iss)
+
+	expectConsume(mock, f)
+	mock.ExpectBegin()
+	mock.ExpectQuery(touchIdentityQuery).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(userByEmailQuery).
+		WithArgs("jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("user-1"))
+	mock.ExpectExec(`UPDATE users SET email_verified_at = COALESCE\(email_verified_at, now\(\)\) WHERE id = \$1`).
+		WithArgs("user-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insertIdentityQuery).
+		WithArgs("user-1", iss.URL, "subject-1", "jane@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectOIDCUser(mock, false)
+	expectSessionInsert(mock, "user-1", "")
+	mock.ExpectCommit()
+
+	w := finishOIDC(router, f)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOIDC_RefusesToLinkUnverifiedEmail(t *testing.T) {
+	router, mock, iss, _ := setupOIDCRouter(t)
+	iss.User.EmailVerified = false
+	f := startOIDC(t, router, mock, iss)
+
+	expectConsume(mock, f)
+	mock.ExpectBegin()
+	mock.ExpectQuery(touchIdentityQuery).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(userByEmailQuery).
+		WithArgs("jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("user-1"))
+	mock.ExpectRollback()
+
+	w := finishOIDC(router, f)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOIDC_NewUserEmailTakenConcurrently(t *testing.T) {
+	router, mock, iss, _ := setupOIDCRouter(t)
+	f := startOIDC(t, router, mock, iss)
+
+	expectConsume(mock, f)
+	mock.ExpectBegin()
+	mock.ExpectQuery(touchIdentityQuery).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(userByEmailQuery).WithArgs("jane@example.com").WillReturnError(sql.ErrNoRows)
+	// Another signup claimed the address between the lookup and the insert
+	mock.ExpectQuery(`INSERT INTO users`).WillReturnError(&pgconn.PgError{Code: "23505"})
+	mock.ExpectRollback()
+
+	w := finishOIDC(router, f)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "email already exists")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOIDC_UnknownOrExpiredState(t *testing.T) {
+	router, mock, iss, _ := setupOIDCRouter(t)
+	f := startOIDC(t, router, mock, iss)
+
+	mock.ExpectQuery(consumeOIDCLoginQuery).
+		WithArgs(f.stateHash, "stub").
+		WillReturnRows(sqlmock.NewRows([]string{"code_verifier", "nonce", "live"}).AddRow(f.verifier, f.nonce, false))
+	mock.ExpectQuery(consumeOIDCLoginQuery).
+		WithArgs(hashToken("forged"), "stub").
+		WillReturnError(sql.ErrNoRows)
+
+	assert.Equal(t, http.StatusBadRequest, finishOIDC(router, f).Code)
+	f.state = "forged"
+	assert.Equal(t, http.StatusBadRequest, finishOIDC(router, f).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOIDC_RejectedCode(t *testing.T) {
+	router, mock, iss, _ := setupOIDCRouter(t)
+	f := startOIDC(t, router, mock, iss)
+
+	// A verifier that doesn't match the challenge is refused by the issuer
+	f.verifier = "stolen-code-without-verifier"
+	expectConsume(mock, f)
+
+	w := finishOIDC(router, f)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/mail"
+)
+
+// forgotPasswordMessage is returned whether or not the email is registered,
+// so the endpoint can't be used to probe for accounts.
+const forgotPasswordMessage = "If that email is registered, a reset link has been sent"
+
+// forgotPasswordTimeout bounds the background lookup and mail send.
+const forgotPasswordTimeout = time.Minute
+
+// ForgotPassword godoc
+// @Summary Request a password reset email
+// @Description Emails a single-use password reset link to the account, if one exists. The response is the same, and just as fast, for unknown emails. Requests are limited per email and per client IP whether or not the account exists.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param payload body forgotPasswordReq true "Account email"
+// @Success 202 {object} map[string]string "Reset email sent if the account exists"
+// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 429 {object} map[string]string "Too many reset requests; see Retry-After"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req forgotPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	// ✅ Counted before the account lookup, so limits don't reveal which emails exist
+	if h.throttle != nil {
+		wait, err := h.throttle.retryAfter(resetEmailKey(req.Email), resetIPKey(c.ClientIP()))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if wait > 0 {
+			tooSoon(c, wait)
+			return
+		}
+		h.throttle.resetRequested(req.Email, c.ClientIP())
+	}
+
+	// ✅ Answer before touching the account; waiting on SMTP only for real
+	// accounts would let response times reveal which emails are registered
+	email := req.Email
+	h.runBackground(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), forgotPasswordTimeout)
+		defer cancel()
+
+		if err := h.sendPasswordReset(ctx, email); err != nil {
+			log.Printf("❌ Failed to send password reset email: %v\n", err)
+		}
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{"message": forgotPasswordMessage})
+}
+
+// sendPasswordReset issues a reset token for the account with email, if
+// there is one, and mails it the link.
+func (h *AuthHandler) sendPasswordReset(ctx context.Context, email string) error {
+	var userID, firstName string
+	err := h.db.QueryRowContext(ctx, `SELECT id, first_name FROM users WHERE email=$1`, email).
+		Scan(&userID, &firstName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := newResetToken()
+	if err != nil {
+		return err
+	}
+
+	// ✅ A new link supersedes any earlier one still outstanding
+	ttl := getPasswordResetTTL()
+	_, err = h.db.ExecContext(ctx, `
+		WITH superseded AS (
+			UPDATE password_resets SET used_at = now()
+			WHERE user_id = $1 AND used_at IS NULL
+		)
+		INSERT INTO password_resets (token_hash, user_id, expires_at)
+		VALUES ($2, $1, $3)
+	`, userID, hashToken(token), time.Now().Add(ttl))
+	if err != nil {
+		return err
+	}
+
+	return h.mailer.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes and works once.\n\n%s\n\nIf you didn't ask for this, you can ignore this email.\n",
+			firstName, int(ttl.Minutes()), tokenLink("PASSWORD_RESET_URL", token)),
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password with an emailed token
+// @Description Sets a new password using a reset token from /password/forgot. Tokens expire and work once; every existing session of the user is revoked.
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param payload body resetPasswordReq true "Reset token and new password"
+// @Success 200 {object} map[string]string "Password updated"
+// @Failure 400 {object} map[string]string "Invalid payload or invalid/expired token"
+// @Failure 500 {object} map[string]string "Database or hashing error"
+// @Router /password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req resetPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	hash, err := hashPassword(h.hasher(), req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
+		return
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer tx.Rollback()
+
+	// ✅ Claiming the token and changing the password commit together
+	var userID string
+	err = tx.QueryRow(`
+		UPDATE password_resets SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING user_id
+	`, hashToken(req.Token)).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET password_hash = $2 WHERE id = $1`, userID, hash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	// Whoever knew the old password may still hold a session
+	if _, err := tx.Exec(`
+		UPDATE sessions SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
+
+// newResetToken returns a random URL-safe reset token.
+func newResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is what gets stored for single-use secrets (reset tokens, recovery
+// codes), so a database leak can't be replayed.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenLink appends the token to the frontend URL configured in env (e.g.
+// PASSWORD_RESET_URL), or returns the bare token when none is configured.
+func tokenLink(env, token string) string {
+	base := os.Getenv(env)
+	if base == "" {
+		return "Token: " + token
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "Token: " + token
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
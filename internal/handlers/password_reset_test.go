@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"personal-assistant-backend/internal/mail"
+	"personal-assistant-backend/internal/password"
+)
+
+// setupPasswordResetRouter mounts the reset endpoints with an in-memory mailer
+func setupPasswordResetRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock, *mail.MemorySender) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	mailer := mail.NewMemorySender()
+	h := NewAuthHandler(db, mailer, nil)
+	h.background = func(task func()) { task() }
+
+	r := gin.Default()
+	r.POST("/password/forgot", h.ForgotPassword)
+	r.POST("/password/reset", h.ResetPassword)
+	return r, mock, mailer
+}
+
+func postJSON(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	return serveJSON(router, "POST", path, body)
+}
+
+// expectResetAllowed mocks the throttle check and counting for a reset request
+func expectResetAllowed(mock sqlmock.Sqlmock, email string) {
+	mock.ExpectQuery(retryAfterQuery).
+		WithArgs("reset:"+email, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"seconds"}).AddRow(0))
+	mock.ExpectQuery(recordFailureQuery).
+		WithArgs("reset:"+email, float64(3600)).
+		WillReturnRows(sqlmock.NewRows([]string{"failures"}).AddRow(1))
+	mock.ExpectQuery(recordFailureQuery).
+		WithArgs(sqlmock.AnyArg(), float64(3600)).
+		WillReturnRows(sqlmock.NewRows([]string{"failures"}).AddRow(1))
+}
+
+const claimResetQuery = `UPDATE password_resets SET used_at = now\(\) WHERE token_hash = \$1 AND used_at IS NULL AND expires_at > now\(\) RETURNING user_id`
+
+// --- TESTS ---
+
+func TestForgotPassword_SendsResetLink(t *testing.T) {
+	t.Setenv("PASSWORD_RESET_URL", "https://app.example.com/reset?lang=en")
+	router, mock, mailer := setupPasswordResetRouter(t)
+
+	expectResetAllowed(mock, "jane@example.com")
+	mock.ExpectQuery(`SELECT id, first_name FROM users WHERE email=\$1`).
+		WithArgs("jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name"}).AddRow("user123", "Jane"))
+	mock.ExpectExec(`WITH superseded AS \( UPDATE password_resets SET used_at = now\(\) WHERE user_id = \$1 AND used_at IS NULL \) INSERT INTO password_resets`).
+		WithArgs("user123", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := postJSON(router, "/password/forgot", `{"email":"jane@example.com"}`)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), forgotPasswordMessage)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	sent := mailer.Sent()
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, "jane@example.com", sent[0].To)
+		assert.Contains(t, sent[0].Body, "Hi Jane")
+		assert.Contains(t, sent[0].Body, "https://app.example.com/reset?lang=en&token=")
+	}
+}
+
+func TestForgotPassword_UnknownEmail(t *testing.T) {
+	router, mock, mailer := setupPasswordResetRouter(t)
+
+	expectResetAllowed(mock, "ghost@example.com")
+	mock.ExpectQuery(`SELECT id, first_name FROM users`).
+		WithArgs("ghost@example.com").
+		WillReturnError(sql.ErrNoRows)
+
+	w := postJSON(router, "/password/forgot", `{"email":"ghost@example.com"}`)
+
+	// Same answer as a registered email
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), forgotPasswordMessage)
+	assert.Empty(t, mailer.Sent())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForgotPassword_MailFailureIsHidden(t *testing.T) {
+	router, mock, mailer := setupPasswordResetRouter(t)
+	mailer.Err = errors.New("smtp down")
+
+	expectResetAllowed(mock, "jane@example.com")
+	mock.ExpectQuery(`SELECT id, first_name FROM users`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name"}).AddRow("user123", "Jane"))
+	mock.ExpectExec(`INSERT INTO password_resets`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := postJSON(router, "/password/forgot", `{"email":"jane@example.com"}`)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForgotPassword_InvalidPayload(t *testing.T) {
+	router, _, _ := setupPasswordResetRouter(t)
+
+	w := postJSON(router, "/password/forgot", `{"email":"not-an-email"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid payload")
+}
+
+func TestForgotPassword_DBErrorIsHidden(t *testing.T) {
+	router, mock, mailer := setupPasswordResetRouter(t)
+
+	expectResetAllowed(mock, "jane@example.com")
+	mock.ExpectQuery(`SELECT id, first_name FROM users`).WillReturnError(errors.New("db exploded"))
+
+	w := postJSON(router, "/password/forgot", `{"email":"jane@example.com"}`)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Empty(t, mailer.Sent())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForgotPassword_AnswersBeforeLookingUpAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	// Hold the background work so the response can't depend on it
+	var pending []func()
+	h := NewAuthHandler(db, mail.NewMemorySender(), nil)
+	h.background = func(task func()) { pending = append(pending, task) }
+	r := gin.Default()
+	r.POST("/password/forgot", h.ForgotPassword)
+
+	expectResetAllowed(mock, "jane@example.com")
+
+	w := postJSON(r, "/password/forgot", `{"email":"jane@example.com"}`)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Len(t, pending, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestForgotPassword_Throttled(t *testing.T) {
+	router, mock, mailer := setupPasswordResetRouter(t)
+
+	mock.ExpectQuery(retryAfterQuery).
+		WithArgs("reset:jane@example.com", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"seconds"}).AddRow(299.2))
+
+	w := postJSON(router, "/password/forgot", `{"email":"Jane@Example.com"}`)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "300", w.Header().Get("Retry-After"))
+	assert.Empty(t, mailer.Sent())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResetPassword_Success(t *testing.T) {
+	router, mock, _ := setupPasswordResetRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(claimResetQuery).
+		WithArgs(hashToken("reset-token")).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user123"))
+	mock.ExpectExec(`UPDATE users SET password_hash = \$2 WHERE id = \$1`).
+		WithArgs("user123", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE sessions SET revoked_at = now\(\) WHERE user_id = \$1 AND revoked_at IS NULL`).
+		WithArgs("user123").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	w := postJSON(router, "/password/reset", `{"token":"reset-token","password":"brand-new-pass"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Password has been reset")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResetPassword_InvalidOrUsedToken(t *testing.T) {
+	router, mock, _ := setupPasswordResetRouter(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(claimResetQuery).WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	w := postJSON(router, "/password/reset", `{"token":"used-token","password":"brand-new-pass"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid or expired token")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResetPassword_ShortPassword(t *testing.T) {
+	router, mock, _ := setupPasswordResetRouter(t)
+
+	w := postJSON(router, "/password/reset", `{"token":"reset-token","password":"short"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid payload")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResetPassword_HashError(t *testing.T) {
+	oldHash := hashPassword
+	hashPassword = func(*password.Hasher, string) (string, error) {
+		return "", errors.New("hash fail")
+	}
+	defer func() { hashPassword = oldHash }()
+
+	router, _, _ := setupPasswordResetRouter(t)
+
+	w := postJSON(router, "/password/reset", `{"token":"reset-token","password":"brand-new-pass"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "failed to hash password")
+}
+
+func TestResetTokenHelpers(t *testing.T) {
+	a, err := newResetToken()
+	assert.NoError(t, err)
+	b, _ := newResetToken()
+	assert.NotEqual(t, a, b)
+	assert.Len(t, hashToken(a), 64)
+	assert.Equal(t, hashToken(a), hashToken(a))
+
+	t.Setenv("PASSWORD_RESET_URL", "")
+	assert.Equal(t, "Token: abc", tokenLink("PASSWORD_RESET_URL", "abc"))
+
+	t.Setenv("PASSWORD_RESET_URL", "https://app.example.com/reset")
+	u, _ := url.Parse(tokenLink("PASSWORD_RESET_URL", "abc"))
+	assert.Equal(t, "abc", u.Query().Get("token"))
+
+	t.Setenv("PASSWORD_RESET_TTL_MINUTES", "")
+	assert.Equal(t, time.Hour, getPasswordResetTTL())
+	t.Setenv("PASSWORD_RESET_TTL_MINUTES", "15")
+	assert.Equal(t, 15*time.Minute, getPasswordResetTTL())
+}
@@ -0,0 +1,51 @@
+package persona
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// CreatePersona godoc
+// @Summary Create a persona
+// @Description Creates a named system prompt the logged-in user can attach to chats.
+// @Tags Personas
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param payload body models.CreatePersonaReq true "Persona name and system prompt"
+// @Success 201 {object} models.PersonaResponse
+// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /personas [post]
+func (h *PersonaHandler) CreatePersona(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req models.CreatePersonaReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var persona models.Persona
+	err := h.DB.QueryRow(`
+		INSERT INTO personas (user_id, name, system_prompt)
+		VALUES ($1, $2, $3)
+		RETURNING id, name, system_prompt, created_at, updated_at
+	`, userID, req.Name, req.SystemPrompt).Scan(
+		&persona.ID, &persona.Name, &persona.SystemPrompt, &persona.CreatedAt, &persona.UpdatedAt,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "db error",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.PersonaResponse{Persona: persona})
+}
@@ -0,0 +1,100 @@
+package persona
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"personal-assistant-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// personaRowColumns matches the columns every persona query returns
+var personaRowColumns = []string{"id", "name", "system_prompt", "created_at", "updated_at"}
+
+// setupPersonaRouter sets up Gin + sqlmock for PersonaHandler
+func setupPersonaRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	h := NewPersonaHandler(db)
+	r := gin.Default()
+
+	// Fake userID in context
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+
+	r.POST("/personas", h.CreatePersona)
+	r.GET("/personas", h.ListPersonas)
+	r.GET("/personas/:persona_id", h.GetPersona)
+	r.PATCH("/personas/:persona_id", h.UpdatePersona)
+	r.DELETE("/personas/:persona_id", h.DeletePersona)
+	return r, mock
+}
+
+// --- TESTS ---
+
+func TestCreatePersona_Success(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	now := time.Now()
+	mock.ExpectQuery(`INSERT INTO personas \(user_id, name, system_prompt\) VALUES \(\$1, \$2, \$3\)`).
+		WithArgs("user123", "Chef", "You are a chef.").
+		WillReturnRows(sqlmock.NewRows(personaRowColumns).
+			AddRow("p-1", "Chef", "You are a chef.", now, now))
+
+	body := `{"name":"Chef","system_prompt":"You are a chef."}`
+	req, _ := http.NewRequest("POST", "/personas", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var resp models.PersonaResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "p-1", resp.Persona.ID)
+	assert.Equal(t, "You are a chef.", resp.Persona.SystemPrompt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreatePersona_MissingPrompt(t *testing.T) {
+	router, _ := setupPersonaRouter(t)
+
+	req, _ := http.NewRequest("POST", "/personas", strings.NewReader(`{"name":"Chef"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid payload")
+}
+
+func TestCreatePersona_DBError(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	mock.ExpectQuery(`INSERT INTO personas`).WillReturnError(errors.New("db exploded"))
+
+	body := `{"name":"Chef","system_prompt":"You are a chef."}`
+	req, _ := http.NewRequest("POST", "/personas", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "db error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package persona
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeletePersona godoc
+// @Summary Delete a persona
+// @Description Deletes a persona if it belongs to the logged-in user. Chats using it fall back to the default system prompt.
+// @Tags Personas
+// @Security BearerAuth
+// @Produce json
+// @Param persona_id path string true "Persona ID"
+// @Success 200 {object} map[string]string "Persona deleted"
+// @Failure 404 {object} map[string]string "Persona not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /personas/{persona_id} [delete]
+func (h *PersonaHandler) DeletePersona(c *gin.Context) {
+	userID := c.GetString("userID")
+	personaID := c.Param("persona_id")
+
+	result, err := h.DB.Exec(`
+		DELETE FROM personas
+		WHERE id = $1 AND user_id = $2
+	`, personaID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "db error",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "db error",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "persona not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Persona deleted"})
+}
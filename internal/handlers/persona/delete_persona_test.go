@@ -0,0 +1,42 @@
+package persona
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeletePersona_Success(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	mock.ExpectExec(`DELETE FROM personas WHERE id = \$1 AND user_id = \$2`).
+		WithArgs("p-1", "user123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	req, _ := http.NewRequest("DELETE", "/personas/p-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Persona deleted")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeletePersona_NotFound(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	mock.ExpectExec(`DELETE FROM personas WHERE id = \$1 AND user_id = \$2`).
+		WithArgs("missing", "user123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req, _ := http.NewRequest("DELETE", "/personas/missing", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "persona not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,45 @@
+package persona
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// GetPersona godoc
+// @Summary Get a persona
+// @Description Returns a single persona if it belongs to the logged-in user.
+// @Tags Personas
+// @Security BearerAuth
+// @Produce json
+// @Param persona_id path string true "Persona ID"
+// @Success 200 {object} models.PersonaResponse
+// @Failure 404 {object} map[string]string "Persona not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /personas/{persona_id} [get]
+func (h *PersonaHandler) GetPersona(c *gin.Context) {
+	userID := c.GetString("userID")
+	personaID := c.Param("persona_id")
+
+	var persona models.Persona
+	err := h.DB.QueryRow(`
+		SELECT id, name, system_prompt, created_at, updated_at
+		FROM personas
+		WHERE id = $1 AND user_id = $2
+	`, personaID, userID).Scan(
+		&persona.ID, &persona.Name, &persona.SystemPrompt, &persona.CreatedAt, &persona.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "persona not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PersonaResponse{Persona: persona})
+}
@@ -0,0 +1,45 @@
+package persona
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPersona_Success(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, name, system_prompt, created_at, updated_at FROM personas WHERE id = \$1 AND user_id = \$2`).
+		WithArgs("p-1", "user123").
+		WillReturnRows(sqlmock.NewRows(personaRowColumns).
+			AddRow("p-1", "Chef", "You are a chef.", now, now))
+
+	req, _ := http.NewRequest("GET", "/personas/p-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "You are a chef.")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPersona_NotFound(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	mock.ExpectQuery(`SELECT id, name, system_prompt, created_at, updated_at FROM personas`).
+		WithArgs("someone-elses", "user123").
+		WillReturnError(sql.ErrNoRows)
+
+	req, _ := http.NewRequest("GET", "/personas/someone-elses", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "persona not found")
+}
@@ -0,0 +1,45 @@
+package persona
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// ListPersonas godoc
+// @Summary List personas for current user
+// @Description Returns every persona created by the logged-in user, newest first.
+// @Tags Personas
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.PersonaListResponse
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /personas [get]
+func (h *PersonaHandler) ListPersonas(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	rows, err := h.DB.Query(`
+		SELECT id, name, system_prompt, created_at, updated_at
+		FROM personas
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer rows.Close()
+
+	personas := []models.Persona{}
+	for rows.Next() {
+		var p models.Persona
+		if err := rows.Scan(&p.ID, &p.Name, &p.SystemPrompt, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan error"})
+			return
+		}
+		personas = append(personas, p)
+	}
+
+	c.JSON(http.StatusOK, models.PersonaListResponse{Personas: personas})
+}
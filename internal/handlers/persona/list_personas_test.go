@@ -0,0 +1,68 @@
+package persona
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"personal-assistant-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPersonas_Success(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, name, system_prompt, created_at, updated_at FROM personas WHERE user_id = \$1 ORDER BY created_at DESC`).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows(personaRowColumns).
+			AddRow("p-2", "Tutor", "Teach patiently.", now, now).
+			AddRow("p-1", "Chef", "You are a chef.", now.Add(-time.Hour), now))
+
+	req, _ := http.NewRequest("GET", "/personas", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.PersonaListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Personas, 2)
+	assert.Equal(t, "Tutor", resp.Personas[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListPersonas_Empty(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	mock.ExpectQuery(`SELECT id, name, system_prompt, created_at, updated_at FROM personas`).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows(personaRowColumns))
+
+	req, _ := http.NewRequest("GET", "/personas", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"personas":[]}`, w.Body.String())
+}
+
+func TestListPersonas_DBError(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	mock.ExpectQuery(`SELECT id, name, system_prompt, created_at, updated_at FROM personas`).
+		WithArgs("user123").
+		WillReturnError(errors.New("db exploded"))
+
+	req, _ := http.NewRequest("GET", "/personas", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "db error")
+}
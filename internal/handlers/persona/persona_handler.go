@@ -0,0 +1,11 @@
+package persona
+
+import "database/sql"
+
+type PersonaHandler struct {
+	DB *sql.DB
+}
+
+func NewPersonaHandler(db *sql.DB) *PersonaHandler {
+	return &PersonaHandler{DB: db}
+}
@@ -0,0 +1,63 @@
+package persona
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/models"
+)
+
+// UpdatePersona godoc
+// @Summary Update a persona
+// @Description Renames a persona or changes its system prompt. Omitted fields keep their current value.
+// @Tags Personas
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param persona_id path string true "Persona ID"
+// @Param payload body models.UpdatePersonaReq true "Fields to change"
+// @Success 200 {object} models.PersonaResponse
+// @Failure 400 {object} map[string]string "Invalid payload"
+// @Failure 404 {object} map[string]string "Persona not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /personas/{persona_id} [patch]
+func (h *PersonaHandler) UpdatePersona(c *gin.Context) {
+	userID := c.GetString("userID")
+	personaID := c.Param("persona_id")
+
+	var req models.UpdatePersonaReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid payload",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var persona models.Persona
+	err := h.DB.QueryRow(`
+		UPDATE personas
+		SET name = COALESCE($3, name),
+			system_prompt = COALESCE($4, system_prompt),
+			updated_at = now()
+		WHERE id = $1 AND user_id = $2
+		RETURNING id, name, system_prompt, created_at, updated_at
+	`, personaID, userID, req.Name, req.SystemPrompt).Scan(
+		&persona.ID, &persona.Name, &persona.SystemPrompt, &persona.CreatedAt, &persona.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "persona not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "db error",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.PersonaResponse{Persona: persona})
+}
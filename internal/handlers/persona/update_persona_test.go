@@ -0,0 +1,59 @@
+package persona
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdatePersona_Success(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	now := time.Now()
+	prompt := "You are a pastry chef."
+	mock.ExpectQuery(`UPDATE personas SET name = COALESCE\(\$3, name\), system_prompt = COALESCE\(\$4, system_prompt\)`).
+		WithArgs("p-1", "user123", nil, &prompt).
+		WillReturnRows(sqlmock.NewRows(personaRowColumns).
+			AddRow("p-1", "Chef", prompt, now, now))
+
+	req, _ := http.NewRequest("PATCH", "/personas/p-1", strings.NewReader(`{"system_prompt":"You are a pastry chef."}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "pastry chef")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePersona_EmptyName(t *testing.T) {
+	router, _ := setupPersonaRouter(t)
+
+	req, _ := http.NewRequest("PATCH", "/personas/p-1", strings.NewReader(`{"name":""}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid payload")
+}
+
+func TestUpdatePersona_NotFound(t *testing.T) {
+	router, mock := setupPersonaRouter(t)
+
+	mock.ExpectQuery(`UPDATE personas`).WillReturnError(sql.ErrNoRows)
+
+	req, _ := http.NewRequest("PATCH", "/personas/missing", strings.NewReader(`{"name":"New"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "persona not found")
+}
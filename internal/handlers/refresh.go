@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -8,15 +10,16 @@ import (
 
 // Refresh godoc
 // @Summary Refresh access token
-// @Description Takes a valid refresh token and issues a new access token. The refresh token remains the same.
+// @Description Exchanges a valid refresh token for a new access + refresh token pair. Each refresh token works once; replaying a used one revokes the whole session. Access tokens are rejected.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
 // @Param payload body RefreshRequest true "Refresh token payload"
-// @Success 200 {object} map[string]string "New access token issued"
+// @Success 200 {object} map[string]string "New access and refresh tokens issued"
 // @Failure 400 {object} map[string]string "Invalid payload"
 // @Failure 401 {object} map[string]string "Invalid or expired refresh token"
-// @Failure 500 {object} map[string]string "Failed to generate new access token"
+// @Failure 403 {object} map[string]string "Account disabled"
+// @Failure 500 {object} map[string]string "Database or token generation error"
 // @Router /token/refresh [post]
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	var body RefreshRequest
@@ -25,22 +28,53 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	// ✅ use handler’s injected parseJWT
-	claims, err := h.parseJWT(body.RefreshToken)
+	// ✅ use handler’s injected parseJWT; access tokens are rejected here
+	claims, err := h.parseJWT(body.RefreshToken, TokenTypeRefresh)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
 		return
 	}
 
-	// ✅ use handler’s injected generateJWT + TTL getter
-	accessToken, err := h.generateJWT(claims.UserID, h.getAccessTTL())
+	tx, err := h.db.Begin()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create access token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Retire the presented token; only a live, unrotated token gets through
+	var familyID string
+	err = tx.QueryRow(`
+		UPDATE sessions
+		SET rotated_at = now(), last_used_at = now()
+		WHERE jti = $1 AND user_id = $2
+			AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > now()
+		RETURNING family_id
+	`, claims.ID, claims.UserID).Scan(&familyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		tx.Rollback()
+		h.revokeOnReuse(claims.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	// ✅ Continue the same session family with a fresh token pair
+	accessToken, refreshToken, err := h.issueTokens(tx, c, claims.UserID, familyID)
+	if err != nil {
+		c.JSON(tokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"access_token":  accessToken,
-		"refresh_token": body.RefreshToken,
+		"refresh_token": refreshToken,
 	})
 }
@@ -1,23 +1,31 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
 // --- Mock helpers for Refresh tests ---
 
-var mockParseJWT = func(token string) (*Claims, error) {
+var mockParseJWT = func(token, tokenType string) (*Claims, error) {
+	if tokenType != TokenTypeRefresh {
+		return nil, errors.New("wrong token type")
+	}
 	if token == "valid-refresh" {
-		return &Claims{UserID: "user123"}, nil
+		claims := &Claims{UserID: "user123", TokenType: TokenTypeRefresh}
+		claims.ID = "jti-old"
+		return claims, nil
 	}
 	if token == "expired-refresh" {
 		return nil, errors.New("token expired")
@@ -25,85 +33,129 @@ var mockParseJWT = func(token string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
-var mockGenerateJWT = func(userID string, _ time.Duration) (string, error) {
-	if userID == "fail-token" {
+var mockGenerateJWT = func(spec tokenSpec) (string, error) {
+	if spec.UserID == "fail-token" {
 		return "", errors.New("token error")
 	}
-	return "mock-token-" + userID, nil
+	return "mock-" + spec.Type + "-" + spec.UserID, nil
 }
 
 var mockGetAccessTTL = func() time.Duration { return 15 * time.Minute }
+var mockGetRefreshTTL = func() time.Duration { return 30 * 24 * time.Hour }
+
+// expectSessionInsert mocks storing a new refresh token row. An empty
+// familyID starts a new family, which the mock names "family-1".
+func expectSessionInsert(mock sqlmock.Sqlmock, userID, familyID string) {
+	returned := familyID
+	if returned == "" {
+		returned = "family-1"
+	}
+	mock.ExpectQuery(`INSERT INTO sessions \(jti, family_id, user_id, user_agent, ip_address, expires_at\) SELECT \$1, COALESCE\(NULLIF\(\$2, ''\)::uuid, gen_random_uuid\(\)\), id, \$4, \$5, \$6 FROM users WHERE id = \$3 AND disabled_at IS NULL RETURNING family_id`).
+		WithArgs(sqlmock.AnyArg(), familyID, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"family_id"}).AddRow(returned))
+}
+
+const rotateSessionQuery = `UPDATE sessions SET rotated_at = now\(\), last_used_at = now\(\) WHERE jti = \$1 AND user_id = \$2 AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > now\(\) RETURNING family_id`
+
+const revokeFamilyQuery = `UPDATE sessions SET revoked_at = now\(\) WHERE revoked_at IS NULL AND family_id = \( SELECT family_id FROM sessions WHERE jti = \$1 AND rotated_at IS NOT NULL \)`
 
-func setupRefreshRouter(t *testing.T) *gin.Engine {
+func setupRefreshRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
 	gin.SetMode(gin.TestMode)
 
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
 	h := &AuthHandler{
-		parseJWT:     mockParseJWT,
-		generateJWT:  mockGenerateJWT,
-		getAccessTTL: mockGetAccessTTL,
+		db:            db,
+		parseJWT:      mockParseJWT,
+		generateJWT:   mockGenerateJWT,
+		getAccessTTL:  mockGetAccessTTL,
+		getRefreshTTL: mockGetRefreshTTL,
 	}
 	r := gin.Default()
 	r.POST("/token/refresh", h.Refresh)
-	return r
+	return r, mock
+}
+
+func postRefresh(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/token/refresh", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
 }
 
 // --- Tests ---
 
 func TestRefresh_Success(t *testing.T) {
-	router := setupRefreshRouter(t)
+	router, mock := setupRefreshRouter(t)
 
-	body := `{"refresh_token":"valid-refresh"}`
-	req, _ := http.NewRequest("POST", "/token/refresh", strings.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
+	mock.ExpectBegin()
+	mock.ExpectQuery(rotateSessionQuery).
+		WithArgs("jti-old", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id"}).AddRow("family-1"))
+	expectSessionInsert(mock, "user123", "family-1")
+	mock.ExpectCommit()
 
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	w := postRefresh(router, `{"refresh_token":"valid-refresh"}`)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	var resp map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "mock-token-user123", resp["access_token"])
-	assert.Equal(t, "valid-refresh", resp["refresh_token"])
+	assert.Equal(t, "mock-access-user123", resp["access_token"])
+	// The refresh token is rotated, never echoed back
+	assert.Equal(t, "mock-refresh-user123", resp["refresh_token"])
+	assert.NotEqual(t, "valid-refresh", resp["refresh_token"])
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestRefresh_InvalidToken(t *testing.T) {
-	router := setupRefreshRouter(t)
+func TestRefresh_ReusedTokenRevokesFamily(t *testing.T) {
+	router, mock := setupRefreshRouter(t)
 
-	body := `{"refresh_token":"bad-token"}`
-	req, _ := http.NewRequest("POST", "/token/refresh", strings.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
+	// The token was already rotated, so the rotate finds nothing...
+	mock.ExpectBegin()
+	mock.ExpectQuery(rotateSessionQuery).
+		WithArgs("jti-old", "user123").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	// ...and the whole family is revoked
+	mock.ExpectExec(revokeFamilyQuery).
+		WithArgs("jti-old").
+		WillReturnResult(sqlmock.NewResult(0, 2))
 
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	w := postRefresh(router, `{"refresh_token":"valid-refresh"}`)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 	assert.Contains(t, w.Body.String(), "invalid refresh token")
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestRefresh_ExpiredToken(t *testing.T) {
-	router := setupRefreshRouter(t)
+func TestRefresh_InvalidToken(t *testing.T) {
+	router, _ := setupRefreshRouter(t)
 
-	body := `{"refresh_token":"expired-refresh"}`
-	req, _ := http.NewRequest("POST", "/token/refresh", strings.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
+	w := postRefresh(router, `{"refresh_token":"bad-token"}`)
 
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid refresh token")
+}
+
+func TestRefresh_ExpiredToken(t *testing.T) {
+	router, _ := setupRefreshRouter(t)
+
+	w := postRefresh(router, `{"refresh_token":"expired-refresh"}`)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 	assert.Contains(t, w.Body.String(), "invalid refresh token")
 }
 
 func TestRefresh_InvalidPayload(t *testing.T) {
-	router := setupRefreshRouter(t)
+	router, _ := setupRefreshRouter(t)
 
-	req, _ := http.NewRequest("POST", "/token/refresh", strings.NewReader(`{invalid-json}`))
-	req.Header.Set("Content-Type", "application/json")
-
-	w := httptest.NewRecorder()
-	router.ServeHTTP(w, req)
+	w := postRefresh(router, `{invalid-json}`)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Contains(t, w.Body.String(), "invalid payload")
@@ -111,25 +163,90 @@ func TestRefresh_InvalidPayload(t *testing.T) {
 
 func TestRefresh_TokenGenError(t *testing.T) {
 	// Override generateJWT to force error
-	errorJWT := func(_ string, _ time.Duration) (string, error) {
+	errorJWT := func(_ tokenSpec) (string, error) {
 		return "", errors.New("token creation failed")
 	}
 
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
 	h := &AuthHandler{
-		parseJWT:     mockParseJWT,
-		generateJWT:  errorJWT,
-		getAccessTTL: mockGetAccessTTL,
+		db:            db,
+		parseJWT:      mockParseJWT,
+		generateJWT:   errorJWT,
+		getAccessTTL:  mockGetAccessTTL,
+		getRefreshTTL: mockGetRefreshTTL,
 	}
 	r := gin.Default()
 	r.POST("/token/refresh", h.Refresh)
 
-	body := `{"refresh_token":"valid-refresh"}`
-	req, _ := http.NewRequest("POST", "/token/refresh", strings.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
+	mock.ExpectBegin()
+	mock.ExpectQuery(rotateSessionQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"family_id"}).AddRow("family-1"))
+	expectSessionInsert(mock, "user123", "family-1")
+	mock.ExpectRollback()
 
-	w := httptest.NewRecorder()
-	r.ServeHTTP(w, req)
+	w := postRefresh(r, `{"refresh_token":"valid-refresh"}`)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "failed to create access token")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefresh_RejectsAccessToken(t *testing.T) {
+	os.Setenv("JWT_SECRET", "refreshSecret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	h := NewAuthHandler(nil, nil, nil)
+	r := gin.Default()
+	r.POST("/token/refresh", h.Refresh)
+
+	access, err := generateJWT(tokenSpec{UserID: "user123", Type: TokenTypeAccess, TTL: time.Minute})
+	assert.NoError(t, err)
+
+	w := postRefresh(r, `{"refresh_token":"`+access+`"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid refresh token")
+}
+
+func TestRefresh_RealTokensRotate(t *testing.T) {
+	os.Setenv("JWT_SECRET", "refreshSecret")
+	defer os.Unsetenv("JWT_SECRET")
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	h := NewAuthHandler(db, nil, nil)
+	r := gin.Default()
+	r.POST("/token/refresh", h.Refresh)
+
+	refresh, err := generateJWT(tokenSpec{UserID: "user123", SessionID: "family-1", Type: TokenTypeRefresh, ID: "jti-1", TTL: time.Hour})
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(rotateSessionQuery).
+		WithArgs("jti-1", "user123").
+		WillReturnRows(sqlmock.NewRows([]string{"family_id"}).AddRow("family-1"))
+	expectSessionInsert(mock, "user123", "family-1")
+	mock.ExpectCommit()
+
+	w := postRefresh(r, `{"refresh_token":"`+refresh+`"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	access, err := ParseAccessToken(resp["access_token"])
+	assert.NoError(t, err)
+	assert.Equal(t, "family-1", access.SessionID)
+
+	next, err := parseJWT(resp["refresh_token"], TokenTypeRefresh)
+	assert.NoError(t, err)
+	assert.Equal(t, "family-1", next.SessionID)
+	assert.NotEqual(t, "jti-1", next.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevokeSession godoc
+// @Summary Sign out a device
+// @Description Revokes one of the logged-in user's sessions by ID, as listed by GET /sessions.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Param session_id path string true "Session ID"
+// @Success 200 {object} map[string]string "Session revoked"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 404 {object} map[string]string "Session not found"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /sessions/{session_id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	n, err := revokeSessions(h.db, userID, c.Param("session_id"), "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeOtherSessions godoc
+// @Summary Sign out all other devices
+// @Description Revokes every session of the logged-in user except the one making the request.
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]string "Other sessions revoked"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 500 {object} map[string]string "Database error"
+// @Router /sessions [delete]
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID := c.GetString("userID")
+	sessionID := c.GetString("sessionID")
+	if userID == "" || sessionID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if _, err := revokeSessions(h.db, userID, "", sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Other sessions revoked"})
+}
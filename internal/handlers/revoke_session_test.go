@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// --- TESTS ---
+
+func TestRevokeSession_Success(t *testing.T) {
+	router, mock := setupSessionRouter(t)
+
+	mock.ExpectExec(revokeSessionsQuery).
+		WithArgs("user123", "session-2", "").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	w := serve(router, "DELETE", "/sessions/session-2")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Session revoked")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeSession_NotFound(t *testing.T) {
+	router, mock := setupSessionRouter(t)
+
+	// Unknown, already revoked, or another user's session
+	mock.ExpectExec(revokeSessionsQuery).
+		WithArgs("user123", "someone-elses", "").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := serve(router, "DELETE", "/sessions/someone-elses")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "session not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeOtherSessions_KeepsCurrent(t *testing.T) {
+	router, mock := setupSessionRouter(t)
+
+	mock.ExpectExec(revokeSessionsQuery).
+		WithArgs("user123", "", "session-1").
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	w := serve(router, "DELETE", "/sessions")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Other sessions revoked")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
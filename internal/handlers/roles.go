@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// Roles a user can have, as stored in users.role.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Roles lists every role.
+var Roles = []string{RoleUser, RoleAdmin}
+
+// UserRole returns the user's role, or "" for unknown users. RequireRole
+// uses it so a changed role applies on the next request, not at token expiry.
+func UserRole(db *sql.DB, userID string) (string, error) {
+	var role string
+	err := db.QueryRow(`SELECT role FROM users WHERE id = $1`, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
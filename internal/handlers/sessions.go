@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors returned by issueTokens; their text is safe to send to clients.
+var (
+	errCreateSession      = errors.New("failed to create session")
+	errCreateAccessToken  = errors.New("failed to create access token")
+	errCreateRefreshToken = errors.New("failed to create refresh token")
+	errAccountDisabled    = errors.New("account disabled")
+)
+
+// querier and execer are satisfied by *sql.DB and *sql.Tx.
+type querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// issueTokens records a session row for a new refresh token and mints the
+// matching token pair. An empty familyID starts a new session family (login);
+// otherwise the token continues an existing one (refresh rotation). Every way
+// of signing in ends here, so disabled accounts are turned away here too.
+func (h *AuthHandler) issueTokens(q querier, c *gin.Context, userID, familyID string) (accessToken, refreshToken string, err error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", "", errCreateRefreshToken
+	}
+
+	refreshTTL := h.getRefreshTTL()
+	err = q.QueryRow(`
+		INSERT INTO sessions (jti, family_id, user_id, user_agent, ip_address, expires_at)
+		SELECT $1, COALESCE(NULLIF($2, '')::uuid, gen_random_uuid()), id, $4, $5, $6
+		FROM users WHERE id = $3 AND disabled_at IS NULL
+		RETURNING family_id
+	`, jti, familyID, userID, c.Request.UserAgent(), c.ClientIP(), time.Now().Add(refreshTTL)).Scan(&familyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", errAccountDisabled
+	}
+	if err != nil {
+		log.Printf("❌ Failed to store session for user %s: %v\n", userID, err)
+		return "", "", errCreateSession
+	}
+
+	accessToken, err = h.generateJWT(tokenSpec{
+		UserID: userID, SessionID: familyID, Type: TokenTypeAccess, TTL: h.getAccessTTL(),
+	})
+	if err != nil {
+		return "", "", errCreateAccessToken
+	}
+
+	refreshToken, err = h.generateJWT(tokenSpec{
+		UserID: userID, SessionID: familyID, Type: TokenTypeRefresh, ID: jti, TTL: refreshTTL,
+	})
+	if err != nil {
+		return "", "", errCreateRefreshToken
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// tokenErrorStatus is the HTTP status for an issueTokens error.
+func tokenErrorStatus(err error) int {
+	if errors.Is(err, errAccountDisabled) {
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// revokeOnReuse revokes every session in the family of an already-rotated
+// refresh token. A rotated token coming back means it was copied, so neither
+// the thief's nor the owner's copy of the family can be trusted.
+func (h *AuthHandler) revokeOnReuse(jti string) {
+	result, err := h.db.Exec(`
+		UPDATE sessions SET revoked_at = now()
+		WHERE revoked_at IS NULL AND family_id = (
+			SELECT family_id FROM sessions WHERE jti = $1 AND rotated_at IS NOT NULL
+		)
+	`, jti)
+	if err != nil {
+		log.Printf("❌ Failed to revoke reused session family: %v\n", err)
+		return
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("⚠️ Refresh token reuse detected (jti=%s), revoked %d session(s)\n", jti, n)
+	}
+}
+
+// revokeSessions revokes the user's session family `only`, or every family
+// except `except` when only is empty. It returns the number of rows revoked.
+func revokeSessions(q execer, userID, only, except string) (int64, error) {
+	result, err := q.Exec(`
+		UPDATE sessions SET revoked_at = now()
+		WHERE user_id = $1 AND revoked_at IS NULL
+			AND ($2 = '' OR family_id::text = $2)
+			AND ($3 = '' OR family_id::text <> $3)
+	`, userID, only, except)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SessionActive reports whether a session family exists and hasn't been
+// revoked. JWTAuthMiddleware uses it to reject access tokens of logged-out sessions.
+func SessionActive(db *sql.DB, sessionID string) (bool, error) {
+	var active bool
+	err := db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM sessions WHERE family_id = $1::uuid AND revoked_at IS NULL
+		)
+	`, sessionID).Scan(&active)
+	return active, err
+}
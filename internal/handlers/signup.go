@@ -2,21 +2,22 @@ package handlers
 
 import (
 	"errors"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgconn"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/jackc/pgx/v5/pgconn"
 	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/password"
 )
 
-// ✅ allow bcrypt mocking in tests
-var bcryptGenerate = bcrypt.GenerateFromPassword
+// ✅ allow hashing failures to be mocked in tests
+var hashPassword = (*password.Hasher).Hash
 
 // Signup godoc
 // @Summary Register a new user
-// @Description Creates a new user account in PostgreSQL and returns account info with JWT access + refresh tokens.
+// @Description Creates a new user account in PostgreSQL, emails a verification link, and returns account info with JWT access + refresh tokens.
 // @Tags Auth
 // @Accept  json
 // @Produce  json
@@ -36,8 +37,8 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		return
 	}
 
-	// ✅ Use mockable bcrypt wrapper
-	hash, err := bcryptGenerate([]byte(req.Password), bcrypt.DefaultCost)
+	// ✅ Use mockable hashing wrapper
+	hash, err := hashPassword(h.hasher(), req.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
 		return
@@ -46,10 +47,10 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 	// Insert into DB
 	var user models.User
 	err = h.db.QueryRow(`
-		INSERT INTO users (first_name, last_name, email, password_hash, phone_number, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (first_name, last_name, email, password_hash, phone_number, created_at, email_verification_sent_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
 		RETURNING id, created_at
-	`, req.FirstName, req.LastName, req.Email, string(hash), req.PhoneNumber, time.Now()).
+	`, req.FirstName, req.LastName, req.Email, hash, req.PhoneNumber, time.Now()).
 		Scan(&user.ID, &user.CreatedAt)
 
 	if err != nil {
@@ -65,17 +66,16 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		return
 	}
 
-	// ✅ Use injected token functions
-	accessToken, err := h.generateJWT(user.ID, h.getAccessTTL())
+	// ✅ Start a session and mint its token pair
+	accessToken, refreshToken, err := h.issueTokens(h.db, c, user.ID, "")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create access token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	refreshToken, err := h.generateJWT(user.ID, h.getRefreshTTL())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create refresh token"})
-		return
+	// ✅ Signup still succeeds if the mail can't go out; the user can resend it
+	if err := h.sendVerificationEmail(c.Request.Context(), user.ID, req.Email, req.FirstName); err != nil {
+		log.Printf("❌ Failed to send verification email to user %s: %v\n", user.ID, err)
 	}
 
 	// Populate user fields
@@ -11,24 +11,26 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"personal-assistant-backend/internal/mail"
 	"personal-assistant-backend/internal/models"
+	"personal-assistant-backend/internal/password"
 )
 
 // --- mock helpers ---
-var mockSignupGenerateJWT = func(userID string, _ time.Duration) (string, error) {
-	if userID == "fail-token" {
+var mockSignupGenerateJWT = func(spec tokenSpec) (string, error) {
+	if spec.UserID == "fail-token" {
 		return "", errors.New("token generation failed")
 	}
-	return "mock-token-" + userID, nil
+	return "mock-" + spec.Type + "-" + spec.UserID, nil
 }
 
 var mockSignupGetAccessTTL = func() time.Duration { return 15 * time.Minute }
 var mockSignupGetRefreshTTL = func() time.Duration { return 30 * 24 * time.Hour }
 
 // --- setup ---
-func setupSignupRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+func setupSignupRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock, *mail.MemorySender) {
 	gin.SetMode(gin.TestMode)
 
 	db, mock, err := sqlmock.New()
@@ -41,23 +43,25 @@ func setupSignupRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
 		generateJWT:   mockSignupGenerateJWT,
 		getAccessTTL:  mockSignupGetAccessTTL,
 		getRefreshTTL: mockSignupGetRefreshTTL,
+		mailer:        mail.NewMemorySender(),
 	}
 
 	r := gin.Default()
 	r.POST("/signup", h.Signup)
-	return r, mock
+	return r, mock, h.mailer.(*mail.MemorySender)
 }
 
 // --- tests ---
 
 func TestSignup_Success(t *testing.T) {
-	router, mock := setupSignupRouter(t)
+	router, mock, mailer := setupSignupRouter(t)
 
 	now := time.Now()
 
-	mock.ExpectQuery(`INSERT INTO users \(first_name, last_name, email, password_hash, phone_number, created_at\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\) RETURNING id, created_at`).
+	mock.ExpectQuery(`INSERT INTO users \(first_name, last_name, email, password_hash, phone_number, created_at, email_verification_sent_at\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$6\) RETURNING id, created_at`).
 		WithArgs("Jane", "Doe", "jane@example.com", sqlmock.AnyArg(), "555-1234", sqlmock.AnyArg()).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("123", now))
+	expectSessionInsert(mock, "123", "")
 
 	body := `{
 		"first_name": "Jane",
@@ -79,13 +83,38 @@ func TestSignup_Success(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
 	assert.Equal(t, "Jane", resp.User.FirstName)
-	assert.Equal(t, "mock-token-123", resp.AccessToken)
-	assert.Equal(t, "mock-token-123", resp.RefreshToken)
+	assert.Equal(t, "mock-access-123", resp.AccessToken)
+	assert.Equal(t, "mock-refresh-123", resp.RefreshToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	sent := mailer.Sent()
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, "jane@example.com", sent[0].To)
+		assert.Contains(t, sent[0].Body, "mock-email_verify-123")
+	}
+}
+
+func TestSignup_MailFailureStillSucceeds(t *testing.T) {
+	router, mock, mailer := setupSignupRouter(t)
+	mailer.Err = errors.New("smtp down")
+
+	mock.ExpectQuery(`INSERT INTO users`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("123", time.Now()))
+	expectSessionInsert(mock, "123", "")
+
+	body := `{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","password":"supersecret"}`
+	req, _ := http.NewRequest("POST", "/signup", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestSignup_InvalidPayload(t *testing.T) {
-	router, _ := setupSignupRouter(t)
+	router, _, _ := setupSignupRouter(t)
 
 	req, _ := http.NewRequest("POST", "/signup", strings.NewReader(`{invalid-json}`))
 	req.Header.Set("Content-Type", "application/json")
@@ -98,7 +127,7 @@ func TestSignup_InvalidPayload(t *testing.T) {
 }
 
 func TestSignup_DBConflict(t *testing.T) {
-	router, mock := setupSignupRouter(t)
+	router, mock, _ := setupSignupRouter(t)
 
 	mock.ExpectQuery(`INSERT INTO users`).WillReturnError(&pgconn.PgError{Code: "23505"})
 
@@ -121,7 +150,7 @@ func TestSignup_DBConflict(t *testing.T) {
 }
 
 func TestSignup_DBError(t *testing.T) {
-	router, mock := setupSignupRouter(t)
+	router, mock, _ := setupSignupRouter(t)
 
 	mock.ExpectQuery(`INSERT INTO users`).WillReturnError(errors.New("db exploded"))
 
@@ -144,14 +173,14 @@ func TestSignup_DBError(t *testing.T) {
 }
 
 func TestSignup_HashError(t *testing.T) {
-	// ✅ Override bcryptGenerate before router setup
-	oldBcrypt := bcryptGenerate
-	bcryptGenerate = func(_ []byte, _ int) ([]byte, error) {
-		return nil, errors.New("hash fail")
+	// ✅ Override hashPassword before router setup
+	oldHash := hashPassword
+	hashPassword = func(*password.Hasher, string) (string, error) {
+		return "", errors.New("hash fail")
 	}
-	defer func() { bcryptGenerate = oldBcrypt }()
+	defer func() { hashPassword = oldHash }()
 
-	router, _ := setupSignupRouter(t)
+	router, _, _ := setupSignupRouter(t)
 
 	body := `{
 		"first_name": "Fail",
@@ -5,28 +5,68 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"personal-assistant-backend/internal/mail"
+	"personal-assistant-backend/internal/oidc"
+	"personal-assistant-backend/internal/password"
 )
 
 // AuthHandler handles authentication-related endpoints.
 type AuthHandler struct {
 	db           *sql.DB
-	generateJWT  func(userID string, ttl time.Duration) (string, error)
+	generateJWT  func(spec tokenSpec) (string, error)
 	getAccessTTL func() time.Duration
 	getRefreshTTL func() time.Duration
-	parseJWT     func(token string) (*Claims, error)
+	parseJWT     func(token, tokenType string) (*Claims, error)
+	mailer       mail.Sender
+	throttle     *loginThrottle // nil disables brute-force protection
+	passwords    *password.Hasher // nil uses password.Default
+	// background runs work the response mustn't wait on; nil means a goroutine
+	background func(task func())
 }
 
 // NewAuthHandler creates a new AuthHandler with default dependencies.
-func NewAuthHandler(db *sql.DB) *AuthHandler {
+func NewAuthHandler(db *sql.DB, mailer mail.Sender, passwords *password.Hasher) *AuthHandler {
 	return &AuthHandler{
 		db:            db,
 		generateJWT:   generateJWT,
 		getAccessTTL:  getAccessTTL,
 		getRefreshTTL: getRefreshTTL,
 		parseJWT:      parseJWT,
+		mailer:        mailer,
+		throttle:      &loginThrottle{db: db},
+		passwords:     passwords,
 	}
 }
 
+// runBackground runs task asynchronously, or via the injected runner in tests.
+func (h *AuthHandler) runBackground(task func()) {
+	if h.background != nil {
+		h.background(task)
+		return
+	}
+	go task()
+}
+
+// hasher returns the password hasher, falling back to password.Default.
+func (h *AuthHandler) hasher() *password.Hasher {
+	if h.passwords == nil {
+		return password.Default
+	}
+	return h.passwords
+}
+
+// OIDCHandler signs users in through external OpenID Connect providers,
+// issuing the same sessions as AuthHandler.
+type OIDCHandler struct {
+	auth      *AuthHandler
+	providers map[string]*oidc.Provider
+}
+
+// NewOIDCHandler creates an OIDCHandler for the given providers, keyed by name.
+func NewOIDCHandler(auth *AuthHandler, providers map[string]*oidc.Provider) *OIDCHandler {
+	return &OIDCHandler{auth: auth, providers: providers}
+}
+
 // Signup request payload
 type signupReq struct {
 	FirstName   string `json:"first_name" binding:"required"`
@@ -42,9 +82,74 @@ type loginReq struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// JWT claims
+// Forgot password request payload
+type forgotPasswordReq struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// Reset password request payload
+type resetPasswordReq struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8,max=128"`
+}
+
+// Verify email request payload
+type verifyEmailReq struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Profile update payload; omitted fields keep their value. An empty
+// phone_number clears it, otherwise it must be E.164 (e.g. +14155552671).
+// Changing the email also needs the current password.
+type updateProfileReq struct {
+	FirstName       *string `json:"first_name,omitempty" binding:"omitempty,min=1,max=80"`
+	LastName        *string `json:"last_name,omitempty" binding:"omitempty,min=1,max=80"`
+	Email           *string `json:"email,omitempty" binding:"omitempty,email"`
+	PhoneNumber     *string `json:"phone_number,omitempty" binding:"omitempty,e164|len=0"`
+	CurrentPassword string  `json:"current_password,omitempty" binding:"required_with=Email"`
+}
+
+// Change password payload
+type changePasswordReq struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8,max=128"`
+}
+
+// Second login step payload; Code is a TOTP code or a recovery code
+type loginMFAReq struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// TOTP enrollment confirmation payload
+type totpCodeReq struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// Disable TOTP payload
+type disableTOTPReq struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// Request body for DELETE /me
+type deleteAccountReq struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// Social login callback payload: the code and state the provider redirected back with
+type oidcCallbackReq struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// JWT claims. TokenType is one of the TokenType constants; SessionID is the
+// session family both tokens of a login belong to, and Email the address an
+// email verification token was issued for.
 type Claims struct {
-	UserID string `json:"sub"`
+	UserID    string `json:"sub"`
+	TokenType string `json:"typ"`
+	SessionID string `json:"sid,omitempty"`
+	Email     string `json:"email,omitempty"`
 	jwt.RegisteredClaims
 }
 
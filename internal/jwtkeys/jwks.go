@@ -0,0 +1,50 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"math/big"
+)
+
+// JWK is a public key in JSON Web Key form (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty" example:"OKP"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"EdDSA"`
+	Kid string `json:"kid" example:"NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"`
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	X   string `json:"x,omitempty" example:"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKS is a JWK Set document, as served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every verification key. The legacy HS256
+// secret is never published.
+func (s *Set) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		set.Keys = append(set.Keys, k.JWK())
+	}
+	return set
+}
+
+// JWK returns the key's public half as a JWK.
+func (k *Key) JWK() JWK {
+	jwk := JWK{Use: "sig", Alg: k.Algorithm, Kid: k.ID}
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64(pub.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64(pub)
+	}
+	return jwk
+}
@@ -0,0 +1,355 @@
+// Package jwtkeys holds the keys tokens are signed and verified with.
+//
+// Tokens are signed with a single active RS256 or EdDSA key and carry its
+// "kid" in the header. Any number of extra public keys can stay trusted for
+// verification while tokens signed by an old key age out, and all of them are
+// published as a JWK Set so other services can verify tokens without sharing a
+// secret. A legacy HS256 JWT_SECRET signs when no key is set; once one is, it
+// is accepted only until an explicit cut-off so the shared secret can't mint
+// tokens forever.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// minRSABits is the smallest RSA modulus accepted for RS256
+const minRSABits = 2048
+
+// ErrNoSigningKey is returned when neither a signing key nor JWT_SECRET is set.
+var ErrNoSigningKey = errors.New("no JWT signing key: set JWT_SIGNING_KEY_FILE, JWT_SIGNING_KEY or JWT_SECRET")
+
+// Key is one RSA or Ed25519 key in a Set.
+type Key struct {
+	ID        string // RFC 7638 thumbprint, used as the "kid"
+	Algorithm string // AlgRS256 or AlgEdDSA
+	Public    crypto.PublicKey
+	private   crypto.PrivateKey // nil for verification-only keys
+}
+
+// NewKey wraps an RSA or Ed25519 key, private or public. Only private keys can sign.
+func NewKey(k any) (*Key, error) {
+	key := &Key{}
+	switch k := k.(type) {
+	case *rsa.PrivateKey:
+		key.private, key.Public = k, &k.PublicKey
+	case ed25519.PrivateKey:
+		key.private, key.Public = k, k.Public()
+	case *rsa.PublicKey, ed25519.PublicKey:
+		key.Public = k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (want RSA or Ed25519)", k)
+	}
+
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key is %d bits, want at least %d", pub.N.BitLen(), minRSABits)
+		}
+		key.Algorithm = AlgRS256
+	case ed25519.PublicKey:
+		key.Algorithm = AlgEdDSA
+	}
+	key.ID = thumbprint(key.Public)
+	return key, nil
+}
+
+// method returns the jwt signing method for the key's algorithm.
+func (k *Key) method() jwt.SigningMethod {
+	if k.Algorithm == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// Set is the active signing key plus every key tokens are still verified with.
+type Set struct {
+	signing     *Key
+	keys        []*Key          // verification keys, signing key first
+	byID        map[string]*Key // keys by kid
+	secret      []byte          // legacy HS256 secret, nil when not accepted
+	secretUntil time.Time       // when a signing key is set, HS256 is accepted only before this
+}
+
+// NewSet builds a Set. signing may be nil when only the legacy secret signs;
+// verify lists extra keys accepted for verification, duplicates ignored.
+// Alongside a signing key the secret verifies only before secretUntil; a
+// zero secretUntil turns it off.
+func NewSet(signing *Key, verify []*Key, secret []byte, secretUntil time.Time) (*Set, error) {
+	if signing == nil && len(secret) == 0 {
+		return nil, ErrNoSigningKey
+	}
+	if signing != nil && signing.private == nil {
+		return nil, errors.New("signing key has no private part")
+	}
+
+	s := &Set{signing: signing, byID: map[string]*Key{}, secret: secret, secretUntil: secretUntil}
+	if signing != nil {
+		verify = append([]*Key{signing}, verify...)
+	}
+	for _, k := range verify {
+		if _, dup := s.byID[k.ID]; dup {
+			continue
+		}
+		s.byID[k.ID] = k
+		s.keys = append(s.keys, k)
+	}
+	return s, nil
+}
+
+// Sign signs claims with the active key, or with the legacy secret when no
+// key is configured.
+func (s *Set) Sign(claims jwt.Claims) (string, error) {
+	if s.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	}
+	token := jwt.NewWithClaims(s.signing.method(), claims)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.private)
+}
+
+// LegacySecretUntil returns when HS256 tokens stop being accepted next to the
+// signing key, or zero when they aren't (or no key is set and the secret signs).
+func (s *Set) LegacySecretUntil() time.Time {
+	if s.signing == nil || len(s.secret) == 0 {
+		return time.Time{}
+	}
+	return s.secretUntil
+}
+
+// acceptsSecret reports whether HS256 tokens verify right now.
+func (s *Set) acceptsSecret() bool {
+	if len(s.secret) == 0 {
+		return false
+	}
+	return s.signing == nil || time.Now().Before(s.secretUntil)
+}
+
+// Parse verifies tokenStr against the set and decodes it into claims. opts
+// add claim checks on top of the signature check.
+func (s *Set) Parse(tokenStr string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	opts = append([]jwt.ParserOption{jwt.WithValidMethods(s.methods())}, opts...)
+	return jwt.ParseWithClaims(tokenStr, claims, s.keyFor, opts...)
+}
+
+// keyFor picks the verification key named by the token's kid, making sure it
+// belongs to the algorithm the token claims so keys can't be used across algorithms.
+func (s *Set) keyFor(token *jwt.Token) (any, error) {
+	alg := token.Method.Alg()
+	if alg == jwt.SigningMethodHS256.Alg() {
+		if !s.acceptsSecret() {
+			return nil, errors.New("HS256 tokens are not accepted")
+		}
+		return s.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.byID[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if key.Algorithm != alg {
+		return nil, fmt.Errorf("key %q does not sign %s", kid, alg)
+	}
+	return key.Public, nil
+}
+
+// methods lists the algorithms the set verifies.
+func (s *Set) methods() []string {
+	var algs []string
+	if s.acceptsSecret() {
+		algs = append(algs, jwt.SigningMethodHS256.Alg())
+	}
+	for _, k := range s.keys {
+		algs = append(algs, k.Algorithm)
+	}
+	return algs
+}
+
+// thumbprint returns the base64url SHA-256 JWK thumbprint of pub (RFC 7638),
+// so a key keeps the same kid however it is loaded.
+func thumbprint(pub crypto.PublicKey) string {
+	var canonical string
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, b64(big.NewInt(int64(pub.E)).Bytes()), b64(pub.N.Bytes()))
+	case ed25519.PublicKey:
+		canonical = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, b64(pub))
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return b64(sum[:])
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParsePEM parses every key in data: PKCS#8 or PKCS#1 private keys and PKIX
+// or PKCS#1 public keys.
+func ParsePEM(data []byte) ([]*Key, error) {
+	var keys []*Key
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var k any
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			k, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			k, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "PUBLIC KEY":
+			k, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			k, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		default:
+			return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", strings.ToLower(block.Type), err)
+		}
+
+		key, err := NewKey(k)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM key found")
+	}
+	return keys, nil
+}
+
+// envVars are the variables FromEnv reads
+var envVars = []string{"JWT_SIGNING_KEY_FILE", "JWT_SIGNING_KEY", "JWT_VERIFY_KEY_FILES", "JWT_VERIFY_KEYS", "JWT_SECRET", "JWT_LEGACY_SECRET_UNTIL"}
+
+// FromEnv loads the key set:
+//   - JWT_SIGNING_KEY_FILE or JWT_SIGNING_KEY: PEM private key tokens are signed with
+//   - JWT_VERIFY_KEY_FILES (comma-separated paths) and JWT_VERIFY_KEYS (PEM):
+//     extra keys still accepted, typically the previous signing key
+//   - JWT_SECRET: legacy HS256 secret, signing only when no key is set
+//   - JWT_LEGACY_SECRET_UNTIL (RFC 3339): with a signing key, JWT_SECRET's
+//     tokens verify until then; unset, they don't verify at all
+func FromEnv() (*Set, error) {
+	var signing *Key
+	signingPEM, err := readPEM("JWT_SIGNING_KEY_FILE", "JWT_SIGNING_KEY")
+	if err != nil {
+		return nil, err
+	}
+	if signingPEM != nil {
+		keys, err := ParsePEM(signingPEM)
+		if err != nil {
+			return nil, fmt.Errorf("JWT signing key: %w", err)
+		}
+		if len(keys) != 1 {
+			return nil, fmt.Errorf("JWT signing key: want exactly one key, got %d", len(keys))
+		}
+		signing = keys[0]
+	}
+
+	var verify []*Key
+	for _, path := range strings.Split(os.Getenv("JWT_VERIFY_KEY_FILES"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("JWT verification key: %w", err)
+		}
+		keys, err := ParsePEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("JWT verification key %s: %w", path, err)
+		}
+		verify = append(verify, keys...)
+	}
+	if inline := os.Getenv("JWT_VERIFY_KEYS"); inline != "" {
+		keys, err := ParsePEM([]byte(inline))
+		if err != nil {
+			return nil, fmt.Errorf("JWT_VERIFY_KEYS: %w", err)
+		}
+		verify = append(verify, keys...)
+	}
+
+	var secret []byte
+	if s := os.Getenv("JWT_SECRET"); s != "" {
+		secret = []byte(s)
+	}
+	var secretUntil time.Time
+	if v := os.Getenv("JWT_LEGACY_SECRET_UNTIL"); v != "" {
+		secretUntil, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_LEGACY_SECRET_UNTIL: %w", err)
+		}
+	}
+	return NewSet(signing, verify, secret, secretUntil)
+}
+
+// readPEM returns the contents of the file named by fileVar, or else the
+// value of inlineVar; nil when neither is set.
+func readPEM(fileVar, inlineVar string) ([]byte, error) {
+	if path := os.Getenv(fileVar); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fileVar, err)
+		}
+		return data, nil
+	}
+	if inline := os.Getenv(inlineVar); inline != "" {
+		return []byte(inline), nil
+	}
+	return nil, nil
+}
+
+// current caches the environment's key set so key files aren't re-read for
+// every token.
+var current struct {
+	sync.Mutex
+	loaded bool
+	env    string
+	set    *Set
+	err    error
+}
+
+// Current returns FromEnv's set, loading it again only when the variables
+// change.
+func Current() (*Set, error) {
+	var env strings.Builder
+	for _, name := range envVars {
+		env.WriteString(os.Getenv(name))
+		env.WriteByte(0)
+	}
+
+	current.Lock()
+	defer current.Unlock()
+	if !current.loaded || current.env != env.String() {
+		current.set, current.err = FromEnv()
+		current.loaded, current.env = true, env.String()
+	}
+	return current.set, current.err
+}
@@ -0,0 +1,215 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEd25519PEM(t *testing.T) []byte {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func newRSAPEM(t *testing.T, bits int) []byte {
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+}
+
+// publicPEM re-encodes the public half of a private key PEM
+func publicPEM(t *testing.T, privPEM []byte) []byte {
+	keys, err := ParsePEM(privPEM)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(keys[0].Public)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func testClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{Subject: "user123", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+}
+
+func mustKey(t *testing.T, data []byte) *Key {
+	keys, err := ParsePEM(data)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	return keys[0]
+}
+
+// --- TESTS ---
+
+func TestSet_SignAndParse(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"EdDSA": newEd25519PEM(t),
+		"RS256": newRSAPEM(t, 2048),
+	} {
+		t.Run(name, func(t *testing.T) {
+			key := mustKey(t, data)
+			set, err := NewSet(key, nil, nil, time.Time{})
+			require.NoError(t, err)
+
+			token, err := set.Sign(testClaims())
+			require.NoError(t, err)
+
+			parsed, err := set.Parse(token, &jwt.RegisteredClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, name, parsed.Method.Alg())
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+		})
+	}
+}
+
+func TestSet_Rotation(t *testing.T) {
+	oldPEM, newPEM := newEd25519PEM(t), newEd25519PEM(t)
+	oldSet, _ := NewSet(mustKey(t, oldPEM), nil, nil, time.Time{})
+	oldToken, _ := oldSet.Sign(testClaims())
+
+	// The old key moves to verification only, by its public half
+	rotated, err := NewSet(mustKey(t, newPEM), []*Key{mustKey(t, publicPEM(t, oldPEM))}, nil, time.Time{})
+	require.NoError(t, err)
+
+	_, err = rotated.Parse(oldToken, &jwt.RegisteredClaims{})
+	assert.NoError(t, err)
+	assert.Len(t, rotated.JWKS().Keys, 2)
+
+	// Once it is dropped its tokens stop verifying
+	retired, _ := NewSet(mustKey(t, newPEM), nil, nil, time.Time{})
+	_, err = retired.Parse(oldToken, &jwt.RegisteredClaims{})
+	assert.Error(t, err)
+}
+
+func TestSet_RejectsAlgorithmConfusion(t *testing.T) {
+	rsaPEM := newRSAPEM(t, 2048)
+	set, _ := NewSet(mustKey(t, rsaPEM), nil, nil, time.Time{})
+
+	// An HS256 token keyed with the published public key must not verify
+	pubPEM := publicPEM(t, rsaPEM)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	forged.Header["kid"] = mustKey(t, rsaPEM).ID
+	forgedStr, _ := forged.SignedString(pubPEM)
+
+	_, err := set.Parse(forgedStr, &jwt.RegisteredClaims{})
+	assert.Error(t, err)
+
+	// Nor a token from a key outside the set
+	other, _ := NewSet(mustKey(t, newEd25519PEM(t)), nil, nil, time.Time{})
+	foreign, _ := other.Sign(testClaims())
+	_, err = set.Parse(foreign, &jwt.RegisteredClaims{})
+	assert.Error(t, err)
+}
+
+func TestSet_LegacySecret(t *testing.T) {
+	// Without a key the secret signs
+	legacy, err := NewSet(nil, nil, []byte("secret"), time.Time{})
+	require.NoError(t, err)
+	token, err := legacy.Sign(testClaims())
+	require.NoError(t, err)
+	assert.Empty(t, legacy.JWKS().Keys)
+	assert.True(t, legacy.LegacySecretUntil().IsZero())
+
+	// With a key it verifies only during the transition, and is never published
+	key := mustKey(t, newEd25519PEM(t))
+	until := time.Now().Add(time.Hour)
+	set, _ := NewSet(key, nil, []byte("secret"), until)
+	_, err = set.Parse(token, &jwt.RegisteredClaims{})
+	assert.NoError(t, err)
+	assert.Len(t, set.JWKS().Keys, 1)
+	assert.Equal(t, until, set.LegacySecretUntil())
+
+	ended, _ := NewSet(key, nil, []byte("secret"), time.Now().Add(-time.Minute))
+	_, err = ended.Parse(token, &jwt.RegisteredClaims{})
+	assert.Error(t, err)
+
+	// No cut-off means no transition at all
+	strict, _ := NewSet(key, nil, []byte("secret"), time.Time{})
+	_, err = strict.Parse(token, &jwt.RegisteredClaims{})
+	assert.Error(t, err)
+
+	_, err = NewSet(nil, nil, nil, time.Time{})
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestNewKey_RejectsWeakRSA(t *testing.T) {
+	_, err := ParsePEM(newRSAPEM(t, 1024))
+	assert.ErrorContains(t, err, "1024 bits")
+}
+
+func TestNewSet_RequiresPrivateSigningKey(t *testing.T) {
+	pub := mustKey(t, publicPEM(t, newEd25519PEM(t)))
+	_, err := NewSet(pub, nil, nil, time.Time{})
+	assert.Error(t, err)
+}
+
+func TestKey_JWK(t *testing.T) {
+	// RFC 8037 appendix A.1 test key and its RFC 7638 thumbprint (A.3)
+	seed, _ := base64.RawURLEncoding.DecodeString("nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A")
+	key, err := NewKey(ed25519.NewKeyFromSeed(seed))
+	require.NoError(t, err)
+
+	jwk := key.JWK()
+	assert.Equal(t, "OKP", jwk.Kty)
+	assert.Equal(t, "Ed25519", jwk.Crv)
+	assert.Equal(t, "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo", jwk.X)
+	assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", jwk.Kid)
+	assert.Equal(t, AlgEdDSA, jwk.Alg)
+	assert.Equal(t, "sig", jwk.Use)
+}
+
+func TestFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	oldPEM, newPEM := newEd25519PEM(t), newRSAPEM(t, 2048)
+	oldPath := filepath.Join(dir, "old.pem")
+	require.NoError(t, os.WriteFile(oldPath, oldPEM, 0o600))
+
+	t.Setenv("JWT_SIGNING_KEY_FILE", "")
+	t.Setenv("JWT_SIGNING_KEY", string(newPEM))
+	t.Setenv("JWT_VERIFY_KEY_FILES", oldPath)
+	t.Setenv("JWT_VERIFY_KEYS", "")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_LEGACY_SECRET_UNTIL", "")
+
+	set, err := Current()
+	require.NoError(t, err)
+	assert.Equal(t, mustKey(t, newPEM).ID, set.signing.ID)
+	assert.Len(t, set.JWKS().Keys, 2)
+
+	// Cached until the environment changes
+	again, _ := Current()
+	assert.Same(t, set, again)
+
+	t.Setenv("JWT_VERIFY_KEY_FILES", filepath.Join(dir, "missing.pem"))
+	_, err = Current()
+	assert.Error(t, err)
+
+	t.Setenv("JWT_VERIFY_KEY_FILES", "")
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("JWT_LEGACY_SECRET_UNTIL", "2030-01-02T15:04:05Z")
+	set, err = Current()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC), set.LegacySecretUntil())
+
+	t.Setenv("JWT_LEGACY_SECRET_UNTIL", "next week")
+	_, err = Current()
+	assert.ErrorContains(t, err, "JWT_LEGACY_SECRET_UNTIL")
+
+	t.Setenv("JWT_LEGACY_SECRET_UNTIL", "")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_SIGNING_KEY", "")
+	_, err = Current()
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+}
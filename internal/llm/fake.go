@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// FakeProvider is a deterministic provider for local dev and tests.
+// It replies with Reply, or echoes the last user message when Reply is empty.
+// StreamErr makes streams fail after the reply instead of finishing, as when
+// the upstream connection drops mid-answer.
+type FakeProvider struct {
+	Reply     string
+	Err       error
+	StreamErr error
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewFakeProvider creates a FakeProvider that echoes the user.
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{}
+}
+
+// Complete returns the whole fake reply at once.
+func (p *FakeProvider) Complete(_ context.Context, req Request) (*Response, error) {
+	reply, err := p.respond(req)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		Content:      reply,
+		FinishReason: FinishStop,
+		Usage:        fakeUsage(req, reply),
+	}, nil
+}
+
+// Stream returns the fake reply one word at a time. Like a real provider, it
+// stops with ctx's error once ctx is cancelled.
+func (p *FakeProvider) Stream(ctx context.Context, req Request) (Stream, error) {
+	reply, err := p.respond(req)
+	if err != nil {
+		return nil, err
+	}
+	usage := fakeUsage(req, reply)
+	return &fakeStream{ctx: ctx, words: strings.SplitAfter(reply, " "), usage: &usage, err: p.StreamErr}, nil
+}
+
+// Requests returns every request the provider has received, oldest first.
+func (p *FakeProvider) Requests() []Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Request(nil), p.requests...)
+}
+
+// LastRequest returns the most recent request, or a zero Request if none.
+func (p *FakeProvider) LastRequest() Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.requests) == 0 {
+		return Request{}
+	}
+	return p.requests[len(p.requests)-1]
+}
+
+func (p *FakeProvider) respond(req Request) (string, error) {
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+
+	if p.Err != nil {
+		return "", p.Err
+	}
+	if p.Reply != "" {
+		return p.Reply, nil
+	}
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == RoleUser {
+			return "You said: " + req.Messages[i].Content, nil
+		}
+	}
+	return "Hello!", nil
+}
+
+// fakeUsage counts tokens with FakeTokenizer.
+func fakeUsage(req Request, reply string) Usage {
+	var tok FakeTokenizer
+	prompt := 0
+	for _, m := range req.Messages {
+		prompt += tok.CountTokens(m.Content)
+	}
+	completion := tok.CountTokens(reply)
+	return Usage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+}
+
+type fakeStream struct {
+	ctx   context.Context
+	words []string
+	pos   int
+	usage *Usage
+	err   error
+	done  bool
+}
+
+func (s *fakeStream) Recv() (Chunk, error) {
+	if err := s.ctx.Err(); err != nil {
+		return Chunk{}, err
+	}
+	if s.pos < len(s.words) {
+		word := s.words[s.pos]
+		s.pos++
+		return Chunk{Delta: word}, nil
+	}
+	if s.err != nil {
+		return Chunk{}, s.err
+	}
+	if !s.done {
+		s.done = true
+		return Chunk{FinishReason: FinishStop, Usage: s.usage}, nil
+	}
+	return Chunk{}, io.EOF
+}
+
+func (s *fakeStream) Close() error {
+	return nil
+}
@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeProvider_CompleteEchoesLastUserMessage(t *testing.T) {
+	p := NewFakeProvider()
+
+	resp, err := p.Complete(context.Background(), Request{Messages: []Message{
+		{Role: RoleUser, Content: "first"},
+		{Role: RoleAssistant, Content: "reply"},
+		{Role: RoleUser, Content: "second question"},
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "You said: second question", resp.Content)
+	assert.Equal(t, FinishStop, resp.FinishReason)
+	assert.Equal(t, 4, resp.Usage.PromptTokens)
+	assert.Equal(t, 4, resp.Usage.CompletionTokens)
+	assert.Equal(t, 8, resp.Usage.TotalTokens)
+}
+
+func TestFakeProvider_StreamYieldsWordsThenUsage(t *testing.T) {
+	p := &FakeProvider{Reply: "hello there world"}
+
+	stream, err := p.Stream(context.Background(), Request{Model: "fake-model"})
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	var text string
+	var last Chunk
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		text += chunk.Delta
+		last = chunk
+	}
+
+	assert.Equal(t, "hello there world", text)
+	assert.Equal(t, FinishStop, last.FinishReason)
+	assert.NotNil(t, last.Usage)
+	assert.Equal(t, 3, last.Usage.CompletionTokens)
+	assert.Equal(t, "fake-model", p.LastRequest().Model)
+}
+
+func TestFakeProvider_StreamStopsWhenCancelled(t *testing.T) {
+	p := &FakeProvider{Reply: "hello there world"}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := p.Stream(ctx, Request{})
+	assert.NoError(t, err)
+
+	chunk, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "hello ", chunk.Delta)
+
+	cancel()
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFakeProvider_StreamErr(t *testing.T) {
+	p := &FakeProvider{Reply: "partial answer", StreamErr: errors.New("connection reset")}
+
+	stream, err := p.Stream(context.Background(), Request{})
+	assert.NoError(t, err)
+
+	var text string
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			assert.EqualError(t, err, "connection reset")
+			break
+		}
+		text += chunk.Delta
+	}
+	assert.Equal(t, "partial answer", text)
+}
+
+func TestFakeProvider_Error(t *testing.T) {
+	p := &FakeProvider{Err: errors.New("boom")}
+
+	_, err := p.Stream(context.Background(), Request{})
+	assert.EqualError(t, err, "boom")
+
+	_, err = p.Complete(context.Background(), Request{})
+	assert.EqualError(t, err, "boom")
+	assert.Len(t, p.Requests(), 2)
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "fake")
+	p, err := NewFromEnv()
+	assert.NoError(t, err)
+	assert.IsType(t, &FakeProvider{}, p)
+
+	t.Setenv("LLM_PROVIDER", "openai")
+	t.Setenv("OPENAI_API_KEY", "")
+	_, err = NewFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("LLM_PROVIDER", "nope")
+	_, err = NewFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package llm
+
+import (
+	"context"
+	"crypto/tls"
+	"math"
+	"net/http"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// DefaultOpenAIModel is used when a request doesn't name a model.
+const DefaultOpenAIModel = "gpt-5-chat-latest"
+
+// OpenAIProvider talks to the OpenAI chat completions API.
+type OpenAIProvider struct {
+	client *openai.Client
+}
+
+// NewOpenAIProvider creates a provider with a TLS-verifying, streaming-friendly HTTP client.
+func NewOpenAIProvider(apiKey string) *OpenAIProvider {
+	config := openai.DefaultConfig(apiKey)
+	config.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: false, // ✅ verifies properly if ca-certificates are installed
+			},
+		},
+		Timeout: 0, // allow long-lived connections for streaming
+	}
+	return &OpenAIProvider{client: openai.NewClientWithConfig(config)}
+}
+
+// Complete runs a non-streaming chat completion.
+func (p *OpenAIProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, toOpenAIRequest(req, false))
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Response{
+		Usage: Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		},
+	}
+	if len(resp.Choices) > 0 {
+		out.Content = resp.Choices[0].Message.Content
+		out.FinishReason = string(resp.Choices[0].FinishReason)
+	}
+	return out, nil
+}
+
+// Stream starts a streaming chat completion with usage reporting enabled.
+func (p *OpenAIProvider) Stream(ctx context.Context, req Request) (Stream, error) {
+	stream, err := p.client.CreateChatCompletionStream(ctx, toOpenAIRequest(req, true))
+	if err != nil {
+		return nil, err
+	}
+	return &openAIStream{stream: stream}, nil
+}
+
+type openAIStream struct {
+	stream *openai.ChatCompletionStream
+}
+
+func (s *openAIStream) Recv() (Chunk, error) {
+	resp, err := s.stream.Recv()
+	if err != nil {
+		return Chunk{}, err
+	}
+
+	var chunk Chunk
+	if len(resp.Choices) > 0 {
+		chunk.Delta = resp.Choices[0].Delta.Content
+		chunk.FinishReason = string(resp.Choices[0].FinishReason)
+	}
+	if resp.Usage != nil {
+		chunk.Usage = &Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		}
+	}
+	return chunk, nil
+}
+
+func (s *openAIStream) Close() error {
+	return s.stream.Close()
+}
+
+func toOpenAIRequest(req Request, stream bool) openai.ChatCompletionRequest {
+	model := req.Model
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, openai.ChatCompletionMessage{Role: m.Role, Content: m.Content})
+	}
+
+	out := openai.ChatCompletionRequest{
+		Model:               model,
+		Messages:            messages,
+		MaxCompletionTokens: req.MaxTokens,
+		Stream:              stream,
+	}
+	if req.Temperature != nil {
+		out.Temperature = *req.Temperature
+		if out.Temperature == 0 {
+			// go-openai drops a zero temperature, so send the closest non-zero value
+			out.Temperature = math.SmallestNonzeroFloat32
+		}
+	}
+	if req.TopP != nil {
+		out.TopP = *req.TopP
+	}
+	if stream {
+		out.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
+	return out
+}
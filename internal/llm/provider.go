@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Message roles understood by every provider.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Finish reasons reported when a completion ends.
+const (
+	FinishStop          = "stop"
+	FinishLength        = "length"
+	FinishContentFilter = "content_filter"
+)
+
+// Message is a single provider-neutral chat message.
+type Message struct {
+	Role    string
+	Content string
+}
+
+// Request describes a chat completion. Zero-valued options use provider defaults.
+type Request struct {
+	Model       string
+	Messages    []Message
+	Temperature *float32
+	TopP        *float32
+	MaxTokens   int
+}
+
+// Usage reports token consumption for a completion.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// Response is the result of a non-streaming completion.
+type Response struct {
+	Content      string
+	FinishReason string
+	Usage        Usage
+}
+
+// Chunk is one piece of a streamed completion. FinishReason and Usage
+// are only set on the chunks where the provider reports them.
+type Chunk struct {
+	Delta        string
+	FinishReason string
+	Usage        *Usage
+}
+
+// Stream yields chunks until Recv returns io.EOF.
+type Stream interface {
+	Recv() (Chunk, error)
+	Close() error
+}
+
+// Provider is implemented by every LLM backend the assistant can talk to.
+type Provider interface {
+	Complete(ctx context.Context, req Request) (*Response, error)
+	Stream(ctx context.Context, req Request) (Stream, error)
+}
+
+// NewFromEnv builds the provider selected by LLM_PROVIDER ("openai" by default, or "fake").
+func NewFromEnv() (Provider, error) {
+	switch name := os.Getenv("LLM_PROVIDER"); name {
+	case "", "openai":
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY not set")
+		}
+		return NewOpenAIProvider(apiKey), nil
+	case "fake":
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM_PROVIDER %q", name)
+	}
+}
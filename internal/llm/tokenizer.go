@@ -0,0 +1,27 @@
+package llm
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Tokenizer estimates how many tokens a piece of text costs.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// ApproxTokenizer estimates ~4 characters per token, which tracks
+// OpenAI's BPE tokenizers closely enough for context budgeting.
+type ApproxTokenizer struct{}
+
+func (ApproxTokenizer) CountTokens(text string) int {
+	n := utf8.RuneCountInString(text)
+	return (n + 3) / 4
+}
+
+// FakeTokenizer counts whitespace-separated words, matching FakeProvider's usage numbers.
+type FakeTokenizer struct{}
+
+func (FakeTokenizer) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
@@ -0,0 +1,89 @@
+package llm
+
+// MessageOverheadTokens approximates the per-message framing tokens
+// (role, separators) chat models add on top of the content.
+const MessageOverheadTokens = 4
+
+// ContextBuilder assembles a prompt that fits a token budget. System
+// messages and the current message are always kept; history is added
+// newest-first until the next message no longer fits.
+type ContextBuilder struct {
+	Tokenizer Tokenizer
+
+	budget      int
+	used        int
+	system      []Message
+	history     []Message // newest first
+	current     *Message
+	currentCost int
+	overflowed  bool
+}
+
+// NewContextBuilder creates a builder with the given prompt-token budget.
+func NewContextBuilder(budget int, tokenizer Tokenizer) *ContextBuilder {
+	return &ContextBuilder{Tokenizer: tokenizer, budget: budget}
+}
+
+// Count returns the content tokens for text using the builder's tokenizer.
+func (b *ContextBuilder) Count(text string) int {
+	return b.Tokenizer.CountTokens(text)
+}
+
+// AddSystem appends a system message that is always kept.
+func (b *ContextBuilder) AddSystem(content string) {
+	b.system = append(b.system, Message{Role: RoleSystem, Content: content})
+	b.used += b.Count(content) + MessageOverheadTokens
+}
+
+// SetCurrent sets the message being answered; it is always kept and placed last.
+func (b *ContextBuilder) SetCurrent(m Message, tokens int) {
+	b.used -= b.currentCost
+	b.current = &m
+	b.currentCost = tokens + MessageOverheadTokens
+	b.used += b.currentCost
+}
+
+// AddHistory offers the next-older history message with its content token
+// count. It returns false, and marks the builder as overflowed, once the
+// message doesn't fit; callers should stop adding history at that point.
+func (b *ContextBuilder) AddHistory(m Message, tokens int) bool {
+	if b.overflowed {
+		return false
+	}
+	cost := tokens + MessageOverheadTokens
+	if b.used+cost > b.budget {
+		b.overflowed = true
+		return false
+	}
+	b.used += cost
+	b.history = append(b.history, m)
+	return true
+}
+
+// Overflowed reports whether some history had to be left out.
+func (b *ContextBuilder) Overflowed() bool {
+	return b.overflowed
+}
+
+// Used returns the prompt tokens consumed so far.
+func (b *ContextBuilder) Used() int {
+	return b.used
+}
+
+// HistoryLen returns how many history messages were kept.
+func (b *ContextBuilder) HistoryLen() int {
+	return len(b.history)
+}
+
+// Messages returns system messages, kept history oldest-first, then the current message.
+func (b *ContextBuilder) Messages() []Message {
+	out := make([]Message, 0, len(b.system)+len(b.history)+1)
+	out = append(out, b.system...)
+	for i := len(b.history) - 1; i >= 0; i-- {
+		out = append(out, b.history[i])
+	}
+	if b.current != nil {
+		out = append(out, *b.current)
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeTokenizer_CountsWords(t *testing.T) {
+	assert.Equal(t, 0, FakeTokenizer{}.CountTokens("   "))
+	assert.Equal(t, 3, FakeTokenizer{}.CountTokens("one two  three"))
+}
+
+func TestApproxTokenizer_RoundsUp(t *testing.T) {
+	assert.Equal(t, 0, ApproxTokenizer{}.CountTokens(""))
+	assert.Equal(t, 1, ApproxTokenizer{}.CountTokens("abc"))
+	assert.Equal(t, 2, ApproxTokenizer{}.CountTokens("abcde"))
+}
+
+func TestContextBuilder_FillsNewestFirstWithinBudget(t *testing.T) {
+	// system: 2 words + 4 overhead = 6, current: 1 + 4 = 5 → 11 used
+	b := NewContextBuilder(30, FakeTokenizer{})
+	b.AddSystem("be nice")
+	b.SetCurrent(Message{Role: RoleUser, Content: "latest"}, 1)
+
+	// newest first: each costs words + 4
+	assert.True(t, b.AddHistory(Message{Role: RoleAssistant, Content: "a b c"}, 3))  // 7 → 18
+	assert.True(t, b.AddHistory(Message{Role: RoleUser, Content: "d e"}, 2))         // 6 → 24
+	assert.False(t, b.AddHistory(Message{Role: RoleAssistant, Content: "f g h"}, 3)) // 7 → 31 > 30
+	assert.False(t, b.AddHistory(Message{Role: RoleUser, Content: "i"}, 1), "stops after first overflow")
+
+	assert.True(t, b.Overflowed())
+	assert.Equal(t, 24, b.Used())
+	assert.Equal(t, 2, b.HistoryLen())
+	assert.Equal(t, []Message{
+		{Role: RoleSystem, Content: "be nice"},
+		{Role: RoleUser, Content: "d e"},
+		{Role: RoleAssistant, Content: "a b c"},
+		{Role: RoleUser, Content: "latest"},
+	}, b.Messages())
+}
+
+func TestContextBuilder_AlwaysKeepsSystemAndCurrent(t *testing.T) {
+	b := NewContextBuilder(5, FakeTokenizer{})
+	b.AddSystem("a very long system prompt that exceeds the budget")
+	b.SetCurrent(Message{Role: RoleUser, Content: "hi"}, 1)
+
+	assert.False(t, b.AddHistory(Message{Role: RoleAssistant, Content: "old"}, 1))
+	assert.Equal(t, []Message{
+		{Role: RoleSystem, Content: "a very long system prompt that exceeds the budget"},
+		{Role: RoleUser, Content: "hi"},
+	}, b.Messages())
+}
+
+func TestContextBuilder_NoOverflowWhenEverythingFits(t *testing.T) {
+	b := NewContextBuilder(100, FakeTokenizer{})
+	b.SetCurrent(Message{Role: RoleUser, Content: "hi"}, 1)
+	b.SetCurrent(Message{Role: RoleUser, Content: "hi there"}, 2)
+
+	assert.True(t, b.AddHistory(Message{Role: RoleAssistant, Content: "old"}, 1))
+	assert.False(t, b.Overflowed())
+	assert.Equal(t, 11, b.Used())
+}
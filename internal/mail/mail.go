@@ -0,0 +1,86 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers outgoing email. Implementations must be safe for concurrent use.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// DefaultMailDir is where the file sender drops messages when MAIL_DIR is unset.
+const DefaultMailDir = "tmp/mail"
+
+// NewFromEnv builds the sender selected by MAIL_SENDER: "smtp", "file"
+// (the default, writes .eml files to MAIL_DIR) or "memory".
+func NewFromEnv() (Sender, error) {
+	switch name := os.Getenv("MAIL_SENDER"); name {
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" {
+			return nil, fmt.Errorf("SMTP_HOST not set")
+		}
+		from := os.Getenv("MAIL_FROM")
+		if from == "" {
+			return nil, fmt.Errorf("MAIL_FROM not set")
+		}
+		port := 587
+		if p := os.Getenv("SMTP_PORT"); p != "" {
+			n, err := strconv.Atoi(p)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid SMTP_PORT %q", p)
+			}
+			port = n
+		}
+		return NewSMTPSender(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from), nil
+	case "", "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = DefaultMailDir
+		}
+		return NewFileSender(dir), nil
+	case "memory":
+		return NewMemorySender(), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_SENDER %q", name)
+	}
+}
+
+// validate rejects messages whose headers could smuggle extra headers in.
+func (m Message) validate() error {
+	if m.To == "" {
+		return fmt.Errorf("mail: no recipient")
+	}
+	if strings.ContainsAny(m.To+m.Subject, "\r\n") {
+		return fmt.Errorf("mail: header contains a line break")
+	}
+	return nil
+}
+
+// format renders the message as RFC 5322 text with CRLF line endings.
+func (m Message) format(from string) []byte {
+	var b strings.Builder
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", m.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	body := strings.ReplaceAll(m.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,103 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("MAIL_SENDER", "")
+	t.Setenv("MAIL_DIR", "")
+	s, err := NewFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, &FileSender{Dir: DefaultMailDir}, s)
+
+	t.Setenv("MAIL_SENDER", "memory")
+	s, err = NewFromEnv()
+	assert.NoError(t, err)
+	assert.IsType(t, &MemorySender{}, s)
+
+	t.Setenv("MAIL_SENDER", "smtp")
+	t.Setenv("SMTP_HOST", "")
+	_, err = NewFromEnv()
+	assert.ErrorContains(t, err, "SMTP_HOST not set")
+
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("MAIL_FROM", "noreply@example.com")
+	t.Setenv("SMTP_PORT", "2525")
+	s, err = NewFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:2525", s.(*SMTPSender).Addr)
+
+	t.Setenv("MAIL_SENDER", "pigeon")
+	_, err = NewFromEnv()
+	assert.ErrorContains(t, err, "unknown MAIL_SENDER")
+}
+
+func TestMemorySender(t *testing.T) {
+	s := NewMemorySender()
+	msg := Message{To: "jane@example.com", Subject: "Hi", Body: "Hello"}
+
+	assert.NoError(t, s.Send(context.Background(), msg))
+	assert.Equal(t, []Message{msg}, s.Sent())
+
+	s.Err = errors.New("boom")
+	assert.EqualError(t, s.Send(context.Background(), msg), "boom")
+	assert.Len(t, s.Sent(), 1)
+}
+
+func TestSend_RejectsHeaderInjection(t *testing.T) {
+	s := NewMemorySender()
+	err := s.Send(context.Background(), Message{To: "jane@example.com", Subject: "Hi\r\nBcc: evil@example.com"})
+	assert.Error(t, err)
+	assert.Empty(t, s.Sent())
+}
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	s := NewFileSender(dir)
+
+	err := s.Send(context.Background(), Message{To: "jane@example.com", Subject: "Reset", Body: "line one\nline two"})
+	assert.NoError(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if assert.Len(t, files, 1) {
+		data, _ := os.ReadFile(files[0])
+		assert.Contains(t, string(data), "To: jane@example.com\r\n")
+		assert.Contains(t, string(data), "Subject: Reset\r\n")
+		assert.Contains(t, string(data), "\r\n\r\nline one\r\nline two")
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	s := NewSMTPSender("smtp.example.com", 587, "user", "pass", "noreply@example.com")
+	assert.NotNil(t, s.Auth)
+
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	s.sendMail = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, msg
+		return nil
+	}
+
+	err := s.Send(context.Background(), Message{To: "jane@example.com", Subject: "Reset", Body: "Hello"})
+	assert.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, "noreply@example.com", gotFrom)
+	assert.Equal(t, []string{"jane@example.com"}, gotTo)
+	assert.Contains(t, string(gotMsg), "From: noreply@example.com\r\n")
+
+	s.sendMail = func(string, smtp.Auth, string, []string, []byte) error { return errors.New("relay down") }
+	assert.ErrorContains(t, s.Send(context.Background(), Message{To: "jane@example.com"}), "relay down")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, s.Send(ctx, Message{To: "jane@example.com"}), context.Canceled)
+}
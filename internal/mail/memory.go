@@ -0,0 +1,71 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemorySender keeps sent messages in memory for tests. Err, when set,
+// is returned from Send instead of recording the message.
+type MemorySender struct {
+	Err error
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+// NewMemorySender creates an empty MemorySender.
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+// Send records msg.
+func (s *MemorySender) Send(_ context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	if s.Err != nil {
+		return s.Err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+// Sent returns a copy of every message sent so far.
+func (s *MemorySender) Sent() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.sent...)
+}
+
+// FileSender writes each message to Dir as an .eml file, so local dev
+// can read reset links without an SMTP server.
+type FileSender struct {
+	Dir string
+}
+
+// NewFileSender creates a FileSender writing into dir.
+func NewFileSender(dir string) *FileSender {
+	return &FileSender{Dir: dir}
+}
+
+// Send writes msg to <Dir>/<timestamp>-<recipient>.eml.
+func (s *FileSender) Send(_ context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("mail dir: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.eml",
+		time.Now().UTC().Format("20060102T150405.000000000"),
+		strings.NewReplacer("/", "_", "\\", "_", "@", "_at_").Replace(msg.To))
+	return os.WriteFile(filepath.Join(s.Dir, name), msg.format(""), 0o600)
+}
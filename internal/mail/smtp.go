@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPSender delivers mail through an SMTP relay, using STARTTLS when the
+// server offers it and PLAIN auth when a username is configured.
+type SMTPSender struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPSender creates an SMTPSender for host:port.
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	s := &SMTPSender{
+		Addr:     host + ":" + strconv.Itoa(port),
+		From:     from,
+		sendMail: smtp.SendMail,
+	}
+	if username != "" {
+		s.Auth = smtp.PlainAuth("", username, password, host)
+	}
+	return s
+}
+
+// Send delivers msg. net/smtp has no context support, so ctx is only
+// checked before dialing.
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.sendMail(s.Addr, s.Auth, s.From, []string{msg.To}, msg.format(s.From)); err != nil {
+		return fmt.Errorf("smtp send: %w", err)
+	}
+	return nil
+}
@@ -1,18 +1,50 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/apikeys"
 )
 
-// APIKeyAuthMiddleware returns a middleware that checks for the given API key
-func APIKeyAuthMiddleware(apiKey string) gin.HandlerFunc {
+// APIKeyAuthMiddleware checks X-API-Key against the client keys in store and
+// records which client made the request under "apiKey"
+func APIKeyAuthMiddleware(store *apikeys.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clientKey := c.GetHeader("X-API-Key")
-		if clientKey == "" || clientKey != apiKey {
+		if clientKey == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+			return
+		}
+
+		key, err := store.Authenticate(c.Request.Context(), clientKey)
+		if err == apikeys.ErrNotFound {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
 			return
 		}
+		if err != nil {
+			log.Printf("❌ API key lookup failed: %v\n", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+
+		c.Set("apiKey", key)
+		c.Next()
+
+		log.Printf("🔑 %s %s by client %q (%s) -> %d\n", c.Request.Method, c.Request.URL.Path, key.Name, key.Prefix, c.Writer.Status())
+	}
+}
+
+// RequireAPIScope rejects clients whose key lacks scope. It must run after
+// APIKeyAuthMiddleware.
+func RequireAPIScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, _ := c.Get("apiKey")
+		if k, ok := key.(*apikeys.Key); !ok || !k.Allows(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks scope " + scope})
+			return
+		}
 		c.Next()
 	}
 }
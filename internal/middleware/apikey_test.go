@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"personal-assistant-backend/internal/apikeys"
+)
+
+// setupAPIKeyRouter mounts an open route and a chat-scoped one behind the API key check
+func setupAPIKeyRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(APIKeyAuthMiddleware(apikeys.NewStore(db, "legacy-key")))
+	r.GET("/hello", func(c *gin.Context) {
+		key := c.MustGet("apiKey").(*apikeys.Key)
+		c.JSON(http.StatusOK, gin.H{"client": key.Name})
+	})
+	r.GET("/chats", RequireAPIScope(apikeys.ScopeChat), func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r, mock
+}
+
+const apiKeyLookupQuery = `SELECT id, name, prefix, array_to_string\(scopes, ','\), created_at, last_used_at FROM api_keys WHERE key_hash = \$1 AND revoked_at IS NULL`
+
+// expectAPIKey mocks a recently used key for client "ios" with scopes
+func expectAPIKey(mock sqlmock.Sqlmock, scopes string) {
+	mock.ExpectQuery(apiKeyLookupQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "prefix", "scopes", "created_at", "last_used_at"}).
+			AddRow("key-1", "ios", "pak_abcdefgh", scopes, time.Now(), time.Now()))
+}
+
+func getWithKey(router *gin.Engine, path, key string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// --- TESTS ---
+
+func TestAPIKey_RecordsClient(t *testing.T) {
+	router, mock := setupAPIKeyRouter(t)
+	expectAPIKey(mock, "auth")
+
+	w := getWithKey(router, "/hello", "pak_abcdefghijklmnop")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"client":"ios"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKey_MissingOrUnknown(t *testing.T) {
+	router, mock := setupAPIKeyRouter(t)
+	mock.ExpectQuery(apiKeyLookupQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	assert.Equal(t, http.StatusUnauthorized, getWithKey(router, "/hello", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, getWithKey(router, "/hello", "pak_revoked").Code)
+	assert.Equal(t, http.StatusUnauthorized, getWithKey(router, "/hello", "wrong").Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKey_LegacyKey(t *testing.T) {
+	router, _ := setupAPIKeyRouter(t)
+
+	w := getWithKey(router, "/chats", "legacy-key")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireAPIScope(t *testing.T) {
+	router, mock := setupAPIKeyRouter(t)
+	expectAPIKey(mock, "auth")
+	expectAPIKey(mock, "auth,chat")
+
+	w := getWithKey(router, "/chats", "pak_abcdefghijklmnop")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "lacks scope chat")
+
+	w = getWithKey(router, "/chats", "pak_abcdefghijklmnop")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,18 +1,19 @@
 package middleware
 
 import (
+	"database/sql"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"personal-assistant-backend/internal/handlers"
+	"personal-assistant-backend/internal/jwtkeys"
 )
 
-// JWTAuthMiddleware ensures requests have a valid JWT access token
-func JWTAuthMiddleware() gin.HandlerFunc {
+// JWTAuthMiddleware ensures requests have a valid JWT access token whose
+// session hasn't been revoked
+func JWTAuthMiddleware(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -32,18 +33,15 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenStr := parts[1]
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			log.Println("❌ JWT_SECRET not set in environment")
+		if _, err := jwtkeys.Current(); err != nil {
+			log.Printf("❌ JWT keys not configured: %v\n", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "server misconfigured"})
 			c.Abort()
 			return
 		}
 
-		// Parse and validate the JWT
-		token, err := jwt.ParseWithClaims(tokenStr, &handlers.Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
+		// Parse and validate the JWT; refresh tokens are not bearer tokens
+		claims, err := handlers.ParseAccessToken(tokenStr)
 		if err != nil {
 			log.Printf("❌ Token parse error: %v\n", err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
@@ -51,25 +49,32 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if !token.Valid {
-			log.Println("❌ Token parsed but marked as invalid")
+		// Logged-out sessions lose their access tokens immediately, not at expiry
+		if claims.SessionID == "" {
+			log.Println("❌ Token has no session")
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			c.Abort()
 			return
 		}
-
-		claims, ok := token.Claims.(*handlers.Claims)
-		if !ok {
-			log.Println("❌ Failed to cast token claims to expected type")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
+		active, err := handlers.SessionActive(db, claims.SessionID)
+		if err != nil {
+			log.Printf("❌ Session lookup failed: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			c.Abort()
+			return
+		}
+		if !active {
+			log.Printf("❌ Session %s has been revoked\n", claims.SessionID)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session revoked"})
 			c.Abort()
 			return
 		}
 
 		log.Printf("✅ Valid token for userID=%s, expires=%v\n", claims.UserID, claims.ExpiresAt)
 
-		// ✅ Store user ID in context using the same key as handlers
+		// ✅ Store user and session IDs in context using the same keys as handlers
 		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
 
 		c.Next()
 	}
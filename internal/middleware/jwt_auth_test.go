@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"personal-assistant-backend/internal/handlers"
+	"personal-assistant-backend/internal/jwtkeys"
+)
+
+// setupJWTRouter mounts a route that echoes the authenticated user and session
+func setupJWTRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "middlewareSecret")
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(JWTAuthMiddleware(db))
+	r.GET("/me", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("userID"), "session_id": c.GetString("sessionID")})
+	})
+	return r, mock
+}
+
+const sessionActiveQuery = `SELECT EXISTS \( SELECT 1 FROM sessions WHERE family_id = \$1::uuid AND revoked_at IS NULL \)`
+
+// expectSessionActive mocks the revocation check for session-1
+func expectSessionActive(mock sqlmock.Sqlmock, active bool) {
+	mock.ExpectQuery(sessionActiveQuery).
+		WithArgs("session-1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(active))
+}
+
+// signToken mints a token like the auth handlers do
+func signToken(t *testing.T, tokenType string) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, handlers.Claims{
+		UserID:    "user123",
+		TokenType: tokenType,
+		SessionID: "session-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "personal-assistant-backend",
+			Audience:  jwt.ClaimStrings{"personal-assistant-api"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			ID:        "jti-1",
+		},
+	}).SignedString([]byte(os.Getenv("JWT_SECRET")))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func getMe(router *gin.Engine, authHeader string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/me", nil)
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// --- TESTS ---
+
+func TestJWTAuth_AcceptsAccessToken(t *testing.T) {
+	router, mock := setupJWTRouter(t)
+	expectSessionActive(mock, true)
+
+	w := getMe(router, "Bearer "+signToken(t, handlers.TokenTypeAccess))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "user123")
+	assert.Contains(t, w.Body.String(), "session-1")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJWTAuth_RejectsRevokedSession(t *testing.T) {
+	router, mock := setupJWTRouter(t)
+	expectSessionActive(mock, false)
+
+	w := getMe(router, "Bearer "+signToken(t, handlers.TokenTypeAccess))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "session revoked")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJWTAuth_RejectsRefreshToken(t *testing.T) {
+	router, _ := setupJWTRouter(t)
+
+	w := getMe(router, "Bearer "+signToken(t, handlers.TokenTypeRefresh))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid or expired token")
+}
+
+func TestJWTAuth_MissingOrMalformedHeader(t *testing.T) {
+	router, _ := setupJWTRouter(t)
+
+	assert.Equal(t, http.StatusUnauthorized, getMe(router, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, getMe(router, "Token abc").Code)
+	assert.Equal(t, http.StatusUnauthorized, getMe(router, "Bearer not-a-jwt").Code)
+}
+
+func TestJWTAuth_AcceptsAsymmetricToken(t *testing.T) {
+	router, mock := setupJWTRouter(t)
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(priv)
+	t.Setenv("JWT_SIGNING_KEY", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+	expectSessionActive(mock, true)
+
+	keys, err := jwtkeys.Current()
+	if err != nil {
+		t.Fatalf("failed to load keys: %v", err)
+	}
+	token, _ := keys.Sign(handlers.Claims{
+		UserID:    "user123",
+		TokenType: handlers.TokenTypeAccess,
+		SessionID: "session-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "personal-assistant-backend",
+			Audience:  jwt.ClaimStrings{"personal-assistant-api"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			ID:        "jti-1",
+		},
+	})
+
+	w := getMe(router, "Bearer "+token)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJWTAuth_NoKeysConfigured(t *testing.T) {
+	router, _ := setupJWTRouter(t)
+	t.Setenv("JWT_SECRET", "")
+
+	w := getMe(router, "Bearer whatever")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "server misconfigured")
+}
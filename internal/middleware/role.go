@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/handlers"
+)
+
+// RequireRole only lets users with the given role through and stores it in
+// the context as "role". It must run after JWTAuthMiddleware, which sets
+// userID. The role is read on every request, so demotions apply at once.
+func RequireRole(db *sql.DB, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("userID")
+		current, err := handlers.UserRole(db, userID)
+		if err != nil {
+			log.Printf("❌ Role lookup failed: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			c.Abort()
+			return
+		}
+		if current != role {
+			log.Printf("❌ User %s (role %q) denied %s route %s\n", userID, current, role, c.FullPath())
+			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient role"})
+			c.Abort()
+			return
+		}
+
+		c.Set("role", current)
+		c.Next()
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupRoleRouter mounts an admin-only route for user123
+func setupRoleRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+	r.Use(RequireRole(db, "admin"))
+	r.GET("/admin/users", func(c *gin.Context) { c.String(http.StatusOK, c.GetString("role")) })
+	return r, mock
+}
+
+const userRoleQuery = `SELECT role FROM users WHERE id = \$1`
+
+func getAdminUsers(router *gin.Engine) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/admin/users", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireRole_Allowed(t *testing.T) {
+	router, mock := setupRoleRouter(t)
+
+	mock.ExpectQuery(userRoleQuery).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("admin"))
+
+	w := getAdminUsers(router)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "admin", w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequireRole_Denied(t *testing.T) {
+	router, mock := setupRoleRouter(t)
+
+	mock.ExpectQuery(userRoleQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("user"))
+
+	w := getAdminUsers(router)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "insufficient role")
+}
+
+func TestRequireRole_UnknownUser(t *testing.T) {
+	router, mock := setupRoleRouter(t)
+
+	mock.ExpectQuery(userRoleQuery).WillReturnRows(sqlmock.NewRows([]string{"role"}))
+
+	assert.Equal(t, http.StatusForbidden, getAdminUsers(router).Code)
+}
+
+func TestRequireRole_DBError(t *testing.T) {
+	router, mock := setupRoleRouter(t)
+
+	mock.ExpectQuery(userRoleQuery).WillReturnError(errors.New("db down"))
+
+	assert.Equal(t, http.StatusInternalServerError, getAdminUsers(router).Code)
+}
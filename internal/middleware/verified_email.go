@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"personal-assistant-backend/internal/handlers"
+)
+
+// RequireVerifiedEmail rejects users who haven't verified their email yet.
+// It must run after JWTAuthMiddleware, which sets userID.
+func RequireVerifiedEmail(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		verified, err := handlers.EmailVerified(db, c.GetString("userID"))
+		if err != nil {
+			log.Printf("❌ Email verification lookup failed: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			c.Abort()
+			return
+		}
+		if !verified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "email not verified"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
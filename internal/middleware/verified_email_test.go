@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupVerifiedRouter mounts a chat-like route behind RequireVerifiedEmail
+func setupVerifiedRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", "user123")
+		c.Next()
+	})
+	r.Use(RequireVerifiedEmail(db))
+	r.GET("/chats", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r, mock
+}
+
+const emailVerifiedQuery = `SELECT email_verified_at IS NOT NULL FROM users WHERE id = \$1`
+
+func TestRequireVerifiedEmail_Verified(t *testing.T) {
+	router, mock := setupVerifiedRouter(t)
+
+	mock.ExpectQuery(emailVerifiedQuery).
+		WithArgs("user123").
+		WillReturnRows(sqlmock.NewRows([]string{"verified"}).AddRow(true))
+
+	req, _ := http.NewRequest("GET", "/chats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequireVerifiedEmail_Unverified(t *testing.T) {
+	router, mock := setupVerifiedRouter(t)
+
+	mock.ExpectQuery(emailVerifiedQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"verified"}).AddRow(false))
+
+	req, _ := http.NewRequest("GET", "/chats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "email not verified")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequireVerifiedEmail_DBError(t *testing.T) {
+	router, mock := setupVerifiedRouter(t)
+
+	mock.ExpectQuery(emailVerifiedQuery).WillReturnError(errors.New("db exploded"))
+
+	req, _ := http.NewRequest("GET", "/chats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,270 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQL files are compiled into the binary so every environment runs the same schema.
+//
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+// lockKey is the pg_advisory_lock key held while migrating, so several
+// machines booting at once don't race each other.
+const lockKey = 7210344
+
+// Migration is a single versioned schema change with its up and down SQL.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes whether a migration has been applied to the database.
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Migrator applies and rolls back migrations against a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New creates a Migrator using the embedded SQL files.
+func New(db *sql.DB) (*Migrator, error) {
+	migrations, err := Load(sqlFiles, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load reads "<version>_<name>.up.sql" / ".down.sql" pairs from dir, sorted by version.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		file := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(file, "."+direction+".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %q", file)
+		}
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", file, err)
+		}
+
+		contents, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file, err)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, name)
+		}
+
+		if direction == "up" {
+			m.Up = string(contents)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s is missing its up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up applies every pending migration in version order and returns how many ran.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer m.unlock(conn)
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if err := runInTx(ctx, conn, mig.Up,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mig.Version, mig.Name); err != nil {
+			return count, fmt.Errorf("apply %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Down rolls back the latest `steps` applied migrations and returns how many ran.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer m.unlock(conn)
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if mig.Down == "" {
+			return count, fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+		}
+		if err := runInTx(ctx, conn, mig.Down,
+			`DELETE FROM schema_migrations WHERE version = $1`, mig.Version); err != nil {
+			return count, fmt.Errorf("revert %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Status reports every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if at, ok := applied[mig.Version]; ok {
+			appliedAt := at
+			s.Applied = true
+			s.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// lock pins a connection and takes the migration advisory lock on it.
+func (m *Migrator) lock(ctx context.Context) (*sql.Conn, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+	return conn, nil
+}
+
+func (m *Migrator) unlock(conn *sql.Conn) {
+	conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+	conn.Close()
+}
+
+// appliedVersions ensures schema_migrations exists and returns applied versions.
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// runInTx executes a migration body and its bookkeeping statement atomically.
+func runInTx(ctx context.Context, conn *sql.Conn, body, bookkeeping string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,157 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 1, Name: "create_users", Up: "CREATE TABLE users ()", Down: "DROP TABLE users"},
+		{Version: 2, Name: "create_chats", Up: "CREATE TABLE chats ()", Down: "DROP TABLE chats"},
+	}
+}
+
+// --- Load tests ---
+
+func TestLoad_SortsAndPairsFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0002_b.up.sql":   {Data: []byte("UP B")},
+		"sql/0002_b.down.sql": {Data: []byte("DOWN B")},
+		"sql/0001_a.up.sql":   {Data: []byte("UP A")},
+		"sql/README.md":       {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(fsys, "sql")
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "a", migrations[0].Name)
+	assert.Equal(t, "", migrations[0].Down)
+	assert.Equal(t, "DOWN B", migrations[1].Down)
+}
+
+func TestLoad_MissingUp(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0001_a.down.sql": {Data: []byte("DOWN A")},
+	}
+
+	_, err := Load(fsys, "sql")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "missing its up file")
+}
+
+func TestLoad_InvalidVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/abc_a.up.sql": {Data: []byte("UP A")},
+	}
+
+	_, err := Load(fsys, "sql")
+	assert.Error(t, err)
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	migrations, err := Load(sqlFiles, "sql")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for _, m := range migrations {
+		assert.NotEmpty(t, m.Down, "migration %d_%s should be reversible", m.Version, m.Name)
+	}
+}
+
+// --- Migrator tests ---
+
+func TestUp_AppliesPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE chats`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(int64(2), "create_chats").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	m := &Migrator{db: db, migrations: testMigrations()}
+	count, err := m.Up(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUp_RollsBackOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE users`).WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	m := &Migrator{db: db, migrations: testMigrations()}
+	count, err := m.Up(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "apply 1_create_users")
+	assert.Equal(t, 0, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDown_RevertsLatest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	now := time.Now()
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, now).AddRow(2, now))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DROP TABLE chats`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations WHERE version = \$1`).WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	m := &Migrator{db: db, migrations: testMigrations()}
+	count, err := m.Down(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatus_ReportsApplied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	now := time.Now()
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, now))
+
+	m := &Migrator{db: db, migrations: testMigrations()}
+	statuses, err := m.Status(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.False(t, statuses[1].Applied)
+	assert.Nil(t, statuses[1].AppliedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS chats (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    title      TEXT NOT NULL DEFAULT 'New Chat',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS chats_user_id_created_at_idx ON chats (user_id, created_at DESC);
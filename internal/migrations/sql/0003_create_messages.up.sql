@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS messages (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chat_id    UUID NOT NULL REFERENCES chats (id) ON DELETE CASCADE,
+    role       TEXT NOT NULL CHECK (role IN ('system', 'user', 'assistant')),
+    content    TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS messages_chat_id_created_at_idx ON messages (chat_id, created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE chats
+    DROP COLUMN IF EXISTS top_p,
+    DROP COLUMN IF EXISTS max_output_tokens,
+    DROP COLUMN IF EXISTS temperature,
+    DROP COLUMN IF EXISTS model;
@@ -0,0 +1,5 @@
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS model             TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS temperature       REAL,
+    ADD COLUMN IF NOT EXISTS max_output_tokens INTEGER,
+    ADD COLUMN IF NOT EXISTS top_p             REAL;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS personas (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name          TEXT NOT NULL,
+    system_prompt TEXT NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS personas_user_id_idx ON personas (user_id);
+
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS persona_id UUID REFERENCES personas (id) ON DELETE SET NULL;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS chat_summaries (
+    chat_id       UUID PRIMARY KEY REFERENCES chats (id) ON DELETE CASCADE,
+    content       TEXT NOT NULL,
+    covered_until TIMESTAMPTZ NOT NULL,
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
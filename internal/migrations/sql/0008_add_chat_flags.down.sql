@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS chats_set_updated_at ON chats;
+DROP FUNCTION IF EXISTS set_updated_at();
+
+ALTER TABLE chats
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS archived,
+    DROP COLUMN IF EXISTS pinned;
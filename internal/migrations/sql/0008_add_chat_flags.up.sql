@@ -0,0 +1,18 @@
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS pinned     BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS archived   BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+UPDATE chats SET updated_at = created_at;
+
+-- Keep updated_at current on every change, whichever code path makes it
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER chats_set_updated_at
+    BEFORE UPDATE ON chats
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
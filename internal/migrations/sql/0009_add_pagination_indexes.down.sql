@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS chats_user_id_created_at_idx ON chats (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS messages_chat_id_created_at_idx ON messages (chat_id, created_at);
+
+DROP INDEX IF EXISTS messages_chat_id_created_at_id_idx;
+DROP INDEX IF EXISTS chats_user_id_created_at_id_idx;
@@ -0,0 +1,6 @@
+-- Cover the (created_at, id) keyset used by cursor pagination
+CREATE INDEX IF NOT EXISTS chats_user_id_created_at_id_idx ON chats (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS messages_chat_id_created_at_id_idx ON messages (chat_id, created_at, id);
+
+DROP INDEX IF EXISTS chats_user_id_created_at_idx;
+DROP INDEX IF EXISTS messages_chat_id_created_at_idx;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS chats_search_vector_idx;
+DROP INDEX IF EXISTS messages_search_vector_idx;
+
+ALTER TABLE chats DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE messages DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,10 @@
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+        GENERATED ALWAYS AS (to_tsvector('english', content)) STORED;
+
+ALTER TABLE chats
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+        GENERATED ALWAYS AS (to_tsvector('english', title)) STORED;
+
+CREATE INDEX IF NOT EXISTS messages_search_vector_idx ON messages USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS chats_search_vector_idx ON chats USING GIN (search_vector);
@@ -0,0 +1,17 @@
+-- One row per refresh token. Rotation marks the old row and inserts a new one
+-- in the same family; replaying a rotated token revokes the whole family.
+CREATE TABLE IF NOT EXISTS sessions (
+    jti          TEXT PRIMARY KEY,
+    family_id    UUID NOT NULL,
+    user_id      UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_agent   TEXT NOT NULL DEFAULT '',
+    ip_address   TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    rotated_at   TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS sessions_family_id_idx ON sessions (family_id);
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
@@ -0,0 +1,8 @@
+-- email_verification_sent_at throttles resends of the verification email.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at          TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS email_verification_sent_at TIMESTAMPTZ;
+
+-- Accounts from before verification existed count as verified, so turning on
+-- REQUIRE_VERIFIED_EMAIL doesn't lock them out of chat.
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
@@ -0,0 +1,16 @@
+-- totp_secret is set on enrollment and only takes effect once confirmed
+-- (totp_enabled_at). totp_last_step stops a code being used twice.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS totp_secret     TEXT,
+    ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS totp_last_step  BIGINT;
+
+-- Single-use recovery codes, stored as SHA-256 hashes.
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    used_at    TIMESTAMPTZ,
+    UNIQUE (user_id, code_hash)
+);
@@ -0,0 +1,9 @@
+-- Failed login counters shared by every app instance. key is "email:<addr>",
+-- "ip:<addr>" or "mfa:<user id>"; locked_until is set once a key runs out
+-- of free attempts.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key             TEXT PRIMARY KEY,
+    failures        INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until    TIMESTAMPTZ
+);
@@ -0,0 +1,12 @@
+-- One row per client app. Only a SHA-256 of the key is stored; prefix is its
+-- first characters so keys can be told apart in listings and logs.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL,
+    key_hash     TEXT NOT NULL UNIQUE,
+    scopes       TEXT[] NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS oidc_logins;
+DROP TABLE IF EXISTS identities;
@@ -0,0 +1,25 @@
+-- External OpenID Connect accounts linked to users. A subject is only unique
+-- within its issuer, so the pair identifies the account.
+CREATE TABLE IF NOT EXISTS identities (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    issuer        TEXT NOT NULL,
+    subject       TEXT NOT NULL,
+    email         TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_login_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS identities_user_id_idx ON identities (user_id);
+
+-- Social logins in flight between /start and /callback. The PKCE verifier
+-- and nonce never leave the server; state is stored as a SHA-256 hash.
+CREATE TABLE IF NOT EXISTS oidc_logins (
+    state_hash    TEXT PRIMARY KEY,
+    provider      TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    nonce         TEXT NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at    TIMESTAMPTZ NOT NULL
+);
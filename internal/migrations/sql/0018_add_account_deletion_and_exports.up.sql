@@ -0,0 +1,26 @@
+-- deletion_scheduled_at is set when a user closes their account; the account
+-- and everything it owns is hard deleted once it passes, unless cleared first.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS users_deletion_scheduled_at_idx
+    ON users (deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+
+-- Personal data exports, built in the background. The zip archive is kept
+-- until expires_at so the user can download it.
+CREATE TABLE IF NOT EXISTS data_exports (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status       TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'ready', 'failed')),
+    archive      BYTEA,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    started_at   TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    expires_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS data_exports_user_id_idx ON data_exports (user_id);
+CREATE INDEX IF NOT EXISTS data_exports_pending_idx ON data_exports (created_at) WHERE status = 'pending';
+
+-- At most one export in flight per user, so concurrent requests share it.
+CREATE UNIQUE INDEX IF NOT EXISTS data_exports_user_active_idx
+    ON data_exports (user_id) WHERE status IN ('pending', 'running');
@@ -0,0 +1,20 @@
+-- role gates the /admin API. disabled_at blocks new sessions for the account.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role        TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin')),
+    ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ;
+
+-- Every admin action, including each break-glass read of a user's messages.
+-- Targets aren't foreign keys so entries outlive the accounts they mention.
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id       UUID REFERENCES users (id) ON DELETE SET NULL,
+    action         TEXT NOT NULL,
+    target_user_id UUID,
+    target_chat_id UUID,
+    reason         TEXT NOT NULL DEFAULT '',
+    ip_address     TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS admin_audit_log_created_at_idx ON admin_audit_log (created_at DESC);
+CREATE INDEX IF NOT EXISTS admin_audit_log_target_user_id_idx ON admin_audit_log (target_user_id);
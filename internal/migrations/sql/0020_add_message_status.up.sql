@@ -0,0 +1,5 @@
+-- How an assistant reply ended. Cancelled (client went away) and errored
+-- (model failed) replies keep whatever text streamed before they stopped.
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'complete'
+        CHECK (status IN ('complete', 'cancelled', 'errored'));
@@ -8,6 +8,12 @@ type User struct {
 	Email       string `json:"email"`
 	PhoneNumber string `json:"phone_number,omitempty"`
 	CreatedAt   string `json:"created_at,omitempty"`
+	// Set once the email address has been verified
+	EmailVerifiedAt *string `json:"email_verified_at,omitempty"`
+	// Set while the account is closed and waiting to be deleted
+	DeletionScheduledAt *string `json:"deletion_scheduled_at,omitempty"`
+	// "user" or "admin"; only returned by /me
+	Role string `json:"role,omitempty"`
 }
 
 // Response for /signup and /login
@@ -17,11 +23,68 @@ type AuthWithTokensResponse struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// Response for /login when the account has two-factor authentication;
+// exchange MFAToken and a code at /login/mfa for the token pair
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
+// Response for starting a social login; send the user to AuthorizationURL
+// and post the code and state it returns with to the callback
+type OIDCStartResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// Response listing the configured social login providers
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// Response for starting TOTP enrollment
+type TOTPEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// Response for confirming TOTP enrollment; the codes are only ever shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
 // Response for /auth
 type AuthCheckResponse struct {
 	User User `json:"user"`
 }
 
+// Response for GET and PATCH /me
+type MeResponse struct {
+	User User `json:"user"`
+}
+
+// Response for DELETE /me
+type AccountDeletionResponse struct {
+	Message             string `json:"message"`
+	DeletionScheduledAt string `json:"deletion_scheduled_at"`
+}
+
+// DataExport is a request for a copy of a user's data. Status goes pending,
+// running, then ready or failed; DownloadURL is set once it is ready.
+type DataExport struct {
+	ID          string  `json:"id"`
+	Status      string  `json:"status"`
+	CreatedAt   string  `json:"created_at"`
+	CompletedAt *string `json:"completed_at,omitempty"`
+	ExpiresAt   *string `json:"expires_at,omitempty"`
+	DownloadURL string  `json:"download_url,omitempty"`
+}
+
+// Response for requesting and polling a data export
+type DataExportResponse struct {
+	Export DataExport `json:"export"`
+}
+
 // Response for /token/refresh
 type TokenRefreshResponse struct {
 	AccessToken string `json:"access_token"`
@@ -0,0 +1,78 @@
+package models
+
+// AdminUser is an account as admins see it: no credentials, no content.
+type AdminUser struct {
+	ID                  string  `json:"id"`
+	FirstName           string  `json:"first_name"`
+	LastName            string  `json:"last_name"`
+	Email               string  `json:"email"`
+	Role                string  `json:"role"`
+	CreatedAt           string  `json:"created_at"`
+	EmailVerifiedAt     *string `json:"email_verified_at,omitempty"`
+	DisabledAt          *string `json:"disabled_at,omitempty"`
+	DeletionScheduledAt *string `json:"deletion_scheduled_at,omitempty"`
+	ChatCount           int     `json:"chat_count"`
+}
+
+// UserUsage counts what an account has done, without saying what it said.
+type UserUsage struct {
+	ChatCount         int     `json:"chat_count"`
+	UserMessages      int     `json:"user_messages"`
+	AssistantMessages int     `json:"assistant_messages"`
+	TokensUsed        int64   `json:"tokens_used"`
+	LastMessageAt     *string `json:"last_message_at,omitempty"`
+	ActiveSessions    int     `json:"active_sessions"`
+	LastLoginAt       *string `json:"last_login_at,omitempty"`
+}
+
+// Response for listing users. NextCursor is empty on the last page.
+type AdminUserListResponse struct {
+	Users      []AdminUser `json:"users"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// Response for a single user with usage stats
+type AdminUserResponse struct {
+	User  AdminUser `json:"user"`
+	Usage UserUsage `json:"usage"`
+}
+
+// AdminChat is a chat's metadata, without its messages.
+type AdminChat struct {
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+	Archived     bool   `json:"archived"`
+	MessageCount int    `json:"message_count"`
+}
+
+// Response for listing a user's chats
+type AdminChatListResponse struct {
+	Chats      []AdminChat `json:"chats"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// AuditEntry is one recorded admin action.
+type AuditEntry struct {
+	ID           string  `json:"id"`
+	AdminID      *string `json:"admin_id,omitempty"`
+	Action       string  `json:"action"`
+	TargetUserID *string `json:"target_user_id,omitempty"`
+	TargetChatID *string `json:"target_chat_id,omitempty"`
+	Reason       string  `json:"reason,omitempty"`
+	IPAddress    string  `json:"ip_address,omitempty"`
+	CreatedAt    string  `json:"created_at"`
+}
+
+// Response for reading the audit log. NextCursor is empty on the last page.
+type AuditLogResponse struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// Request body for admin actions on an account; the reason goes into the
+// audit log
+type AdminActionReq struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
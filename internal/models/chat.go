@@ -2,14 +2,50 @@ package models
 
 // Chat represents a conversation container owned by a user.
 type Chat struct {
-	ID        string `json:"id"`
-	Title     string `json:"title"`
-	CreatedAt string `json:"created_at"`
+	ID        string       `json:"id"`
+	Title     string       `json:"title"`
+	CreatedAt string       `json:"created_at"`
+	UpdatedAt string       `json:"updated_at"`
+	Pinned    bool         `json:"pinned"`
+	Archived  bool         `json:"archived"`
+	Settings  ChatSettings `json:"settings"`
+	PersonaID *string      `json:"persona_id,omitempty"`
+}
+
+// ChatSettings controls which model answers in a chat and how it generates.
+// Empty/nil fields fall back to the server defaults.
+type ChatSettings struct {
+	Model           string   `json:"model,omitempty"`
+	Temperature     *float32 `json:"temperature,omitempty"`
+	MaxOutputTokens *int     `json:"max_output_tokens,omitempty"`
+	TopP            *float32 `json:"top_p,omitempty"`
 }
 
 // Request body when creating a new chat
 type CreateChatReq struct {
-	Title string `json:"title" binding:"omitempty,max=120"`
+	Title     string        `json:"title" binding:"omitempty,max=120"`
+	Settings  *ChatSettings `json:"settings,omitempty"`
+	PersonaID *string       `json:"persona_id,omitempty"`
+}
+
+// Request body for PATCH /chats/:chat_id/settings (only provided fields change).
+// An empty persona_id detaches the chat's persona; fields listed in reset go
+// back to the provider defaults.
+type UpdateChatSettingsReq struct {
+	Model           *string  `json:"model,omitempty"`
+	Temperature     *float32 `json:"temperature,omitempty"`
+	MaxOutputTokens *int     `json:"max_output_tokens,omitempty"`
+	TopP            *float32 `json:"top_p,omitempty"`
+	PersonaID       *string  `json:"persona_id,omitempty"`
+	Reset           []string `json:"reset,omitempty" binding:"omitempty,dive,oneof=temperature max_output_tokens top_p"`
+}
+
+// Request body for PATCH /chats/:chat_id (only provided fields change).
+type UpdateChatReq struct {
+	Title    *string                `json:"title,omitempty" binding:"omitempty,min=1,max=120"`
+	Pinned   *bool                  `json:"pinned,omitempty"`
+	Archived *bool                  `json:"archived,omitempty"`
+	Settings *UpdateChatSettingsReq `json:"settings,omitempty"`
 }
 
 // Response for creating a chat
@@ -17,7 +53,26 @@ type ChatCreateResponse struct {
 	Chat Chat `json:"chat"`
 }
 
-// Response for listing chats
+// Response for updating a chat
+type ChatUpdateResponse struct {
+	Chat Chat `json:"chat"`
+}
+
+// Response for listing chats. NextCursor is empty on the last page.
 type ChatListResponse struct {
-	Chats []Chat `json:"chats"`
+	Chats      []Chat `json:"chats"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ChatSummary is the rolling summary of a chat's older messages.
+type ChatSummary struct {
+	ChatID       string `json:"chat_id"`
+	Content      string `json:"content"`
+	CoveredUntil string `json:"covered_until"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// Response for fetching a chat summary
+type ChatSummaryResponse struct {
+	Summary ChatSummary `json:"summary"`
 }
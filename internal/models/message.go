@@ -6,9 +6,18 @@ type Message struct {
 	ChatID    string `json:"chat_id"`
 	Role      string `json:"role"`   // "user" or "assistant"
 	Content   string `json:"content"`
+	Status    string `json:"status"` // how the reply ended: "complete", "cancelled" or "errored"
 	CreatedAt string `json:"created_at"`
 }
 
+// Message statuses. Cancelled and errored replies keep the text that
+// streamed before the client went away or the model failed.
+const (
+	MessageComplete  = "complete"
+	MessageCancelled = "cancelled"
+	MessageErrored   = "errored"
+)
+
 // Request body when sending a message
 type SendMessageReq struct {
 	Content string `json:"content" binding:"required"`
@@ -19,3 +28,9 @@ type MessageResponse struct {
 	UserMessage      Message `json:"user_message"`
 	AssistantMessage Message `json:"assistant_message"`
 }
+
+// Response for listing a chat's messages. NextCursor is empty on the last page.
+type MessageListResponse struct {
+	Messages   []Message `json:"messages"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,32 @@
+package models
+
+// Persona is a reusable system prompt a user can attach to chats.
+type Persona struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	SystemPrompt string `json:"system_prompt"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// Request body when creating a persona
+type CreatePersonaReq struct {
+	Name         string `json:"name" binding:"required,max=80"`
+	SystemPrompt string `json:"system_prompt" binding:"required,max=8000"`
+}
+
+// Request body when updating a persona (only provided fields change)
+type UpdatePersonaReq struct {
+	Name         *string `json:"name,omitempty" binding:"omitempty,min=1,max=80"`
+	SystemPrompt *string `json:"system_prompt,omitempty" binding:"omitempty,min=1,max=8000"`
+}
+
+// Response for creating, fetching or updating a persona
+type PersonaResponse struct {
+	Persona Persona `json:"persona"`
+}
+
+// Response for listing personas
+type PersonaListResponse struct {
+	Personas []Persona `json:"personas"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// SearchHit is a message or chat title matching a search query.
+// MessageID is empty when the chat title matched.
+type SearchHit struct {
+	ChatID    string  `json:"chat_id"`
+	ChatTitle string  `json:"chat_title"`
+	MessageID string  `json:"message_id,omitempty"`
+	Role      string  `json:"role,omitempty"`
+	Snippet   string  `json:"snippet"`
+	Rank      float32 `json:"rank"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// Response for GET /search. NextCursor is empty on the last page.
+type SearchResponse struct {
+	Hits       []SearchHit `json:"hits"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
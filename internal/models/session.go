@@ -0,0 +1,17 @@
+package models
+
+// Session is a signed-in device: one refresh token family.
+type Session struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IPAddress  string `json:"ip_address"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+	Current    bool   `json:"current"`
+}
+
+// Response for GET /sessions
+type SessionListResponse struct {
+	Sessions []Session `json:"sessions"`
+}
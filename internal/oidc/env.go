@@ -0,0 +1,49 @@
+package oidc
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// validName keeps provider names safe in routes and env variable names
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ProvidersFromEnv builds the providers listed in OIDC_PROVIDERS
+// (comma-separated names). Each name reads OIDC_<NAME>_ISSUER,
+// OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET, OIDC_<NAME>_REDIRECT_URL
+// and optionally OIDC_<NAME>_SCOPES (space-separated), with dashes in the
+// name written as underscores.
+func ProvidersFromEnv() (map[string]*Provider, error) {
+	providers := map[string]*Provider{}
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !validName.MatchString(name) {
+			return nil, fmt.Errorf("invalid OIDC provider name %q", name)
+		}
+		if _, dup := providers[name]; dup {
+			return nil, fmt.Errorf("OIDC provider %q listed twice", name)
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		cfg := Config{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+		for _, required := range []string{"ISSUER", "CLIENT_ID", "REDIRECT_URL"} {
+			if os.Getenv(prefix+required) == "" {
+				return nil, fmt.Errorf("%s%s not set", prefix, required)
+			}
+		}
+		providers[name] = NewProvider(cfg, nil)
+	}
+	return providers, nil
+}
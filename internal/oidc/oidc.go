@@ -0,0 +1,304 @@
+// Package oidc is a minimal OpenID Connect relying party: provider discovery,
+// the authorization code flow with PKCE, and ID token verification against
+// the provider's published keys.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultScopes are requested when a provider configures none
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// keyRefreshInterval limits how often an unknown kid triggers a JWKS refetch
+const keyRefreshInterval = time.Minute
+
+// clockSkew is tolerated on ID token time claims
+const clockSkew = time.Minute
+
+// defaultClient bounds calls to providers so a slow one can't hang logins
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// Config describes one OpenID Connect provider.
+type Config struct {
+	Name         string // slug used in routes, e.g. "google"
+	Issuer       string // discovery is read from Issuer + /.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string // where the provider sends the user back with a code
+	Scopes       []string
+}
+
+// metadata is the part of the discovery document we use
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider talks to one OpenID Connect provider. Discovery and keys are
+// fetched on first use and cached.
+type Provider struct {
+	Config
+	client *http.Client
+
+	mu            sync.Mutex
+	meta          *metadata
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// NewProvider creates a Provider. A nil client uses one with a 10s timeout.
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = defaultClient
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = DefaultScopes
+	}
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	return &Provider{Config: cfg, client: client}
+}
+
+// Claims are the ID token claims used to find or create the user.
+type Claims struct {
+	Email         string       `json:"email"`
+	EmailVerified flexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+	GivenName     string       `json:"given_name"`
+	FamilyName    string       `json:"family_name"`
+	Nonce         string       `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// flexibleBool accepts true or "true", since some providers send
+// email_verified as a string.
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	*b = flexibleBool(s == "true")
+	return nil
+}
+
+// Verified reports whether the provider vouches for the email address.
+func (c *Claims) Verified() bool {
+	return c.Email != "" && bool(c.EmailVerified)
+}
+
+// RandomToken returns a random URL-safe string for state, nonce and PKCE
+// verifiers.
+func RandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge is the S256 PKCE challenge for verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the provider URL the user signs in at.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"scope":                 {strings.Join(p.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the verified claims of
+// the ID token that came with it. nonce must match the one sent with the
+// authorization request.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.getJSON(req, &tokens)
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("token request: %d %s %s", status, tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return p.Verify(ctx, tokens.IDToken, nonce)
+}
+
+// Verify checks an ID token's signature, issuer, audience, expiry and nonce.
+func (p *Provider) Verify(ctx context.Context, idToken, nonce string) (*Claims, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.ParseWithClaims(idToken, &Claims{}, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("id token: invalid")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("id token: no subject")
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("id token: nonce mismatch")
+	}
+	return claims, nil
+}
+
+// discover fetches and caches the provider's discovery document.
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var meta metadata
+	status, err := p.getJSON(req, &meta)
+	if err != nil || status != http.StatusOK {
+		return nil, fmt.Errorf("discovery for %s failed: status %d, %v", p.Name, status, err)
+	}
+	// A document claiming another issuer could mint tokens for it
+	if strings.TrimSuffix(meta.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("discovery for %s: issuer %q does not match %q", p.Name, meta.Issuer, p.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery for %s: incomplete document", p.Name)
+	}
+	p.meta = &meta
+	return p.meta, nil
+}
+
+// key returns the provider key named kid, refetching the key set when kid is
+// unknown since providers rotate keys.
+func (p *Provider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	if time.Since(p.keysFetchedAt) < keyRefreshInterval {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.meta.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set jwkSet
+	status, err := p.getJSON(req, &set)
+	if err != nil || status != http.StatusOK {
+		return nil, fmt.Errorf("fetch keys for %s failed: status %d, %v", p.Name, status, err)
+	}
+	p.keys = set.publicKeys()
+	p.keysFetchedAt = time.Now()
+
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// lookupKey finds kid in the cached keys. Tokens without a kid are accepted
+// when the provider publishes a single key.
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	k, ok := p.keys[kid]
+	return k, ok
+}
+
+// getJSON sends req and decodes a JSON body of at most 1 MiB into v.
+func (p *Provider) getJSON(req *http.Request, v any) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
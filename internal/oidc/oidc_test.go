@@ -0,0 +1,144 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"personal-assistant-backend/internal/oidc/oidctest"
+)
+
+func stubProvider(iss *oidctest.Issuer) *Provider {
+	return NewProvider(Config{
+		Name:         "stub",
+		Issuer:       iss.URL,
+		ClientID:     iss.ClientID,
+		ClientSecret: iss.ClientSecret,
+		RedirectURL:  "https://app.example.com/auth/callback",
+	}, nil)
+}
+
+// signIn runs the browser half of the flow and returns the code
+func signIn(t *testing.T, iss *oidctest.Issuer, p *Provider, state, nonce, verifier string) string {
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, verifier)
+	require.NoError(t, err)
+
+	code, returnedState := iss.Authorize(t, authURL)
+	assert.Equal(t, state, returnedState)
+	return code
+}
+
+// --- TESTS ---
+
+func TestProvider_CodeFlow(t *testing.T) {
+	iss := oidctest.NewIssuer(t)
+	p := stubProvider(iss)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	require.NoError(t, err)
+	u, _ := url.Parse(authURL)
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+	assert.Equal(t, CodeChallenge("verifier-1"), u.Query().Get("code_challenge"))
+	assert.Equal(t, "openid email profile", u.Query().Get("scope"))
+
+	code := signIn(t, iss, p, "state-1", "nonce-1", "verifier-1")
+	claims, err := p.Exchange(context.Background(), code, "verifier-1", "nonce-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "subject-1", claims.Subject)
+	assert.Equal(t, "jane@example.com", claims.Email)
+	assert.True(t, claims.Verified())
+	assert.Equal(t, "Jane", claims.GivenName)
+}
+
+func TestProvider_RejectsWrongVerifier(t *testing.T) {
+	iss := oidctest.NewIssuer(t)
+	p := stubProvider(iss)
+
+	code := signIn(t, iss, p, "state-1", "nonce-1", "verifier-1")
+	_, err := p.Exchange(context.Background(), code, "someone-elses-verifier", "nonce-1")
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestProvider_RejectsNonceMismatch(t *testing.T) {
+	iss := oidctest.NewIssuer(t)
+	iss.Nonce = "replayed-nonce"
+	p := stubProvider(iss)
+
+	code := signIn(t, iss, p, "state-1", "nonce-1", "verifier-1")
+	_, err := p.Exchange(context.Background(), code, "verifier-1", "nonce-1")
+	assert.ErrorContains(t, err, "nonce mismatch")
+}
+
+func TestProvider_RejectsMismatchedIssuer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"issuer":"https://evil.example.com","authorization_endpoint":"x","token_endpoint":"y","jwks_uri":"z"}`))
+	}))
+	defer srv.Close()
+
+	p := NewProvider(Config{Name: "bad", Issuer: srv.URL, ClientID: "c", RedirectURL: "r"}, nil)
+	_, err := p.AuthCodeURL(context.Background(), "s", "n", "v")
+	assert.ErrorContains(t, err, "does not match")
+}
+
+func TestJWK_ParsesECAndSkipsUnsupported(t *testing.T) {
+	priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	b64 := base64.RawURLEncoding.EncodeToString
+	set := jwkSet{Keys: []jwk{
+		{Kty: "EC", Kid: "ec", Crv: "P-256", X: b64(priv.X.Bytes()), Y: b64(priv.Y.Bytes())},
+		{Kty: "EC", Kid: "off-curve", Crv: "P-256", X: b64([]byte{1}), Y: b64([]byte{2})},
+		{Kty: "oct", Kid: "symmetric", N: "secret"},
+		{Kty: "OKP", Kid: "enc", Use: "enc", Crv: "Ed25519", X: b64(make([]byte, 32))},
+	}}
+
+	keys := set.publicKeys()
+	assert.Len(t, keys, 1)
+	assert.True(t, priv.PublicKey.Equal(keys["ec"]))
+}
+
+func TestFlexibleBool(t *testing.T) {
+	var c Claims
+	for body, want := range map[string]bool{
+		`{"email":"a@b.c","email_verified":true}`:    true,
+		`{"email":"a@b.c","email_verified":"true"}`:  true,
+		`{"email":"a@b.c","email_verified":"false"}`: false,
+		`{"email":"a@b.c"}`:                          false,
+	} {
+		c = Claims{}
+		require.NoError(t, json.Unmarshal([]byte(body), &c))
+		assert.Equal(t, want, c.Verified(), body)
+	}
+}
+
+func TestProvidersFromEnv(t *testing.T) {
+	t.Setenv("OIDC_PROVIDERS", "google, my-idp")
+	t.Setenv("OIDC_GOOGLE_ISSUER", "https://accounts.google.com/")
+	t.Setenv("OIDC_GOOGLE_CLIENT_ID", "gid")
+	t.Setenv("OIDC_GOOGLE_CLIENT_SECRET", "gsecret")
+	t.Setenv("OIDC_GOOGLE_REDIRECT_URL", "https://app.example.com/callback")
+	t.Setenv("OIDC_MY_IDP_ISSUER", "https://idp.example.com")
+	t.Setenv("OIDC_MY_IDP_CLIENT_ID", "mid")
+	t.Setenv("OIDC_MY_IDP_REDIRECT_URL", "https://app.example.com/callback")
+	t.Setenv("OIDC_MY_IDP_SCOPES", "openid email")
+
+	providers, err := ProvidersFromEnv()
+	require.NoError(t, err)
+	assert.Len(t, providers, 2)
+	assert.Equal(t, "https://accounts.google.com", providers["google"].Issuer)
+	assert.Equal(t, DefaultScopes, providers["google"].Scopes)
+	assert.Equal(t, []string{"openid", "email"}, providers["my-idp"].Scopes)
+
+	t.Setenv("OIDC_MY_IDP_CLIENT_ID", "")
+	_, err = ProvidersFromEnv()
+	assert.ErrorContains(t, err, "OIDC_MY_IDP_CLIENT_ID not set")
+}
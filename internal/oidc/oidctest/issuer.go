@@ -0,0 +1,198 @@
+// Package oidctest runs a stub OpenID Connect issuer for tests: discovery,
+// JWKS, an authorize endpoint that signs in a preset user, and a token
+// endpoint that checks PKCE before minting an RS256 ID token.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// User is who the stub signs in at /authorize.
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+// Issuer is a running stub provider.
+type Issuer struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+	// User is signed in by the next /authorize call
+	User User
+	// Nonce overrides the nonce put in ID tokens when set
+	Nonce string
+
+	key   *rsa.PrivateKey
+	kid   string
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+// grant is an issued authorization code waiting to be redeemed
+type grant struct {
+	user        User
+	challenge   string
+	nonce       string
+	redirectURI string
+}
+
+// NewIssuer starts a stub issuer that lives until the test ends.
+func NewIssuer(t *testing.T) *Issuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate issuer key: %v", err)
+	}
+
+	iss := &Issuer{
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+		User:         User{Subject: "subject-1", Email: "jane@example.com", EmailVerified: true, GivenName: "Jane", FamilyName: "Doe"},
+		key:          key,
+		kid:          "stub-key-1",
+		codes:        map[string]grant{},
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/.well-known/openid-configuration", iss.discovery)
+	r.GET("/jwks", iss.jwks)
+	r.GET("/authorize", iss.authorize)
+	r.POST("/token", iss.token)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	iss.URL = srv.URL
+	return iss
+}
+
+// Authorize follows authURL like a browser would and returns the code and
+// state the stub redirects back with.
+func (iss *Issuer) Authorize(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned %d", resp.StatusCode)
+	}
+
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("bad redirect: %v", err)
+	}
+	return loc.Query().Get("code"), loc.Query().Get("state")
+}
+
+func (iss *Issuer) discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                 iss.URL,
+		"authorization_endpoint": iss.URL + "/authorize",
+		"token_endpoint":         iss.URL + "/token",
+		"jwks_uri":               iss.URL + "/jwks",
+	})
+}
+
+func (iss *Issuer) jwks(c *gin.Context) {
+	pub := iss.key.PublicKey
+	c.JSON(http.StatusOK, gin.H{"keys": []gin.H{{
+		"kty": "RSA",
+		"use": "sig",
+		"alg": "RS256",
+		"kid": iss.kid,
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+func (iss *Issuer) authorize(c *gin.Context) {
+	if c.Query("client_id") != iss.ClientID || c.Query("response_type") != "code" ||
+		c.Query("code_challenge_method") != "S256" || c.Query("code_challenge") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+
+	code := rand.Text()
+	iss.mu.Lock()
+	iss.codes[code] = grant{
+		user:        iss.User,
+		challenge:   c.Query("code_challenge"),
+		nonce:       c.Query("nonce"),
+		redirectURI: c.Query("redirect_uri"),
+	}
+	iss.mu.Unlock()
+
+	redirect, _ := url.Parse(c.Query("redirect_uri"))
+	q := redirect.Query()
+	q.Set("code", code)
+	q.Set("state", c.Query("state"))
+	redirect.RawQuery = q.Encode()
+	c.Redirect(http.StatusFound, redirect.String())
+}
+
+func (iss *Issuer) token(c *gin.Context) {
+	id, secret, ok := c.Request.BasicAuth()
+	if !ok || id != iss.ClientID || secret != iss.ClientSecret {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+		return
+	}
+
+	iss.mu.Lock()
+	g, found := iss.codes[c.PostForm("code")]
+	delete(iss.codes, c.PostForm("code"))
+	iss.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(c.PostForm("code_verifier")))
+	if !found || c.PostForm("grant_type") != "authorization_code" ||
+		c.PostForm("redirect_uri") != g.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+		return
+	}
+
+	nonce := g.nonce
+	if iss.Nonce != "" {
+		nonce = iss.Nonce
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            iss.URL,
+		"aud":            iss.ClientID,
+		"sub":            g.user.Subject,
+		"email":          g.user.Email,
+		"email_verified": g.user.EmailVerified,
+		"given_name":     g.user.GivenName,
+		"family_name":    g.user.FamilyName,
+		"nonce":          nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	})
+	token.Header["kid"] = iss.kid
+	idToken, err := token.SignedString(iss.key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access_token": "stub-access-token", "token_type": "Bearer", "id_token": idToken})
+}
@@ -0,0 +1,82 @@
+// Package pagination holds the keyset cursors and page sizes shared by the
+// list endpoints. A cursor is the opaque (created_at, id) position of the
+// last row a client has seen; id breaks ties between rows created in the same
+// microsecond.
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+// errMalformed hides why a cursor didn't parse; clients should treat it as opaque.
+var errMalformed = errors.New("malformed cursor")
+
+// Cursor is a decoded (created_at, id) position.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Encode builds the opaque cursor for a row; createdAt is RFC 3339.
+func Encode(createdAt, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt + "|" + id))
+}
+
+// EncodeTime is Encode for a created_at that hasn't been formatted yet.
+func EncodeTime(createdAt time.Time, id string) string {
+	return Encode(createdAt.Format(time.RFC3339Nano), id)
+}
+
+// Decode parses a cursor produced by Encode; "" means no cursor and gives nil.
+func Decode(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errMalformed
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, errMalformed
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, errMalformed
+	}
+	return &Cursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// Args returns the values bound to a keyset condition's created_at and id
+// placeholders; both are nil without a cursor.
+func (c *Cursor) Args() []any {
+	if c == nil {
+		return []any{nil, nil}
+	}
+	return []any{c.CreatedAt, c.ID}
+}
+
+// ParseLimit reads ?limit=, defaulting to DefaultPageSize.
+func ParseLimit(c *gin.Context) (int, error) {
+	v := c.Query("limit")
+	if v == "" {
+		return DefaultPageSize, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > MaxPageSize {
+		return 0, fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+	}
+	return n, nil
+}
@@ -0,0 +1,53 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, 10, 16, 8, 0, 0, 123456000, time.UTC)
+
+	cur, err := Decode(EncodeTime(createdAt, "user-9"))
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(cur.CreatedAt))
+	assert.Equal(t, "user-9", cur.ID)
+	assert.Equal(t, []any{cur.CreatedAt, "user-9"}, cur.Args())
+
+	cur, err = Decode("")
+	assert.NoError(t, err)
+	assert.Nil(t, cur)
+	assert.Equal(t, []any{nil, nil}, cur.Args())
+
+	for _, s := range []string{"not-a-cursor!", Encode("yesterday", "c1"), Encode(createdAt.Format(time.RFC3339), "")} {
+		_, err = Decode(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limit := func(query string) (int, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?"+query, nil)
+		return ParseLimit(c)
+	}
+
+	n, err := limit("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPageSize, n)
+
+	n, err = limit("limit=7")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, n)
+
+	for _, q := range []string{"limit=0", "limit=101", "limit=abc"} {
+		_, err = limit(q)
+		assert.Error(t, err, q)
+	}
+}
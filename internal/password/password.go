@@ -0,0 +1,195 @@
+// Package password hashes passwords with argon2id in the PHC string format
+// ($argon2id$v=19$m=...,t=...,p=...$salt$hash) and still verifies the bcrypt
+// hashes stored before it, so accounts can be upgraded as their users log in.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Params are the argon2id cost settings new hashes are made with.
+type Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultParams follow the RFC 9106 recommendation for memory-constrained
+// servers: 64 MiB, 3 passes.
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Default hashes with DefaultParams.
+var Default = New(DefaultParams)
+
+// ErrUnsupported means a stored hash is in no format this package knows.
+var ErrUnsupported = errors.New("unsupported password hash")
+
+// Validate rejects parameters argon2id can't run with or that are too weak
+// to be worth storing.
+func (p Params) Validate() error {
+	switch {
+	case p.Iterations < 1:
+		return errors.New("argon2id iterations must be at least 1")
+	case p.Parallelism < 1:
+		return errors.New("argon2id parallelism must be at least 1")
+	case p.Memory < 8*uint32(p.Parallelism):
+		return errors.New("argon2id memory must be at least 8 KiB per lane")
+	case p.SaltLength < 8:
+		return errors.New("salt must be at least 8 bytes")
+	case p.KeyLength < 16:
+		return errors.New("key must be at least 16 bytes")
+	}
+	return nil
+}
+
+// Hasher hashes and verifies passwords.
+type Hasher struct {
+	params Params
+	dummy  func() string
+}
+
+// New returns a Hasher making argon2id hashes with p.
+func New(p Params) *Hasher {
+	h := &Hasher{params: p}
+	h.dummy = sync.OnceValue(func() string {
+		hash, _ := h.Hash(rand.Text())
+		return hash
+	})
+	return h
+}
+
+// FromEnv builds a Hasher from PASSWORD_ARGON2_MEMORY_KIB,
+// PASSWORD_ARGON2_ITERATIONS and PASSWORD_ARGON2_PARALLELISM, each falling
+// back to DefaultParams when unset.
+func FromEnv() (*Hasher, error) {
+	p := DefaultParams
+	for _, v := range []struct {
+		name string
+		bits int
+		set  func(uint64)
+	}{
+		{"PASSWORD_ARGON2_MEMORY_KIB", 32, func(n uint64) { p.Memory = uint32(n) }},
+		{"PASSWORD_ARGON2_ITERATIONS", 32, func(n uint64) { p.Iterations = uint32(n) }},
+		{"PASSWORD_ARGON2_PARALLELISM", 8, func(n uint64) { p.Parallelism = uint8(n) }},
+	} {
+		s := os.Getenv(v.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(s, 10, v.bits)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", v.name, s)
+		}
+		v.set(n)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return New(p), nil
+}
+
+// Params returns the parameters new hashes are made with.
+func (h *Hasher) Params() Params {
+	return h.params
+}
+
+// Hash returns the PHC-formatted argon2id hash of password with a fresh salt.
+func (h *Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	p := h.params
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify reports whether password matches hash, which may be argon2id or
+// legacy bcrypt. A mismatch is not an error; a malformed hash is.
+func (h *Hasher) Verify(hash, password string) (bool, error) {
+	if isBcrypt(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	p, salt, key, err := decode(hash)
+	if err != nil {
+		return false, err
+	}
+	got := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(got, key) == 1, nil
+}
+
+// NeedsRehash reports whether hash should be replaced by a fresh Hash of
+// the same password: it is bcrypt, or argon2id with other parameters.
+func (h *Hasher) NeedsRehash(hash string) bool {
+	p, salt, key, err := decode(hash)
+	if err != nil {
+		return true
+	}
+	p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+	return p != h.params
+}
+
+// Dummy returns the hash of a random password, made with h's parameters,
+// for checking against when there is no real hash so misses take as long
+// as wrong passwords.
+func (h *Hasher) Dummy() string {
+	return h.dummy()
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// decode parses a PHC argon2id string
+func decode(hash string) (p Params, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return p, nil, nil, ErrUnsupported
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrUnsupported
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: bad parameters", ErrUnsupported)
+	}
+	if p.Iterations < 1 || p.Parallelism < 1 {
+		return p, nil, nil, fmt.Errorf("%w: bad parameters", ErrUnsupported)
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: bad salt", ErrUnsupported)
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return p, nil, nil, fmt.Errorf("%w: bad key", ErrUnsupported)
+	}
+	return p, salt, key, nil
+}
@@ -0,0 +1,114 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cheap keeps tests fast; production uses DefaultParams or the env
+var cheap = Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+// --- TESTS ---
+
+func TestHasher_HashAndVerify(t *testing.T) {
+	h := New(cheap)
+
+	hash, err := h.Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"), hash)
+
+	ok, err := h.Verify(hash, "correct horse")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify(hash, "wrong horse")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Fresh salt every time
+	again, _ := h.Hash("correct horse")
+	assert.NotEqual(t, hash, again)
+}
+
+func TestHasher_VerifiesOtherParams(t *testing.T) {
+	// Hashes keep their own parameters, so changing the config doesn't lock anyone out
+	old, _ := New(Params{Memory: 32, Iterations: 2, Parallelism: 2, SaltLength: 8, KeyLength: 16}).Hash("pw")
+
+	ok, err := New(cheap).Verify(old, "pw")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestHasher_VerifiesLegacyBcrypt(t *testing.T) {
+	h := New(cheap)
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("supersecret"), bcrypt.MinCost)
+
+	ok, err := h.Verify(string(legacy), "supersecret")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify(string(legacy), "nope")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestHasher_RejectsMalformed(t *testing.T) {
+	h := New(cheap)
+	for _, hash := range []string{
+		"",
+		"plaintext",
+		"$argon2i$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5",
+	} {
+		ok, err := h.Verify(hash, "x")
+		assert.False(t, ok, hash)
+		assert.ErrorIs(t, err, ErrUnsupported, hash)
+	}
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	h := New(cheap)
+	current, _ := h.Hash("pw")
+	old, _ := New(Params{Memory: 32, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}).Hash("pw")
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+
+	assert.False(t, h.NeedsRehash(current))
+	assert.True(t, h.NeedsRehash(old))
+	assert.True(t, h.NeedsRehash(string(legacy)))
+	assert.True(t, h.NeedsRehash("garbage"))
+}
+
+func TestHasher_Dummy(t *testing.T) {
+	h := New(cheap)
+
+	assert.Equal(t, h.Dummy(), h.Dummy())
+	assert.False(t, h.NeedsRehash(h.Dummy()))
+}
+
+func TestFromEnv(t *testing.T) {
+	h, err := FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultParams, h.Params())
+
+	t.Setenv("PASSWORD_ARGON2_MEMORY_KIB", "19456")
+	t.Setenv("PASSWORD_ARGON2_ITERATIONS", "2")
+	t.Setenv("PASSWORD_ARGON2_PARALLELISM", "1")
+	h, err = FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Params{Memory: 19456, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}, h.Params())
+
+	t.Setenv("PASSWORD_ARGON2_PARALLELISM", "300")
+	_, err = FromEnv()
+	assert.ErrorContains(t, err, "invalid PASSWORD_ARGON2_PARALLELISM")
+
+	t.Setenv("PASSWORD_ARGON2_PARALLELISM", "1")
+	t.Setenv("PASSWORD_ARGON2_ITERATIONS", "0")
+	_, err = FromEnv()
+	assert.ErrorContains(t, err, "iterations")
+}
@@ -0,0 +1,65 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 6238 appendix B test secret ("12345678901234567890")
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; ours are their last 6 digits
+	for unix, want := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, want, code, unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := Code(rfcSecret, Step(now))
+
+	step, ok := Validate(rfcSecret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// One step of clock drift is tolerated, two are not
+	_, ok = Validate(rfcSecret, code, now.Add(Period))
+	assert.True(t, ok)
+	_, ok = Validate(rfcSecret, code, now.Add(2*Period))
+	assert.False(t, ok)
+
+	_, ok = Validate(rfcSecret, "000000", now)
+	assert.False(t, ok)
+	_, ok = Validate(rfcSecret, "12345", now)
+	assert.False(t, ok)
+	_, ok = Validate("not base32!", code, now)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	_, err = Code(secret, 1)
+	assert.NoError(t, err)
+
+	uri := URI("Personal Assistant", "jane@example.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Personal%20Assistant:jane@example.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "issuer=Personal+Assistant")
+}
@@ -1,18 +1,34 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
+	"personal-assistant-backend/internal/accountdata"
+	"personal-assistant-backend/internal/apikeys"
 	"personal-assistant-backend/internal/config"
 	"personal-assistant-backend/internal/handlers"
+	adminHandler "personal-assistant-backend/internal/handlers/admin"
 	chatHandler "personal-assistant-backend/internal/handlers/chat"
+	personaHandler "personal-assistant-backend/internal/handlers/persona"
+	"personal-assistant-backend/internal/jwtkeys"
+	"personal-assistant-backend/internal/llm"
+	"personal-assistant-backend/internal/mail"
 	"personal-assistant-backend/internal/middleware"
+	"personal-assistant-backend/internal/migrations"
+	"personal-assistant-backend/internal/oidc"
+	"personal-assistant-backend/internal/password"
 	"personal-assistant-backend/docs"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -67,11 +83,96 @@ func main() {
 	log.Println("✅ Connected to database")
 
 	// =====================================================
-	// 🔑 API Key
+	// 🧬 Schema Migrations
 	// =====================================================
-	apiKey := os.Getenv("API_KEY")
-	if apiKey == "" {
-		log.Fatal("❌ API_KEY not set")
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatal("❌ Failed to load migrations:", err)
+	}
+
+	// `migrate up|down [steps]|status` runs migrations and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(migrator, os.Args[2:])
+		return
+	}
+
+	if os.Getenv("SKIP_MIGRATIONS") != "true" {
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Fatal("❌ Failed to run migrations:", err)
+		}
+		log.Printf("✅ Migrations up to date (%d applied)\n", applied)
+	}
+
+	// =====================================================
+	// 🔑 API Keys
+	// =====================================================
+	// API_KEY is the old single shared key; clients should move to their own
+	apiKeys := apikeys.NewStore(db, os.Getenv("API_KEY"))
+	if os.Getenv("API_KEY") != "" {
+		log.Println("⚠️ API_KEY is deprecated; mint per-client keys with `apikey create`")
+	}
+
+	// `apikey create <name> <scopes>|list|revoke <id>` manages client keys and exits
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		runAPIKeyCommand(apiKeys, os.Args[2:])
+		return
+	}
+
+	// =====================================================
+	// 👑 User Roles
+	// =====================================================
+	// `role <email> <user|admin> [reason]` changes a user's role and exits
+	if len(os.Args) > 1 && os.Args[1] == "role" {
+		runRoleCommand(db, os.Args[2:])
+		return
+	}
+
+	// =====================================================
+	// 🔏 JWT Signing Keys
+	// =====================================================
+	jwtKeys, err := jwtkeys.Current()
+	if err != nil {
+		log.Fatal("❌ Failed to load JWT keys:", err)
+	}
+	log.Printf("✅ JWT keys loaded (%d published)\n", len(jwtKeys.JWKS().Keys))
+	if until := jwtKeys.LegacySecretUntil(); until.After(time.Now()) {
+		log.Printf("⚠️ Legacy HS256 tokens signed with JWT_SECRET are accepted until %s; remove JWT_SECRET once they have expired\n", until.Format(time.RFC3339))
+	}
+
+	// =====================================================
+	// 🧂 Password Hashing
+	// =====================================================
+	passwords, err := password.FromEnv()
+	if err != nil {
+		log.Fatal("❌ Failed to configure password hashing:", err)
+	}
+
+	// =====================================================
+	// 🤖 LLM Provider
+	// =====================================================
+	provider, err := llm.NewFromEnv()
+	if err != nil {
+		log.Fatal("❌ Failed to configure LLM provider:", err)
+	}
+
+	// =====================================================
+	// 📧 Mail Sender
+	// =====================================================
+	mailer, err := mail.NewFromEnv()
+	if err != nil {
+		log.Fatal("❌ Failed to configure mail sender:", err)
+	}
+
+	// =====================================================
+	// 🌍 Social Login Providers
+	// =====================================================
+	oidcProviders, err := oidc.ProvidersFromEnv()
+	if err != nil {
+		log.Fatal("❌ Failed to configure OIDC providers:", err)
+	}
+	if len(oidcProviders) > 0 {
+		log.Printf("✅ Social login enabled (%d providers)\n", len(oidcProviders))
 	}
 
 	// =====================================================
@@ -99,20 +200,28 @@ func main() {
 	if isLocal {
 		r.Use(func(c *gin.Context) {
 			path := c.Request.URL.Path
-			// Allow Swagger & /hello without API key
+			// Allow Swagger, /hello & the JWKS without API key
 			if path == "/hello" ||
+				path == handlers.JWKSPath ||
 				path == "/swagger" ||
 				path == "/swagger/" ||
 				len(path) >= 9 && path[:9] == "/swagger/" {
 				c.Next()
 				return
 			}
-			middleware.APIKeyAuthMiddleware(apiKey)(c)
+			middleware.APIKeyAuthMiddleware(apiKeys)(c)
 		})
 		log.Println("🧩 Local mode: Swagger + /hello are open (no API key needed)")
 	} else {
-		r.Use(middleware.APIKeyAuthMiddleware(apiKey))
-		log.Println("🔒 Production mode: All routes protected by API key")
+		r.Use(func(c *gin.Context) {
+			// Public keys are public; verifiers shouldn't need our API key
+			if c.Request.URL.Path == handlers.JWKSPath {
+				c.Next()
+				return
+			}
+			middleware.APIKeyAuthMiddleware(apiKeys)(c)
+		})
+		log.Println("🔒 Production mode: All routes but the JWKS protected by API key")
 	}
 
 	// =====================================================
@@ -123,34 +232,117 @@ func main() {
 	// =====================================================
 	// 🧩 Initialize Handlers
 	// =====================================================
-	auth := handlers.NewAuthHandler(db)
-	chats := chatHandler.NewChatHandler(db)
+	auth := handlers.NewAuthHandler(db, mailer, passwords)
+	oidcLogin := handlers.NewOIDCHandler(auth, oidcProviders)
+	chats := chatHandler.NewChatHandler(db, provider)
+	personas := personaHandler.NewPersonaHandler(db)
+	admin := adminHandler.NewAdminHandler(db)
 
 	// =====================================================
 	// 🚪 Public Auth Routes
 	// =====================================================
-	r.POST("/signup", auth.Signup)
-	r.POST("/login", auth.Login)
-	r.POST("/token/refresh", auth.Refresh)
+	r.GET(handlers.JWKSPath, handlers.JWKSHandler)
+
+	publicAuth := r.Group("/")
+	publicAuth.Use(middleware.RequireAPIScope(apikeys.ScopeAuth))
+
+	publicAuth.POST("/signup", auth.Signup)
+	publicAuth.POST("/login", auth.Login)
+	publicAuth.POST("/login/mfa", auth.LoginMFA)
+	publicAuth.POST("/token/refresh", auth.Refresh)
+	publicAuth.POST("/password/forgot", auth.ForgotPassword)
+	publicAuth.POST("/password/reset", auth.ResetPassword)
+	publicAuth.GET("/email/verify", auth.VerifyEmail)
+	publicAuth.POST("/email/verify", auth.VerifyEmail)
+	publicAuth.GET("/auth/oidc/providers", oidcLogin.ListProviders)
+	publicAuth.POST("/auth/oidc/:provider/start", oidcLogin.Start)
+	publicAuth.POST("/auth/oidc/:provider/callback", oidcLogin.Callback)
 
 	// =====================================================
 	// 🔒 Protected Routes (JWT)
 	// =====================================================
+	jwtAuth := middleware.JWTAuthMiddleware(db)
+
+	// --- Account routes need the account scope
 	authGroup := r.Group("/")
-	authGroup.Use(middleware.JWTAuthMiddleware())
+	authGroup.Use(middleware.RequireAPIScope(apikeys.ScopeAccount), jwtAuth)
 
 	// --- Auth check (on app startup)
 	authGroup.GET("/auth", auth.AuthCheck)
 
 	// --- Current user info
 	authGroup.GET("/me", auth.Me)
+	authGroup.PATCH("/me", auth.UpdateMe)
+	authGroup.POST("/me/password", auth.ChangePassword)
+
+	// --- Account deletion & data export
+	authGroup.DELETE("/me", auth.DeleteMe)
+	authGroup.DELETE("/me/deletion", auth.CancelDeletion)
+	authGroup.POST("/me/export", auth.RequestExport)
+	authGroup.GET("/me/export/:export_id", auth.GetExport)
+	authGroup.GET("/me/export/:export_id/download", auth.DownloadExport)
+
+	// --- Two-factor authentication
+	authGroup.POST("/me/mfa/totp", auth.EnrollTOTP)
+	authGroup.POST("/me/mfa/totp/confirm", auth.ConfirmTOTP)
+	authGroup.DELETE("/me/mfa/totp", auth.DisableTOTP)
+	authGroup.POST("/email/verify/resend", auth.ResendVerification)
+
+	// --- Sessions
+	authGroup.POST("/logout", auth.Logout)
+	authGroup.GET("/sessions", auth.ListSessions)
+	authGroup.DELETE("/sessions", auth.RevokeOtherSessions)
+	authGroup.DELETE("/sessions/:session_id", auth.RevokeSession)
 
-	// --- Chat routes
-	authGroup.POST("/chats", chats.CreateChat)
-	authGroup.GET("/chats", chats.ListChats)
-	authGroup.POST("/chats/:chat_id/messages", chats.SendMessage)
-	authGroup.GET("/chats/:chat_id/messages", chats.ListMessages)
-	authGroup.DELETE("/chats/:chat_id", chats.DeleteChat)
+	// --- Chat, search and persona routes need the chat scope
+	chatScoped := r.Group("/")
+	chatScoped.Use(middleware.RequireAPIScope(apikeys.ScopeChat), jwtAuth)
+
+	// --- Chat routes (optionally limited to verified emails)
+	chatGroup := chatScoped.Group("/")
+	if config.RequireVerifiedEmail() {
+		chatGroup.Use(middleware.RequireVerifiedEmail(db))
+		log.Println("✉️ Chat routes require a verified email")
+	}
+
+	chatGroup.POST("/chats", chats.CreateChat)
+	chatGroup.GET("/chats", chats.ListChats)
+	chatGroup.POST("/chats/:chat_id/messages", chats.SendMessage)
+	chatGroup.GET("/chats/:chat_id/messages", chats.ListMessages)
+	chatGroup.DELETE("/chats/:chat_id", chats.DeleteChat)
+	chatGroup.PATCH("/chats/:chat_id", chats.UpdateChat)
+	chatGroup.PATCH("/chats/:chat_id/settings", chats.UpdateChatSettings)
+	chatGroup.GET("/chats/:chat_id/summary", chats.GetChatSummary)
+
+	// --- Search
+	chatGroup.GET("/search", chats.Search)
+
+	// --- Persona routes
+	chatScoped.POST("/personas", personas.CreatePersona)
+	chatScoped.GET("/personas", personas.ListPersonas)
+	chatScoped.GET("/personas/:persona_id", personas.GetPersona)
+	chatScoped.PATCH("/personas/:persona_id", personas.UpdatePersona)
+	chatScoped.DELETE("/personas/:persona_id", personas.DeletePersona)
+
+	// =====================================================
+	// 🛡️ Admin Routes (JWT + admin role)
+	// =====================================================
+	adminGroup := r.Group("/admin")
+	adminGroup.Use(middleware.RequireAPIScope(apikeys.ScopeAccount), jwtAuth, middleware.RequireRole(db, handlers.RoleAdmin))
+
+	adminGroup.GET("/users", admin.ListUsers)
+	adminGroup.GET("/users/:user_id", admin.GetUser)
+	adminGroup.POST("/users/:user_id/disable", admin.DisableUser)
+	adminGroup.POST("/users/:user_id/enable", admin.EnableUser)
+	adminGroup.POST("/users/:user_id/logout", admin.LogoutUser)
+	adminGroup.GET("/users/:user_id/chats", admin.ListUserChats)
+	adminGroup.GET("/audit", admin.AuditLog)
+
+	// --- Message contents only with break-glass on; every read is audited
+	adminGroup.GET("/users/:user_id/chats/:chat_id/messages", admin.ReadMessages)
+	if config.AdminBreakGlass() {
+		log.Println("⚠️ ADMIN_BREAK_GLASS is on: admins can read user messages (audited)")
+	}
 
 	// =====================================================
 	// 🧩 Misc Routes
@@ -158,6 +350,12 @@ func main() {
 	r.GET("/hello", handlers.HelloHandler)
 	r.GET("/greet", handlers.GreetHandler)
 
+	// =====================================================
+	// ⏰ Background Jobs
+	// =====================================================
+	// Builds data exports and hard deletes accounts past their grace period
+	go accountdata.NewWorker(db).Run(context.Background())
+
 	// =====================================================
 	// 🚀 Start Server
 	// =====================================================
@@ -166,3 +364,120 @@ func main() {
 		log.Fatal("❌ Failed to start server:", err)
 	}
 }
+
+// runAPIKeyCommand handles `apikey create <name> <scope,...>`, `apikey list`
+// and `apikey revoke <id>`.
+func runAPIKeyCommand(store *apikeys.Store, args []string) {
+	ctx := context.Background()
+
+	cmd := "list"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "create":
+		if len(args) != 3 {
+			log.Fatalf("❌ Usage: apikey create <name> <scope,...> (scopes: %s)\n", strings.Join(apikeys.Scopes, ", "))
+		}
+		plaintext, key, err := store.Create(ctx, args[1], strings.Split(args[2], ","))
+		if err != nil {
+			log.Fatal("❌ Failed to create API key:", err)
+		}
+		log.Printf("✅ Created API key %s for %q with scopes %s\n", key.ID, key.Name, strings.Join(key.Scopes, ","))
+		log.Println("⚠️ Store it now, it won't be shown again:")
+		fmt.Println(plaintext)
+
+	case "list":
+		keys, err := store.List(ctx)
+		if err != nil {
+			log.Fatal("❌ Failed to list API keys:", err)
+		}
+		for _, k := range keys {
+			state := "active"
+			if k.RevokedAt != nil {
+				state = "revoked " + k.RevokedAt.Format(time.RFC3339)
+			}
+			lastUsed := "never"
+			if k.LastUsedAt != nil {
+				lastUsed = k.LastUsedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%s\t%s\t%s…\t%s\tlast used %s\t%s\n", k.ID, k.Name, k.Prefix, strings.Join(k.Scopes, ","), lastUsed, state)
+		}
+
+	case "revoke":
+		if len(args) != 2 {
+			log.Fatal("❌ Usage: apikey revoke <id>")
+		}
+		if err := store.Revoke(ctx, args[1]); err != nil {
+			log.Fatal("❌ Failed to revoke API key:", err)
+		}
+		log.Printf("✅ Revoked API key %s\n", args[1])
+
+	default:
+		log.Fatalf("❌ Unknown apikey command %q (want create, list or revoke)\n", cmd)
+	}
+}
+
+// runRoleCommand handles `role <email> <user|admin> [reason]`. It is how the
+// first admin is made; the change is written to the audit log.
+func runRoleCommand(db *sql.DB, args []string) {
+	if len(args) < 2 || !slices.Contains(handlers.Roles, args[1]) {
+		log.Fatalf("❌ Usage: role <email> <role> [reason] (roles: %s)\n", strings.Join(handlers.Roles, ", "))
+	}
+	reason := strings.Join(args[2:], " ")
+	if err := adminHandler.SetRole(context.Background(), db, args[0], args[1], reason); err != nil {
+		log.Fatal("❌ Failed to set role:", err)
+	}
+	log.Printf("✅ %s is now %s\n", args[0], args[1])
+}
+
+// runMigrateCommand handles `migrate up`, `migrate down [steps]` and `migrate status`.
+func runMigrateCommand(migrator *migrations.Migrator, args []string) {
+	ctx := context.Background()
+
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal("❌ Migration failed:", err)
+		}
+		log.Printf("✅ Applied %d migration(s)\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				log.Fatalf("❌ Invalid step count %q\n", args[1])
+			}
+			steps = n
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatal("❌ Rollback failed:", err)
+		}
+		log.Printf("✅ Reverted %d migration(s)\n", reverted)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal("❌ Failed to read migration status:", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		log.Fatalf("❌ Unknown migrate command %q (want up, down or status)\n", cmd)
+	}
+}